@@ -0,0 +1,15 @@
+#!/bin/bash
+
+echo "Initializing ..."
+
+# private key and authorized_keys are base64 encoded in environment of ephemeral container
+mkdir -p /root/.ssh
+echo "${authorized}" | base64 -d > /root/.ssh/authorized_keys
+echo "${privateKey}" | base64 -d > /root/.ssh/id_rsa
+chmod 600 /root/.ssh/id_rsa
+
+# redirect rules are removed when container stopped
+trap '/usr/sbin/navigator teardown' SIGTERM
+
+/usr/sbin/sshd -D &
+wait $!
@@ -14,7 +14,7 @@ if [ -n "${privateKey}" ]; then
   echo "Private key created created"
 fi
 
-if [ "${KT_DNS_PROTOCOL}" = "" ]; then
+if [ "${KT_DNS_PROTOCOL}" = "" ] && [ "${KT_AUTH_PROXY}" = "" ] && [ "${KT_UDP_RELAY}" = "" ]; then
   echo "Skip shadow process"
 elif [ "${1}" = "--debug" ]; then
   echo "Run shadow in debug mode"
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/alibaba/kt-connect/pkg/janitor"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+func main() {
+	namespaces := flag.String("namespaces", "", "Comma separated namespaces to clean up, default to all namespaces")
+	interval := flag.Duration("interval", 5*time.Minute, "Interval between clean up rounds")
+	threshold := flag.Int64("thresholdInMinus", util.ResourceHeartBeatIntervalMinus*2+1,
+		"Length of allowed disconnection time before a unavailing resource be cleaned")
+	leaseName := flag.String("leaseName", "kt-janitor", "Name of lease used for leader election")
+	leaseNamespace := flag.String("leaseNamespace", os.Getenv("POD_NAMESPACE"), "Namespace of lease used for leader election")
+	metricsAddr := flag.String("metricsAddr", ":8080", "Address to expose metrics, empty to disable")
+	debug := flag.Bool("debug", false, "Print debug log")
+	flag.Parse()
+
+	if *debug {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	}
+	if *interval <= 0 {
+		log.Fatal().Msgf("Interval should be positive, but got %s", *interval)
+	}
+	restConfig, err := getRestConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Failed to load kubernetes config")
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Failed to create kubernetes client")
+	}
+	opt.Store.Clientset = clientset
+	opt.Store.RestConfig = restConfig
+
+	identity, _ := os.Hostname()
+	config := &janitor.Config{
+		Interval:         *interval,
+		ThresholdInMinus: *threshold,
+		LeaseName:        *leaseName,
+		LeaseNamespace:   *leaseNamespace,
+		Identity:         identity,
+	}
+	if *namespaces != "" {
+		config.Namespaces = strings.Split(*namespaces, ",")
+	}
+	if config.LeaseNamespace == "" {
+		config.LeaseNamespace = util.DefaultNamespace
+	}
+
+	metrics := janitor.NewMetrics()
+	if *metricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics)
+			if err2 := http.ListenAndServe(*metricsAddr, mux); err2 != nil {
+				log.Error().Err(err2).Msgf("Failed to expose metrics on %s", *metricsAddr)
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ch
+		log.Info().Msgf("Shutting down")
+		cancel()
+	}()
+	janitor.Run(ctx, clientset, config, metrics)
+}
+
+// getRestConfig use in-cluster config when running as pod, otherwise fallback to local kubeconfig
+func getRestConfig() (*rest.Config, error) {
+	if config, err := rest.InClusterConfig(); err == nil {
+		return config, nil
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
+}
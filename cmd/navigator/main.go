@@ -1,4 +1,213 @@
 package main
 
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/navigator"
+	"github.com/gofrs/flock"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+func init() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
+
+const pathKtLock = "/var/kt-navigator.lock"
+const actionPorts = "ports"
+const actionSetup = "setup"
+const actionRenew = "renew"
+const actionTeardown = "teardown"
+const actionGuard = "guard"
+const actionRelay = "relay"
+const actionRelayServe = "relay-serve"
+const defaultLeaseSeconds = 60
+const guardInterval = 5 * time.Second
+
 func main() {
+	if len(os.Args) < 2 {
+		usage()
+		return
+	}
+	var err error
+	switch os.Args[1] {
+	case actionPorts:
+		ports()
+	case actionSetup:
+		if len(os.Args) < 3 {
+			usage()
+			return
+		}
+		err = withLock(func() error { return setup(os.Args[2], leaseSeconds(3), proxyUid(4)) })
+	case actionRenew:
+		err = withLock(func() error { return navigator.Renew(leaseSeconds(2)) })
+	case actionTeardown:
+		err = withLock(teardown)
+	case actionGuard:
+		guard()
+	case actionRelay, actionRelayServe:
+		if len(os.Args) < 3 {
+			usage()
+			return
+		}
+		if os.Args[1] == actionRelay {
+			err = relay(os.Args[2])
+		} else {
+			err = relayServe(os.Args[2])
+		}
+	default:
+		log.Error().Msgf("Invalid action '%s'", os.Args[1])
+		usage()
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to %s", os.Args[1])
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	log.Info().Msgf(`Usage: 
+navigator %s
+navigator %s <[protocol/]port:redirect-port,...> [lease-seconds] [sidecar-proxy-uid]
+navigator %s [lease-seconds]
+navigator %s
+navigator %s <port:tunnel-port:target-port,...>
+`, actionPorts, actionSetup, actionRenew, actionTeardown, actionRelay)
+}
+
+func withLock(action func() error) error {
+	fileLock := flock.New(pathKtLock)
+	if err := fileLock.Lock(); err != nil {
+		return fmt.Errorf("unable to fetch navigator lock: %s", err)
+	}
+	defer fileLock.Unlock()
+	return action()
+}
+
+func leaseSeconds(argIndex int) int {
+	if len(os.Args) > argIndex {
+		if seconds, err := strconv.Atoi(os.Args[argIndex]); err == nil && seconds > 0 {
+			return seconds
+		}
+	}
+	return defaultLeaseSeconds
+}
+
+func proxyUid(argIndex int) int {
+	if len(os.Args) > argIndex {
+		if uid, err := strconv.Atoi(os.Args[argIndex]); err == nil && uid > 0 {
+			return uid
+		}
+	}
+	return 0
+}
+
+// ports print listened ports line by line, in "<protocol> <port>" format
+func ports() {
+	for protocol, ports := range navigator.ListenedPorts() {
+		for _, port := range ports {
+			fmt.Printf("%s %d\n", protocol, port)
+		}
+	}
+}
+
+func setup(rulesText string, lease, proxyUid int) error {
+	rules, err := navigator.ParseRules(rulesText)
+	if err != nil {
+		return err
+	}
+	backend, err := navigator.DetectBackend()
+	if err != nil {
+		return err
+	}
+	if err = navigator.Renew(lease); err != nil {
+		return err
+	}
+	if err = backend.Setup(rules, proxyUid); err != nil {
+		_ = backend.Teardown()
+		_ = navigator.Release()
+		return err
+	}
+	for _, rule := range rules {
+		log.Info().Msgf("Redirect rule %s added", rule)
+	}
+	// start a detached guard process to remove rules when lease expired, e.g. ktctl exit unexpectedly,
+	// stdout and stderr must not be inherited, otherwise exec session would not end
+	cmd := exec.Command(os.Args[0], actionGuard)
+	if err = cmd.Start(); err != nil {
+		log.Warn().Err(err).Msgf("Failed to start guard process")
+	} else {
+		_ = cmd.Process.Release()
+	}
+	return nil
+}
+
+func teardown() error {
+	backend, err := navigator.DetectBackend()
+	if err != nil {
+		return err
+	}
+	if err = backend.Teardown(); err != nil {
+		return err
+	}
+	log.Info().Msgf("Redirect rules removed")
+	return navigator.Release()
+}
+
+func guard() {
+	for {
+		time.Sleep(guardInterval)
+		exists, expired := navigator.LeaseStatus()
+		if !exists {
+			// rules already removed
+			return
+		} else if expired {
+			if err := withLock(func() error {
+				if _, stillExpired := navigator.LeaseStatus(); stillExpired {
+					return teardown()
+				}
+				return nil
+			}); err != nil {
+				log.Error().Err(err).Msgf("Failed to remove expired redirect rules")
+			}
+			if exists, _ = navigator.LeaseStatus(); !exists {
+				return
+			}
+		}
+	}
+}
+
+// relay start a detached process to relay udp ports through tcp tunnel, it exits together with redirect rules
+func relay(rulesText string) error {
+	if _, err := navigator.ParseRelayRules(rulesText); err != nil {
+		return err
+	}
+	// same as guard process, stdout and stderr must not be inherited
+	cmd := exec.Command(os.Args[0], actionRelayServe, rulesText)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	log.Info().Msgf("Udp relay %s started", rulesText)
+	return cmd.Process.Release()
+}
+
+func relayServe(rulesText string) error {
+	rules, err := navigator.ParseRelayRules(rulesText)
+	if err != nil {
+		return err
+	}
+	if err = navigator.StartRelay(rules); err != nil {
+		return err
+	}
+	for {
+		time.Sleep(guardInterval)
+		if exists, _ := navigator.LeaseStatus(); !exists {
+			// redirect rules removed, no more datagram would arrive
+			return nil
+		}
+	}
 }
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"github.com/alibaba/kt-connect/pkg/common"
+	"github.com/alibaba/kt-connect/pkg/shadow/authproxy"
 	"github.com/alibaba/kt-connect/pkg/shadow/dnsserver"
+	"github.com/alibaba/kt-connect/pkg/shadow/udprelay"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"os"
@@ -29,6 +32,21 @@ func main() {
 		log.Error().Err(err).Msgf("Failed to parse log level")
 	}
 	zerolog.SetGlobalLevel(level)
+	if authProxyPorts := os.Getenv(common.EnvVarAuthProxy); authProxyPorts != "" {
+		if err = authproxy.Start(authProxyPorts, fmt.Sprintf("%s/%s", common.AuthConfigDir, common.AuthConfigFile)); err != nil {
+			log.Fatal().Err(err).Msgf("Failed to start auth proxy")
+		}
+	}
+	if relayTargets := os.Getenv(common.EnvVarUdpRelay); relayTargets != "" {
+		if err = udprelay.Start(common.StandardUdpRelayPort, strings.Split(relayTargets, ",")); err != nil {
+			log.Fatal().Err(err).Msgf("Failed to start udp relay")
+		}
+	}
+	if os.Getenv(common.EnvVarDnsProtocol) == "" &&
+		(os.Getenv(common.EnvVarAuthProxy) != "" || os.Getenv(common.EnvVarUdpRelay) != "") {
+		// only auth proxy or udp relay is required
+		select {}
+	}
 	dnsPort := common.StandardDnsPort
 	dnsProtocol := getParameter(common.EnvVarDnsProtocol, ArgDnsProtocol, "udp")
 	localDomain := getParameter(common.EnvVarLocalDomains, ArgLocalDomains, "")
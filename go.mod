@@ -17,6 +17,7 @@ require (
 	golang.org/x/crypto v0.0.0-20220331220935-ae2d96664a29
 	golang.org/x/net v0.0.0-20220403103023-749bd193bc2b
 	golang.org/x/sys v0.0.0-20220405210540-1e041c57c461
+	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
 	golang.zx2c4.com/wintun v0.0.0-20211104114900-415007cec224
 	gopkg.in/yaml.v3 v3.0.0
 	k8s.io/api v0.22.0
@@ -56,7 +57,6 @@ require (
 	go.uber.org/atomic v1.9.0 // indirect
 	golang.org/x/mod v0.5.1 // indirect
 	golang.org/x/oauth2 v0.0.0-20211104180415-d3ed0bb246c8 // indirect
-	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
 	golang.org/x/text v0.3.7 // indirect
 	golang.org/x/time v0.0.0-20220224211638-0e9765cccd65 // indirect
 	golang.org/x/tools v0.1.9 // indirect
@@ -0,0 +1,13 @@
+package common
+
+// AuthConfig access control config of shadow pod auth proxy
+type AuthConfig struct {
+	// Type authentication type, 'basic', 'token' or empty for client address checking only
+	Type       string   `json:"type,omitempty"`
+	Username   string   `json:"username,omitempty"`
+	Password   string   `json:"password,omitempty"`
+	Token      string   `json:"token,omitempty"`
+	AllowCidrs []string `json:"allowCidrs,omitempty"`
+	// TrustedProxies address ranges of proxies (e.g. ingress controller pods) whose X-Forwarded-For header is trusted
+	TrustedProxies []string `json:"trustedProxies,omitempty"`
+}
@@ -9,6 +9,10 @@ const (
 	StandardSshPort = 22
 	// StandardDnsPort standard dns port
 	StandardDnsPort = 53
+	// StandardUdpRelayPort port of udp relay in shadow pod
+	StandardUdpRelayPort = 17000
+	// StandardDnsMetricsPort port of dns cache metrics in shadow pod
+	StandardDnsMetricsPort = 9153
 
 	// EnvVarLocalDomains environment variable for local domain config
 	EnvVarLocalDomains = "KT_LOCAL_DOMAIN"
@@ -16,4 +20,17 @@ const (
 	EnvVarDnsProtocol = "KT_DNS_PROTOCOL"
 	// EnvVarLogLevel environment variable for shadow pod log level
 	EnvVarLogLevel = "KT_LOG_LEVEL"
+	// EnvVarAuthProxy environment variable for ports of shadow pod auth proxy, in <listen>:<upstream> format
+	EnvVarAuthProxy = "KT_AUTH_PROXY"
+	// EnvVarUdpRelay environment variable for allowed targets of shadow pod udp relay, in <host>:<port> format
+	EnvVarUdpRelay = "KT_UDP_RELAY"
+
+	// AuthConfigFile key of auth proxy config in shadow config map, also the file name
+	AuthConfigFile = "auth"
+	// AuthConfigDir directory of auth proxy config file in shadow pod
+	AuthConfigDir = "/root/authorized"
+	// AuthTypeBasic http basic authentication
+	AuthTypeBasic = "basic"
+	// AuthTypeToken bearer token authentication
+	AuthTypeToken = "token"
 )
\ No newline at end of file
@@ -0,0 +1,230 @@
+package common
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/miekg/dns"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDnsCacheSize default max count of records in dns cache
+	DefaultDnsCacheSize = 4096
+	// DefaultNegativeTtl ttl for negative answer without SOA record (e.g. upstream unreachable)
+	DefaultNegativeTtl = 5
+	// only entries hit more than this times will be prefetched
+	prefetchMinHits = 3
+	// prefetch entry when its remaining ttl is less than 1/prefetchRatio of original ttl
+	prefetchRatio = 10
+)
+
+// DnsCacheStats counters of dns cache
+type DnsCacheStats struct {
+	Size         int
+	Hits         int64
+	NegativeHits int64
+	Misses       int64
+	Evictions    int64
+	Prefetches   int64
+}
+
+func (s DnsCacheStats) String() string {
+	return fmt.Sprintf("size=%d, hits=%d, negative-hits=%d, misses=%d, evictions=%d, prefetches=%d",
+		s.Size, s.Hits, s.NegativeHits, s.Misses, s.Evictions, s.Prefetches)
+}
+
+// Render output counters in prometheus text format
+func (s DnsCacheStats) Render() string {
+	var sb strings.Builder
+	writeDnsCacheMetric(&sb, "kt_dns_cache_entries", "gauge", "Number of records in dns cache", int64(s.Size))
+	writeDnsCacheMetric(&sb, "kt_dns_cache_hits_total", "counter", "Number of queries answered by cache", s.Hits)
+	writeDnsCacheMetric(&sb, "kt_dns_cache_negative_hits_total", "counter",
+		"Number of queries answered by negatively cached records", s.NegativeHits)
+	writeDnsCacheMetric(&sb, "kt_dns_cache_misses_total", "counter", "Number of queries not found in cache", s.Misses)
+	writeDnsCacheMetric(&sb, "kt_dns_cache_evictions_total", "counter", "Number of records evicted from cache", s.Evictions)
+	writeDnsCacheMetric(&sb, "kt_dns_cache_prefetches_total", "counter",
+		"Number of records refreshed before expire", s.Prefetches)
+	return sb.String()
+}
+
+func writeDnsCacheMetric(sb *strings.Builder, name, metricType, help string, value int64) {
+	sb.WriteString(fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, metricType, name, value))
+}
+
+// DnsCache bounded LRU cache of dns answers, entry expires according to ttl of its records
+type DnsCache struct {
+	lock     sync.Mutex
+	capacity int
+	maxTtl   uint32
+	entries  map[string]*list.Element
+	lru      *list.List
+	prefetch func(domain string, qtype uint16)
+	stats    DnsCacheStats
+	now      func() time.Time
+}
+
+type dnsCacheEntry struct {
+	key         string
+	domain      string
+	qtype       uint16
+	answer      []dns.RR
+	ttl         uint32
+	expireAt    time.Time
+	hits        int
+	prefetching bool
+}
+
+// NewDnsCache create dns cache with max entry count and max ttl (0 means no limit),
+// prefetch function is invoked in background when a frequently used entry is about to expire
+func NewDnsCache(capacity int, maxTtl uint32, prefetch func(domain string, qtype uint16)) *DnsCache {
+	if capacity <= 0 {
+		capacity = DefaultDnsCacheSize
+	}
+	return &DnsCache{
+		capacity: capacity,
+		maxTtl:   maxTtl,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		prefetch: prefetch,
+		now:      time.Now,
+	}
+}
+
+// Get fetch answer from cache, ttl of returned records are set to remaining seconds,
+// an empty answer with found equals to true means the domain is negatively cached
+func (c *DnsCache) Get(domain string, qtype uint16) (answer []dns.RR, found bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := getCacheKey(domain, qtype)
+	elem, exists := c.entries[key]
+	if !exists {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := elem.Value.(*dnsCacheEntry)
+	remaining := entry.expireAt.Sub(c.now())
+	if remaining <= 0 {
+		c.removeElement(elem)
+		c.stats.Misses++
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	entry.hits++
+	if len(entry.answer) == 0 {
+		c.stats.NegativeHits++
+	} else {
+		c.stats.Hits++
+	}
+	remainingTtl := uint32((remaining + time.Second - 1) / time.Second)
+	if c.prefetch != nil && !entry.prefetching && entry.hits >= prefetchMinHits &&
+		remainingTtl*prefetchRatio <= entry.ttl {
+		entry.prefetching = true
+		c.stats.Prefetches++
+		go c.prefetch(entry.domain, entry.qtype)
+	}
+	answer = make([]dns.RR, 0, len(entry.answer))
+	for _, rr := range entry.answer {
+		item := dns.Copy(rr)
+		item.Header().Ttl = remainingTtl
+		answer = append(answer, item)
+	}
+	return answer, true
+}
+
+// Put record lookup result to cache, answer ttl is the minimal ttl of its records,
+// for empty answer (NXDOMAIN or NODATA) the ttl of SOA record in authority section is used
+func (c *DnsCache) Put(domain string, qtype uint16, answer []dns.RR, authority []dns.RR) {
+	ttl := answerTtl(answer, authority)
+	if c.maxTtl > 0 && ttl > c.maxTtl {
+		ttl = c.maxTtl
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := getCacheKey(domain, qtype)
+	if elem, exists := c.entries[key]; exists {
+		c.removeElement(elem)
+	}
+	if ttl == 0 {
+		return
+	}
+	records := make([]dns.RR, 0, len(answer))
+	for _, rr := range answer {
+		if rr.Header().Rrtype != dns.TypeOPT {
+			records = append(records, dns.Copy(rr))
+		}
+	}
+	entry := &dnsCacheEntry{
+		key:      key,
+		domain:   domain,
+		qtype:    qtype,
+		answer:   records,
+		ttl:      ttl,
+		expireAt: c.now().Add(time.Duration(ttl) * time.Second),
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.capacity {
+		c.removeElement(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// ServeHTTP expose cache counters in prometheus text format
+func (c *DnsCache) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = w.Write([]byte(c.Stats().Render()))
+}
+
+// ServeMetrics expose cache counters at '/metrics' of specified address, block until server stopped
+func (c *DnsCache) ServeMetrics(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c)
+	return http.ListenAndServe(address, mux)
+}
+
+// Stats get snapshot of cache counters
+func (c *DnsCache) Stats() DnsCacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	return stats
+}
+
+func (c *DnsCache) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*dnsCacheEntry).key)
+}
+
+func answerTtl(answer []dns.RR, authority []dns.RR) uint32 {
+	var ttl uint32
+	found := false
+	for _, rr := range answer {
+		if rr.Header().Rrtype == dns.TypeOPT {
+			continue
+		}
+		if !found || rr.Header().Ttl < ttl {
+			ttl = rr.Header().Ttl
+			found = true
+		}
+	}
+	if found {
+		return ttl
+	}
+	// negative caching, see RFC 2308 section 5
+	for _, rr := range authority {
+		if soa, ok := rr.(*dns.SOA); ok {
+			if soa.Minttl < soa.Hdr.Ttl {
+				return soa.Minttl
+			}
+			return soa.Hdr.Ttl
+		}
+	}
+	return DefaultNegativeTtl
+}
+
+func getCacheKey(domain string, qtype uint16) string {
+	return fmt.Sprintf("%s:%d", dns.CanonicalName(domain), qtype)
+}
@@ -0,0 +1,121 @@
+package common
+
+import (
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestCache(capacity int, maxTtl uint32, prefetch func(string, uint16)) (*DnsCache, *time.Time) {
+	now := time.Unix(1650000000, 0)
+	c := NewDnsCache(capacity, maxTtl, prefetch)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func newRR(t *testing.T, s string) dns.RR {
+	rr, err := dns.NewRR(s)
+	require.NoError(t, err)
+	return rr
+}
+
+func TestShouldUseMinimalRecordTtl(t *testing.T) {
+	c, now := newTestCache(10, 0, nil)
+	c.Put("tomcat.", dns.TypeA, []dns.RR{
+		newRR(t, "tomcat. 30 IN CNAME www.tomcat."),
+		newRR(t, "www.tomcat. 10 IN A 10.12.4.6"),
+	}, nil)
+	answer, found := c.Get("tomcat.", dns.TypeA)
+	require.True(t, found)
+	require.Equal(t, 2, len(answer))
+	require.Equal(t, uint32(10), answer[0].Header().Ttl)
+	*now = now.Add(4 * time.Second)
+	answer, found = c.Get("TOMCAT.", dns.TypeA)
+	require.True(t, found)
+	require.Equal(t, uint32(6), answer[1].Header().Ttl)
+	*now = now.Add(6 * time.Second)
+	_, found = c.Get("tomcat.", dns.TypeA)
+	require.False(t, found)
+	require.Equal(t, DnsCacheStats{Size: 0, Hits: 2, Misses: 1}, c.Stats())
+}
+
+func TestShouldLimitTtlWithMaxTtl(t *testing.T) {
+	c, _ := newTestCache(10, 60, nil)
+	c.Put("tomcat.", dns.TypeA, []dns.RR{newRR(t, "tomcat. 3600 IN A 10.12.4.6")}, nil)
+	answer, found := c.Get("tomcat.", dns.TypeA)
+	require.True(t, found)
+	require.Equal(t, uint32(60), answer[0].Header().Ttl)
+}
+
+func TestShouldCacheNegativeAnswerWithSoaTtl(t *testing.T) {
+	c, now := newTestCache(10, 0, nil)
+	soa := newRR(t, "tomcat. 300 IN SOA ns.tomcat. admin.tomcat. 1 7200 1800 86400 30")
+	c.Put("none.tomcat.", dns.TypeA, []dns.RR{}, []dns.RR{soa})
+	answer, found := c.Get("none.tomcat.", dns.TypeA)
+	require.True(t, found)
+	require.Equal(t, 0, len(answer))
+	*now = now.Add(30 * time.Second)
+	_, found = c.Get("none.tomcat.", dns.TypeA)
+	require.False(t, found)
+
+	c.Put("none.tomcat.", dns.TypeAAAA, []dns.RR{}, nil)
+	*now = now.Add((DefaultNegativeTtl - 1) * time.Second)
+	_, found = c.Get("none.tomcat.", dns.TypeAAAA)
+	require.True(t, found)
+	require.Equal(t, int64(2), c.Stats().NegativeHits)
+}
+
+func TestShouldEvictLeastRecentlyUsedEntry(t *testing.T) {
+	c, _ := newTestCache(2, 0, nil)
+	c.Put("a.", dns.TypeA, []dns.RR{newRR(t, "a. 10 IN A 10.0.0.1")}, nil)
+	c.Put("b.", dns.TypeA, []dns.RR{newRR(t, "b. 10 IN A 10.0.0.2")}, nil)
+	_, found := c.Get("a.", dns.TypeA)
+	require.True(t, found)
+	c.Put("c.", dns.TypeA, []dns.RR{newRR(t, "c. 10 IN A 10.0.0.3")}, nil)
+	_, found = c.Get("b.", dns.TypeA)
+	require.False(t, found)
+	_, found = c.Get("a.", dns.TypeA)
+	require.True(t, found)
+	_, found = c.Get("c.", dns.TypeA)
+	require.True(t, found)
+	require.Equal(t, int64(1), c.Stats().Evictions)
+	require.Equal(t, 2, c.Stats().Size)
+}
+
+func TestShouldPrefetchHotEntry(t *testing.T) {
+	prefetched := make(chan string, 1)
+	c, now := newTestCache(10, 0, func(domain string, qtype uint16) {
+		prefetched <- domain
+	})
+	c.Put("hot.", dns.TypeA, []dns.RR{newRR(t, "hot. 100 IN A 10.0.0.1")}, nil)
+	for i := 0; i < prefetchMinHits; i++ {
+		c.Get("hot.", dns.TypeA)
+	}
+	require.Equal(t, 0, len(prefetched))
+	*now = now.Add(95 * time.Second)
+	c.Get("hot.", dns.TypeA)
+	c.Get("hot.", dns.TypeA)
+	select {
+	case domain := <-prefetched:
+		require.Equal(t, "hot.", domain)
+	case <-time.After(time.Second):
+		require.Fail(t, "entry not prefetched")
+	}
+	require.Equal(t, int64(1), c.Stats().Prefetches)
+}
+
+func TestShouldExposeMetricsInPrometheusFormat(t *testing.T) {
+	c, _ := newTestCache(2, 0, nil)
+	c.Put("a.", dns.TypeA, []dns.RR{newRR(t, "a. 10 IN A 10.0.0.1")}, nil)
+	c.Get("a.", dns.TypeA)
+	c.Get("b.", dns.TypeA)
+
+	recorder := httptest.NewRecorder()
+	c.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	text := recorder.Body.String()
+	require.Contains(t, text, "# TYPE kt_dns_cache_hits_total counter\nkt_dns_cache_hits_total 1\n")
+	require.Contains(t, text, "kt_dns_cache_misses_total 1\n")
+	require.Contains(t, text, "# TYPE kt_dns_cache_entries gauge\nkt_dns_cache_entries 1\n")
+}
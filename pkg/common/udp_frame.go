@@ -0,0 +1,40 @@
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// MaxUdpFrameSize max size of datagram carried in a frame
+	MaxUdpFrameSize = 65535
+	// UdpSessionIdleTimeout relay session is closed when no datagram sent or received for a while
+	UdpSessionIdleTimeout = 2 * time.Minute
+)
+
+// WriteUdpFrame write a datagram to stream, with 2 bytes big-endian length as prefix
+func WriteUdpFrame(w io.Writer, data []byte) error {
+	if len(data) > MaxUdpFrameSize {
+		return fmt.Errorf("datagram size %d exceeds limit", len(data))
+	}
+	frame := make([]byte, 2+len(data))
+	binary.BigEndian.PutUint16(frame, uint16(len(data)))
+	copy(frame[2:], data)
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadUdpFrame read a datagram from stream, buf should be large enough to hold MaxUdpFrameSize bytes
+func ReadUdpFrame(r io.Reader, buf []byte) (int, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	size := int(binary.BigEndian.Uint16(header[:]))
+	if size > len(buf) {
+		return 0, fmt.Errorf("buffer too small for datagram of %d bytes", size)
+	}
+	return io.ReadFull(r, buf[:size])
+}
@@ -0,0 +1,29 @@
+package common
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func TestUdpFrame(t *testing.T) {
+	stream := &bytes.Buffer{}
+	require.Nil(t, WriteUdpFrame(stream, []byte("order.default:53")))
+	require.Nil(t, WriteUdpFrame(stream, []byte{}))
+	require.Nil(t, WriteUdpFrame(stream, []byte("hello")))
+	require.NotNil(t, WriteUdpFrame(stream, make([]byte, MaxUdpFrameSize+1)))
+
+	buf := make([]byte, MaxUdpFrameSize)
+	n, err := ReadUdpFrame(stream, buf)
+	require.Nil(t, err)
+	require.Equal(t, "order.default:53", string(buf[:n]))
+	n, err = ReadUdpFrame(stream, buf)
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+	n, err = ReadUdpFrame(stream, buf)
+	require.Nil(t, err)
+	require.Equal(t, "hello", string(buf[:n]))
+	_, err = ReadUdpFrame(stream, buf)
+	require.Equal(t, io.EOF, err)
+}
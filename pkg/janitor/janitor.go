@@ -0,0 +1,139 @@
+package janitor
+
+import (
+	"context"
+	"github.com/alibaba/kt-connect/pkg/kt/command/clean"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/rs/zerolog/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"time"
+)
+
+// Config options of janitor
+type Config struct {
+	// Namespaces to clean up, empty for all namespaces
+	Namespaces       []string
+	Interval         time.Duration
+	ThresholdInMinus int64
+	LeaseName        string
+	LeaseNamespace   string
+	Identity         string
+}
+
+// Run clean up kt resources on interval as long as current instance is the leader, block until context done
+func Run(ctx context.Context, clientset kubernetes.Interface, config *Config, metrics *Metrics) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      config.LeaseName,
+			Namespace: config.LeaseNamespace,
+		},
+		Client: clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: config.Identity,
+		},
+	}
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   15 * time.Second,
+		RenewDeadline:   10 * time.Second,
+		RetryPeriod:     2 * time.Second,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Info().Msgf("Instance %s became leader, start cleaning", config.Identity)
+				metrics.SetLeader(true)
+				loop(ctx, config, metrics)
+			},
+			OnStoppedLeading: func() {
+				log.Info().Msgf("Instance %s stopped leading", config.Identity)
+				metrics.SetLeader(false)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != config.Identity {
+					log.Info().Msgf("Current leader is %s", identity)
+				}
+			},
+		},
+	})
+}
+
+func loop(ctx context.Context, config *Config, metrics *Metrics) {
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+	for {
+		err := Sweep(config, metrics)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to clean up kt resources")
+		}
+		metrics.SweepDone(time.Now().Unix(), err != nil)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep check and tidy unavailing kt resources in all target namespaces
+func Sweep(config *Config, metrics *Metrics) error {
+	namespaces := config.Namespaces
+	if len(namespaces) == 0 {
+		nsList, err := cluster.Ins().GetAllNamespaces()
+		if err != nil {
+			return err
+		}
+		for _, ns := range nsList.Items {
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+	// clean functions work on namespace of global options, restore it after sweep
+	originNamespace := opt.Get().Global.Namespace
+	defer func() {
+		opt.Get().Global.Namespace = originNamespace
+	}()
+	opt.Get().Clean.ThresholdInMinus = config.ThresholdInMinus
+	var lastErr error
+	for _, ns := range namespaces {
+		opt.Get().Global.Namespace = ns
+		resourceToClean, err := clean.CheckClusterResources()
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to check kt resources in namespace %s", ns)
+			lastErr = err
+			continue
+		}
+		counts := countResources(resourceToClean)
+		if isEmpty(counts) {
+			log.Debug().Msgf("No unavailing kt resource found in namespace %s", ns)
+			continue
+		}
+		log.Info().Msgf("Cleaning up namespace %s", ns)
+		clean.TidyClusterResources(resourceToClean)
+		metrics.AddResources(ns, counts)
+	}
+	return lastErr
+}
+
+func countResources(r *clean.ResourceToClean) map[string]int {
+	return map[string]int{
+		"delete_pod":        len(r.PodsToDelete),
+		"delete_configmap":  len(r.ConfigMapsToDelete),
+		"delete_deployment": len(r.DeploymentsToDelete),
+		"scale_workload":    len(r.WorkloadsToScale),
+		"delete_service":    len(r.ServicesToDelete),
+		"recover_service":   len(r.ServicesToRecover),
+		"unlock_service":    len(r.ServicesToUnlock),
+	}
+}
+
+func isEmpty(counts map[string]int) bool {
+	for _, c := range counts {
+		if c > 0 {
+			return false
+		}
+	}
+	return true
+}
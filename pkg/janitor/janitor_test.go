@@ -0,0 +1,65 @@
+package janitor
+
+import (
+	"context"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func ktPod(name, namespace string, lastHeartBeat time.Time) *coreV1.Pod {
+	return &coreV1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   namespace,
+		Labels:      map[string]string{util.ControlBy: util.KubernetesToolkit},
+		Annotations: map[string]string{util.KtLastHeartBeat: strconv.FormatInt(lastHeartBeat.Unix(), 10)},
+	}}
+}
+
+func TestSweep(t *testing.T) {
+	auditLogFile := util.KtAuditLogFile
+	util.KtAuditLogFile = filepath.Join(t.TempDir(), "audit.log")
+	defer func() { util.KtAuditLogFile = auditLogFile }()
+
+	expired := time.Now().Add(-time.Hour)
+	clientset := fake.NewSimpleClientset(
+		&coreV1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&coreV1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+		ktPod("order-kt-abcde", "dev", expired),
+		ktPod("order-kt-fghij", "test", expired),
+		ktPod("payment-kt-klmno", "test", time.Now()),
+	)
+	opt.Store.Clientset = clientset
+	opt.Get().Global.Namespace = "default"
+
+	metrics := NewMetrics()
+	require.Nil(t, Sweep(&Config{ThresholdInMinus: 10}, metrics))
+	require.Equal(t, "default", opt.Get().Global.Namespace)
+	require.Equal(t, int64(10), opt.Get().Clean.ThresholdInMinus)
+	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, pods.Items, 1)
+	require.Equal(t, "payment-kt-klmno", pods.Items[0].Name)
+	text := metrics.Render()
+	require.Contains(t, text, "kt_janitor_resources_total{namespace=\"dev\",action=\"delete_pod\"} 1\n")
+	require.Contains(t, text, "kt_janitor_resources_total{namespace=\"test\",action=\"delete_pod\"} 1\n")
+
+	// only specified namespaces are swept
+	_, err = clientset.CoreV1().Pods("dev").Create(context.TODO(), ktPod("order-kt-pqrst", "dev", expired), metav1.CreateOptions{})
+	require.Nil(t, err)
+	_, err = clientset.CoreV1().Pods("test").Create(context.TODO(), ktPod("order-kt-uvwxy", "test", expired), metav1.CreateOptions{})
+	require.Nil(t, err)
+	require.Nil(t, Sweep(&Config{Namespaces: []string{"test"}, ThresholdInMinus: 10}, metrics))
+	_, err = clientset.CoreV1().Pods("dev").Get(context.TODO(), "order-kt-pqrst", metav1.GetOptions{})
+	require.Nil(t, err)
+	_, err = clientset.CoreV1().Pods("test").Get(context.TODO(), "order-kt-uvwxy", metav1.GetOptions{})
+	require.NotNil(t, err)
+	require.Equal(t, "default", opt.Get().Global.Namespace)
+}
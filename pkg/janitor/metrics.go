@@ -0,0 +1,91 @@
+package janitor
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Metrics statistics of janitor, exposed in prometheus text format
+type Metrics struct {
+	lock          sync.Mutex
+	sweeps        int64
+	sweepErrors   int64
+	lastSweepTime int64
+	leader        bool
+	resources     map[string]int64
+}
+
+// NewMetrics create an empty metrics
+func NewMetrics() *Metrics {
+	return &Metrics{resources: map[string]int64{}}
+}
+
+// AddResources increase count of resources handled in namespace for each action
+func (m *Metrics) AddResources(namespace string, counts map[string]int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for action, count := range counts {
+		if count > 0 {
+			m.resources[namespace+"/"+action] += int64(count)
+		}
+	}
+}
+
+// SweepDone record finish of a sweep round
+func (m *Metrics) SweepDone(timestamp int64, failed bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.sweeps++
+	if failed {
+		m.sweepErrors++
+	}
+	m.lastSweepTime = timestamp
+}
+
+// SetLeader mark whether current instance is leader
+func (m *Metrics) SetLeader(leader bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.leader = leader
+}
+
+// Render output metrics in prometheus text format
+func (m *Metrics) Render() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var sb strings.Builder
+	writeMetric(&sb, "kt_janitor_sweeps_total", "counter", "Number of clean up rounds executed", m.sweeps)
+	writeMetric(&sb, "kt_janitor_sweep_errors_total", "counter", "Number of clean up rounds failed", m.sweepErrors)
+	writeMetric(&sb, "kt_janitor_last_sweep_timestamp_seconds", "gauge", "Time of last clean up round", m.lastSweepTime)
+	leader := int64(0)
+	if m.leader {
+		leader = 1
+	}
+	writeMetric(&sb, "kt_janitor_leader", "gauge", "Whether current instance is the leader", leader)
+	sb.WriteString("# HELP kt_janitor_resources_total Number of kt resources cleaned up or recovered\n")
+	sb.WriteString("# TYPE kt_janitor_resources_total counter\n")
+	keys := make([]string, 0, len(m.resources))
+	for k := range m.resources {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		namespace, action, _ := strings.Cut(k, "/")
+		sb.WriteString(fmt.Sprintf("kt_janitor_resources_total{namespace=\"%s\",action=\"%s\"} %d\n",
+			namespace, action, m.resources[k]))
+	}
+	return sb.String()
+}
+
+// ServeHTTP expose metrics via http
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = w.Write([]byte(m.Render()))
+}
+
+func writeMetric(sb *strings.Builder, name, metricType, help string, value int64) {
+	sb.WriteString(fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, metricType, name, value))
+}
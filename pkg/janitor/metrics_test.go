@@ -0,0 +1,25 @@
+package janitor
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMetrics_Render(t *testing.T) {
+	m := NewMetrics()
+	m.SetLeader(true)
+	m.AddResources("dev", map[string]int{"delete_pod": 2, "delete_service": 0})
+	m.AddResources("default", map[string]int{"recover_service": 1})
+	m.AddResources("dev", map[string]int{"delete_pod": 1})
+	m.SweepDone(1650000000, false)
+	m.SweepDone(1650000300, true)
+
+	text := m.Render()
+	require.Contains(t, text, "kt_janitor_sweeps_total 2\n")
+	require.Contains(t, text, "kt_janitor_sweep_errors_total 1\n")
+	require.Contains(t, text, "kt_janitor_last_sweep_timestamp_seconds 1650000300\n")
+	require.Contains(t, text, "kt_janitor_leader 1\n")
+	require.Contains(t, text, "kt_janitor_resources_total{namespace=\"default\",action=\"recover_service\"} 1\n"+
+		"kt_janitor_resources_total{namespace=\"dev\",action=\"delete_pod\"} 3\n")
+	require.NotContains(t, text, "delete_service")
+}
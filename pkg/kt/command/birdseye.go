@@ -20,6 +20,9 @@ func NewBirdseyeCommand() *cobra.Command {
 			if len(args) > 0 {
 				return fmt.Errorf("too many options specified (%s)", strings.Join(args, ",") )
 			}
+			if err := general.CheckOutputFormat(opt.Get().Birdseye.Output); err != nil {
+				return err
+			}
 			return general.Prepare()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,6 +37,15 @@ func NewBirdseyeCommand() *cobra.Command {
 }
 
 func Birdseye() error {
+	if opt.Get().Birdseye.Watch {
+		if opt.Get().Birdseye.SortBy != util.SortByName && opt.Get().Birdseye.SortBy != util.SortByStatus {
+			return fmt.Errorf("invalid sort method: %s", opt.Get().Birdseye.SortBy)
+		}
+		return birdseye.RunDashboard(opt.Get().Global.Namespace, opt.Get().Birdseye.SortBy, Recover)
+	}
+	if opt.Get().Birdseye.Output != "" {
+		return printBirdseyeReport()
+	}
 	err := showServiceStatus()
 	if err != nil {
 		return err
@@ -70,6 +82,29 @@ func showServiceStatus() error {
 	return nil
 }
 
+func printBirdseyeReport() error {
+	if opt.Get().Birdseye.SortBy != util.SortByName && opt.Get().Birdseye.SortBy != util.SortByStatus {
+		return fmt.Errorf("invalid sort method: %s", opt.Get().Birdseye.SortBy)
+	}
+	ktPods, ktSvcs, svcs, err := birdseye.GetKtPodsAndAllServices(opt.Get().Global.Namespace)
+	if err != nil {
+		return err
+	}
+	details := birdseye.SortServiceDetails(birdseye.GetServiceDetails(ktSvcs, ktPods, svcs), opt.Get().Birdseye.SortBy)
+	report := birdseye.Report{
+		Namespace: opt.Get().Global.Namespace,
+		Services:  birdseye.ToServiceStatus(details),
+	}
+	if opt.Get().Birdseye.ShowConnector {
+		pods, apps, err2 := birdseye.GetKtPodsAndDeployments()
+		if err2 != nil {
+			return err2
+		}
+		report.Connectors = birdseye.GetConnectorDetails(pods, apps)
+	}
+	return general.PrintOutput(opt.Get().Birdseye.Output, report)
+}
+
 func showConnectors() error {
 	pods, apps, err := birdseye.GetKtPodsAndDeployments()
 	if err != nil {
@@ -7,7 +7,6 @@ import (
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	appV1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
-	"strings"
 )
 
 const UnknownUser = "unknown user"
@@ -47,51 +46,35 @@ func GetKtPodsAndDeployments() ([]coreV1.Pod, []appV1.Deployment, error) {
 }
 
 func GetConnectors(pods []coreV1.Pod, apps []appV1.Deployment) []string {
-	users:= make([]string, 0)
-	for _, pod := range pods {
-		if user := checkConnector(pod.Annotations); user != "" {
-			users = append(users, user)
+	users := make([]string, 0)
+	for _, c := range GetConnectorDetails(pods, apps) {
+		if c.User == "" {
+			users = append(users, UnknownUser)
+		} else if c.LastActiveMinutes != nil {
+			users = append(users, fmt.Sprintf("%s (last active %d min ago)", c.User, *c.LastActiveMinutes))
+		} else {
+			users = append(users, c.User)
 		}
 	}
+	return users
+}
+
+// GetConnectorDetails get user name and last active time of each connect shadow
+func GetConnectorDetails(pods []coreV1.Pod, apps []appV1.Deployment) []Connector {
+	connectors := make([]Connector, 0)
+	for _, pod := range pods {
+		connectors = append(connectors, checkConnector(pod.Annotations))
+	}
 	for _, app := range apps {
-		if user := checkConnector(app.Annotations); user != "" {
-			users = append(users, user)
-		}
+		connectors = append(connectors, checkConnector(app.Annotations))
 	}
-	return users
+	return connectors
 }
 
 func GetServiceStatus(ktSvcs []coreV1.Service, pods []coreV1.Pod, svcs []coreV1.Service) [][]string {
 	allServices := make([][]string, 0)
-	for _, svc := range ktSvcs {
-		for _, p := range pods {
-			if p.Labels[util.KtRole] == util.RolePreviewShadow && util.MapContains(svc.Spec.Selector, p.Labels) {
-				allServices = append(allServices, []string{svc.Name, fmt.Sprintf("previewing by [%s]", getUserName(p))})
-				break
-			}
-		}
-	}
-	svcLoop:
-	for _, svc := range svcs {
-		for _, p := range pods {
-			if util.MapContains(svc.Spec.Selector, p.Labels) {
-				if role := p.Labels[util.KtRole]; role == util.RoleExchangeShadow {
-					allServices = append(allServices, []string{svc.Name, fmt.Sprintf("exchanged by [%s]", getUserName(p))})
-					continue svcLoop
-				} else if role == util.RoleRouter {
-					allServices = append(allServices, []string{svc.Name, "meshed (auto) by " +
-						getMeshedUserNames(ktSvcs, pods, svc.Name + util.MeshPodInfix)})
-					continue svcLoop
-				} else if role == util.RoleMeshShadow {
-					allServices = append(allServices, []string{svc.Name, "meshed (manual) by " +
-						getMeshedUserNames([]coreV1.Service{svc}, pods, svc.Name)})
-					continue svcLoop
-				}
-			}
-		}
-		if !opt.Get().Birdseye.HideNaturalService {
-			allServices = append(allServices, []string{svc.Name, "normal"})
-		}
+	for _, detail := range GetServiceDetails(ktSvcs, pods, svcs) {
+		allServices = append(allServices, []string{detail.Name, detail.Description()})
 	}
 	return allServices
 }
@@ -104,37 +87,13 @@ func getUserName(p coreV1.Pod) string {
 	return user
 }
 
-func getMeshedUserNames(svcs []coreV1.Service, pods []coreV1.Pod, namePrefix string) string {
-	users := make([]string, 0)
-	for _, s := range svcs {
-		if strings.HasPrefix(s.Name, namePrefix) {
-			for _, p := range pods {
-				if p.Labels[util.KtRole] == util.RoleMeshShadow && util.MapContains(s.Spec.Selector, p.Labels) {
-					user := p.Annotations[util.KtUser]
-					if user != "" {
-						users = append(users, user)
-					}
-					break
-				}
-			}
-		}
-	}
-	if len(users) == 0 {
-		return UnknownUser
-	}
-	return "[" + strings.Join(users, "], [") + "]"
-}
-
-func checkConnector(annotations map[string]string) string {
-	if user, exists := annotations[util.KtUser]; exists {
-		lastHeartBeat := util.ParseTimestamp(annotations[util.KtLastHeartBeat])
-		if lastHeartBeat > 0 {
+func checkConnector(annotations map[string]string) Connector {
+	connector := Connector{User: annotations[util.KtUser]}
+	if connector.User != "" {
+		if lastHeartBeat := util.ParseTimestamp(annotations[util.KtLastHeartBeat]); lastHeartBeat > 0 {
 			lastActiveInMin := (util.GetTime() - lastHeartBeat) / 60
-			return fmt.Sprintf("%s (last active %d min ago)", user, lastActiveInMin)
-		} else {
-			return user
+			connector.LastActiveMinutes = &lastActiveInMin
 		}
-	} else {
-		return UnknownUser
 	}
+	return connector
 }
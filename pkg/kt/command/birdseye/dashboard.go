@@ -0,0 +1,357 @@
+package birdseye
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/term"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keyCtrlC     = "\x03"
+	keyEnter     = "\r"
+	keyEscape    = "\x1b"
+	keyBackspace = "\x7f"
+	keyUp        = "\x1b[A"
+	keyDown      = "\x1b[B"
+
+	escEnterScreen = "\x1b[?1049h\x1b[?25l"
+	escLeaveScreen = "\x1b[?25h\x1b[?1049l"
+	escClearScreen = "\x1b[H\x1b[2J"
+
+	listHelp   = "[up/down] select  [enter] detail  [r] recover  [s] sort  [q] quit"
+	detailHelp = "[esc] back  [r] recover  [q] quit"
+)
+
+// Dashboard interactive terminal view of service status, refreshed on cluster changes
+type Dashboard struct {
+	lock      sync.Mutex
+	namespace string
+	sortBy    string
+	recover   func(string) error
+	// selected name of service under cursor in list view
+	selected string
+	offset   int
+	// viewing name of service in detail view, empty for list view
+	viewing string
+	// confirming name of service waiting for recover confirmation
+	confirming string
+	message    string
+	changed    chan struct{}
+}
+
+// RunDashboard show dashboard of services in namespace until user quit
+func RunDashboard(namespace, sortBy string, recover func(string) error) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("watch mode requires an interactive terminal")
+	}
+	state := WatchClusterState(namespace)
+	d := &Dashboard{namespace: namespace, sortBy: sortBy, recover: recover, changed: state.Changed}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, oldState)
+	fmt.Print(escEnterScreen)
+	defer fmt.Print(escLeaveScreen)
+	// log of recover would break the screen, show the latest one in status line instead
+	originLogger := log.Logger
+	log.Logger = zerolog.New(statusWriter{d})
+	defer func() { log.Logger = originLogger }()
+
+	keys := make(chan string)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err2 := os.Stdin.Read(buf)
+			if err2 != nil {
+				close(keys)
+				return
+			}
+			keys <- string(buf[:n])
+		}
+	}()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		width, height, err2 := term.GetSize(fd)
+		if err2 != nil {
+			width, height = 120, 40
+		}
+		fmt.Print(escClearScreen + d.Render(state.Details(), width, height))
+		select {
+		case key, ok := <-keys:
+			if !ok || d.HandleKey(key, state.Details()) {
+				return nil
+			}
+		case <-state.Changed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// HandleKey update dashboard according to key pressed, return true for quit
+func (d *Dashboard) HandleKey(key string, details []ServiceDetail) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	rows := d.sortedRows(details)
+	if d.confirming != "" {
+		if key == "y" || key == "Y" {
+			d.message = fmt.Sprintf("Recovering service %s ...", d.confirming)
+			go d.doRecover(d.confirming)
+		} else {
+			d.message = ""
+		}
+		d.confirming = ""
+		return false
+	}
+	switch key {
+	case "q", keyCtrlC:
+		return true
+	case keyUp, "k":
+		if index := d.selectedIndex(rows); d.viewing == "" && index > 0 {
+			d.selected = rows[index-1][0]
+		}
+	case keyDown, "j":
+		if index := d.selectedIndex(rows); d.viewing == "" && index < len(rows)-1 {
+			d.selected = rows[index+1][0]
+		}
+	case keyEnter:
+		if index := d.selectedIndex(rows); d.viewing == "" && index >= 0 {
+			d.viewing = rows[index][0]
+		}
+	case keyEscape, keyBackspace, "b":
+		d.viewing = ""
+	case "s":
+		if d.sortBy == util.SortByStatus {
+			d.sortBy = util.SortByName
+		} else {
+			d.sortBy = util.SortByStatus
+		}
+	case "r":
+		if name := d.current(rows); name != "" {
+			d.confirming = name
+			d.message = fmt.Sprintf("Recover service %s ? (y/n)", name)
+		}
+	}
+	return false
+}
+
+// Render draw the list view or detail view in lines fit for the terminal size
+func (d *Dashboard) Render(details []ServiceDetail, width, height int) string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if height < 4 {
+		height = 4
+	}
+	var lines []string
+	help := listHelp
+	if d.viewing != "" {
+		help = detailHelp
+		lines = d.renderDetail(details)
+	} else {
+		lines = d.renderList(details, height-3)
+	}
+	lines = append(lines, "", d.message)
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines[:height-1], help)
+	for i, line := range lines {
+		if len(line) > width {
+			lines[i] = line[:width]
+		}
+	}
+	// terminal is in raw mode, carriage return is required
+	return strings.Join(lines, "\r\n")
+}
+
+func (d *Dashboard) renderList(details []ServiceDetail, maxLines int) []string {
+	rows := d.sortedRows(details)
+	index := d.selectedIndex(rows)
+	visible := maxLines - 2
+	if index < d.offset {
+		d.offset = 0
+		if index > 0 {
+			d.offset = index
+		}
+	} else if visible > 0 && index >= d.offset+visible {
+		d.offset = index - visible + 1
+	}
+	if d.offset > len(rows) {
+		d.offset = len(rows)
+	}
+	end := len(rows)
+	if visible > 0 && end > d.offset+visible {
+		end = d.offset + visible
+	}
+	table := formatTable([]string{"SERVICE", "STATE", "OWNERS", "HEARTBEAT", "VERSIONS"}, rows[d.offset:end])
+	lines := []string{fmt.Sprintf("Services in namespace %s (sort by %s, %d in total)", d.namespace, d.sortBy, len(rows))}
+	for i, line := range table {
+		if i > 0 && d.offset+i-1 == index {
+			line = "> " + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (d *Dashboard) renderDetail(details []ServiceDetail) []string {
+	var detail *ServiceDetail
+	for i := range details {
+		if details[i].Name == d.viewing {
+			detail = &details[i]
+		}
+	}
+	if detail == nil {
+		return []string{fmt.Sprintf("Service %s no longer exists", d.viewing)}
+	}
+	lines := []string{
+		fmt.Sprintf("Service:   %s", detail.Name),
+		fmt.Sprintf("State:     %s", detail.State),
+		fmt.Sprintf("Owners:    %s", formatOwners(detail)),
+		fmt.Sprintf("Heartbeat: %s", formatAge(detail.HeartBeatAge)),
+		fmt.Sprintf("Versions:  %s", formatList(detail.Versions)),
+		"",
+		"Kt pods:",
+	}
+	pods := make([][]string, 0)
+	for _, p := range detail.Pods {
+		age := int64(-1)
+		if lastHeartBeat := util.ParseTimestamp(p.Annotations[util.KtLastHeartBeat]); lastHeartBeat > 0 {
+			age = util.GetTime() - lastHeartBeat
+		}
+		pods = append(pods, []string{p.Name, p.Labels[util.KtRole], getUserName(p), string(p.Status.Phase), formatAge(age)})
+	}
+	for _, line := range formatTable([]string{"NAME", "ROLE", "USER", "PHASE", "HEARTBEAT"}, pods) {
+		lines = append(lines, "  "+line)
+	}
+	return lines
+}
+
+// sortedRows convert details to rows of [name, state, owners, heartbeat, versions], sorted by name or state
+func (d *Dashboard) sortedRows(details []ServiceDetail) [][]string {
+	rows := make([][]string, 0, len(details))
+	for _, detail := range SortServiceDetails(details, d.sortBy) {
+		rows = append(rows, []string{detail.Name, detail.State, formatOwners(&detail),
+			formatAge(detail.HeartBeatAge), formatList(detail.Versions)})
+	}
+	return rows
+}
+
+// selectedIndex get row index of selected service, the first row is selected if it's gone
+func (d *Dashboard) selectedIndex(rows [][]string) int {
+	for i, row := range rows {
+		if row[0] == d.selected {
+			return i
+		}
+	}
+	if len(rows) == 0 {
+		return -1
+	}
+	d.selected = rows[0][0]
+	return 0
+}
+
+func (d *Dashboard) current(rows [][]string) string {
+	if d.viewing != "" {
+		return d.viewing
+	} else if index := d.selectedIndex(rows); index >= 0 {
+		return rows[index][0]
+	}
+	return ""
+}
+
+func (d *Dashboard) doRecover(name string) {
+	err := d.recover(name)
+	d.lock.Lock()
+	if err != nil {
+		d.message = fmt.Sprintf("Failed to recover service %s: %s", name, err)
+	} else {
+		d.message = fmt.Sprintf("Service %s recovered", name)
+	}
+	d.lock.Unlock()
+	d.notify()
+}
+
+func (d *Dashboard) notify() {
+	select {
+	case d.changed <- struct{}{}:
+	default:
+	}
+}
+
+// statusWriter show log message in status line of dashboard
+type statusWriter struct {
+	d *Dashboard
+}
+
+func (w statusWriter) Write(p []byte) (int, error) {
+	var event map[string]any
+	if err := json.Unmarshal(p, &event); err == nil {
+		if msg, ok := event[zerolog.MessageFieldName].(string); ok && msg != "" {
+			w.d.lock.Lock()
+			w.d.message = msg
+			w.d.lock.Unlock()
+			w.d.notify()
+		}
+	}
+	return len(p), nil
+}
+
+func formatTable(header []string, rows [][]string) []string {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for _, row := range append([][]string{header}, rows...) {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cell + strings.Repeat(" ", widths[i]-len(cell))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "   "), " "))
+	}
+	return lines
+}
+
+func formatOwners(detail *ServiceDetail) string {
+	if detail.State == StateNormal {
+		return "-"
+	} else if len(detail.Owners) == 0 {
+		return UnknownUser
+	}
+	return strings.Join(detail.Owners, ", ")
+}
+
+func formatList(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ", ")
+}
+
+func formatAge(seconds int64) string {
+	if seconds < 0 {
+		return "-"
+	} else if seconds < 60 {
+		return fmt.Sprintf("%ds ago", seconds)
+	} else if seconds < 3600 {
+		return fmt.Sprintf("%dm ago", seconds/60)
+	}
+	return fmt.Sprintf("%dh ago", seconds/3600)
+}
@@ -0,0 +1,50 @@
+package birdseye
+
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestDashboard(t *testing.T) {
+	details := []ServiceDetail{
+		{Name: "stock", State: StateNormal, HeartBeatAge: -1},
+		{Name: "order", State: StateExchanged, Owners: []string{"tom"}, HeartBeatAge: 75},
+		{Name: "cart", State: StateAutoMeshed, HeartBeatAge: 30, Versions: []string{"v1", "v2"}},
+	}
+	recovered := make(chan string, 1)
+	d := &Dashboard{namespace: "default", sortBy: util.SortByStatus, changed: make(chan struct{}, 1),
+		recover: func(name string) error {
+			recovered <- name
+			return nil
+		}}
+
+	lines := strings.Split(d.Render(details, 200, 12), "\r\n")
+	require.Len(t, lines, 12)
+	require.Equal(t, "Services in namespace default (sort by status, 3 in total)", lines[0])
+	require.Equal(t, "  SERVICE   STATE           OWNERS         HEARTBEAT   VERSIONS", lines[1])
+	require.Equal(t, "> order     exchanged       tom            1m ago      -", lines[2])
+	require.Equal(t, "  cart      meshed (auto)   unknown user   30s ago     v1, v2", lines[3])
+	require.Equal(t, "  stock     normal          -              -           -", lines[4])
+	require.Equal(t, listHelp, lines[11])
+
+	require.False(t, d.HandleKey(keyDown, details))
+	require.False(t, d.HandleKey("s", details))
+	lines = strings.Split(d.Render(details, 200, 12), "\r\n")
+	require.True(t, strings.HasPrefix(lines[2], "> cart "))
+
+	require.False(t, d.HandleKey(keyEnter, details))
+	lines = strings.Split(d.Render(details, 200, 12), "\r\n")
+	require.Equal(t, "Service:   cart", lines[0])
+	require.Equal(t, "Versions:  v1, v2", lines[4])
+	require.Equal(t, detailHelp, lines[11])
+
+	require.False(t, d.HandleKey("r", details))
+	require.Equal(t, "Recover service cart ? (y/n)", d.message)
+	require.False(t, d.HandleKey("y", details))
+	require.Equal(t, "cart", <-recovered)
+
+	require.False(t, d.HandleKey(keyEscape, details))
+	require.True(t, d.HandleKey("q", details))
+}
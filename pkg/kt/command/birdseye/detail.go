@@ -0,0 +1,128 @@
+package birdseye
+
+import (
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	coreV1 "k8s.io/api/core/v1"
+	"sort"
+	"strings"
+)
+
+const (
+	StateNormal       = "normal"
+	StateExchanged    = "exchanged"
+	StateAutoMeshed   = "meshed (auto)"
+	StateManualMeshed = "meshed (manual)"
+	StatePreviewing   = "previewing"
+)
+
+// ServiceDetail status of a service and the kt pods serving it
+type ServiceDetail struct {
+	Name  string
+	State string
+	// Owners name of users who changed the service, empty for unknown
+	Owners []string
+	// HeartBeatAge seconds since last heart beat of kt pods, -1 for not available
+	HeartBeatAge int64
+	// Versions version marks of auto meshed service
+	Versions []string
+	// Pods kt pods related to the service
+	Pods []coreV1.Pod
+}
+
+// GetServiceDetails analysis status of each service via the kt services and kt pods in namespace
+func GetServiceDetails(ktSvcs []coreV1.Service, pods []coreV1.Pod, svcs []coreV1.Service) []ServiceDetail {
+	details := make([]ServiceDetail, 0)
+	for _, svc := range ktSvcs {
+		for _, p := range pods {
+			if p.Labels[util.KtRole] == util.RolePreviewShadow && util.MapContains(svc.Spec.Selector, p.Labels) {
+				details = append(details, newDetail(svc.Name, StatePreviewing, []string{getUserName(p)}, []coreV1.Pod{p}))
+				break
+			}
+		}
+	}
+svcLoop:
+	for _, svc := range svcs {
+		for _, p := range pods {
+			if util.MapContains(svc.Spec.Selector, p.Labels) {
+				if role := p.Labels[util.KtRole]; role == util.RoleExchangeShadow {
+					details = append(details, newDetail(svc.Name, StateExchanged, []string{getUserName(p)}, []coreV1.Pod{p}))
+					continue svcLoop
+				} else if role == util.RoleRouter {
+					shadowPods, versions := getMeshedPods(ktSvcs, pods, svc.Name+util.MeshPodInfix)
+					detail := newDetail(svc.Name, StateAutoMeshed, getMeshedUsers(shadowPods), append([]coreV1.Pod{p}, shadowPods...))
+					detail.Versions = versions
+					details = append(details, detail)
+					continue svcLoop
+				} else if role == util.RoleMeshShadow {
+					shadowPods, _ := getMeshedPods([]coreV1.Service{svc}, pods, svc.Name)
+					details = append(details, newDetail(svc.Name, StateManualMeshed, getMeshedUsers(shadowPods), shadowPods))
+					continue svcLoop
+				}
+			}
+		}
+		if !opt.Get().Birdseye.HideNaturalService {
+			details = append(details, newDetail(svc.Name, StateNormal, nil, nil))
+		}
+	}
+	return details
+}
+
+// Description one line summary of service status
+func (d *ServiceDetail) Description() string {
+	switch d.State {
+	case StateNormal:
+		return d.State
+	case StatePreviewing, StateExchanged:
+		return fmt.Sprintf("%s by [%s]", d.State, d.Owners[0])
+	default:
+		if len(d.Owners) == 0 {
+			return d.State + " by " + UnknownUser
+		}
+		return d.State + " by [" + strings.Join(d.Owners, "], [") + "]"
+	}
+}
+
+func newDetail(name, state string, owners []string, pods []coreV1.Pod) ServiceDetail {
+	detail := ServiceDetail{Name: name, State: state, Owners: owners, HeartBeatAge: -1, Pods: pods}
+	for _, p := range pods {
+		if lastHeartBeat := util.ParseTimestamp(p.Annotations[util.KtLastHeartBeat]); lastHeartBeat > 0 {
+			if age := util.GetTime() - lastHeartBeat; detail.HeartBeatAge < 0 || age < detail.HeartBeatAge {
+				detail.HeartBeatAge = age
+			}
+		}
+	}
+	return detail
+}
+
+// getMeshedPods find mesh shadow pods selected by services with specified name prefix, and version of each
+func getMeshedPods(svcs []coreV1.Service, pods []coreV1.Pod, namePrefix string) ([]coreV1.Pod, []string) {
+	shadowPods := make([]coreV1.Pod, 0)
+	versions := make([]string, 0)
+	for _, s := range svcs {
+		if strings.HasPrefix(s.Name, namePrefix) {
+			for _, p := range pods {
+				if p.Labels[util.KtRole] == util.RoleMeshShadow && util.MapContains(s.Spec.Selector, p.Labels) {
+					shadowPods = append(shadowPods, p)
+					if version := strings.TrimPrefix(s.Name, namePrefix); version != "" {
+						versions = append(versions, version)
+					}
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(versions)
+	return shadowPods, versions
+}
+
+func getMeshedUsers(pods []coreV1.Pod) []string {
+	users := make([]string, 0)
+	for _, p := range pods {
+		if user := p.Annotations[util.KtUser]; user != "" {
+			users = append(users, user)
+		}
+	}
+	return users
+}
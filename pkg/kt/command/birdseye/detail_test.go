@@ -0,0 +1,65 @@
+package birdseye
+
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func newPod(name, role, user, target string) coreV1.Pod {
+	return coreV1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{util.KtRole: role, util.KtTarget: target},
+			Annotations: map[string]string{util.KtUser: user, util.KtLastHeartBeat: util.GetTimestamp()},
+		},
+	}
+}
+
+func newService(name string, selector map[string]string) coreV1.Service {
+	return coreV1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       coreV1.ServiceSpec{Selector: selector},
+	}
+}
+
+func TestGetServiceDetails(t *testing.T) {
+	pods := []coreV1.Pod{
+		newPod("order-kt-exchange-abcde", util.RoleExchangeShadow, "tom", "t1"),
+		newPod("cart-kt-router", util.RoleRouter, "", "t2"),
+		newPod("cart-kt-mesh-v2", util.RoleMeshShadow, "jerry", "t3"),
+		newPod("cart-kt-mesh-v1", util.RoleMeshShadow, "", "t4"),
+		newPod("user-preview", util.RolePreviewShadow, "spike", "t5"),
+	}
+	ktSvcs := []coreV1.Service{
+		newService("cart-kt-mesh-v2", map[string]string{util.KtTarget: "t3"}),
+		newService("cart-kt-mesh-v1", map[string]string{util.KtTarget: "t4"}),
+		newService("user", map[string]string{util.KtTarget: "t5"}),
+	}
+	svcs := []coreV1.Service{
+		newService("order", map[string]string{util.KtTarget: "t1"}),
+		newService("cart", map[string]string{util.KtTarget: "t2"}),
+		newService("stock", map[string]string{"app": "stock"}),
+	}
+	details := GetServiceDetails(ktSvcs, pods, svcs)
+	require.Len(t, details, 4)
+
+	require.Equal(t, "user", details[0].Name)
+	require.Equal(t, "previewing by [spike]", details[0].Description())
+
+	require.Equal(t, StateExchanged, details[1].State)
+	require.Equal(t, "exchanged by [tom]", details[1].Description())
+	require.True(t, details[1].HeartBeatAge >= 0 && details[1].HeartBeatAge < 5)
+
+	require.Equal(t, StateAutoMeshed, details[2].State)
+	require.Equal(t, []string{"v1", "v2"}, details[2].Versions)
+	require.Equal(t, []string{"jerry"}, details[2].Owners)
+	require.Len(t, details[2].Pods, 3)
+	require.Equal(t, "meshed (auto) by [jerry]", details[2].Description())
+
+	require.Equal(t, "stock", details[3].Name)
+	require.Equal(t, "normal", details[3].Description())
+	require.Equal(t, int64(-1), details[3].HeartBeatAge)
+}
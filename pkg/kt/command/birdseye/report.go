@@ -0,0 +1,46 @@
+package birdseye
+
+// Report machine-readable summary of services status in namespace
+type Report struct {
+	Namespace  string          `json:"namespace" yaml:"namespace"`
+	Services   []ServiceStatus `json:"services" yaml:"services"`
+	Connectors []Connector     `json:"connectors,omitempty" yaml:"connectors,omitempty"`
+}
+
+// ServiceStatus machine-readable status of a service
+type ServiceStatus struct {
+	Name  string `json:"name" yaml:"name"`
+	State string `json:"state" yaml:"state"`
+	// Owners empty when service is normal or owner unknown
+	Owners []string `json:"owners" yaml:"owners"`
+	// HeartBeatAgeSeconds omitted when service is normal or no heart beat found
+	HeartBeatAgeSeconds *int64   `json:"heartBeatAgeSeconds,omitempty" yaml:"heartBeatAgeSeconds,omitempty"`
+	Versions            []string `json:"versions" yaml:"versions"`
+}
+
+// Connector machine-readable info of user connecting to cluster
+type Connector struct {
+	// User empty for unknown user
+	User              string `json:"user" yaml:"user"`
+	LastActiveMinutes *int64 `json:"lastActiveMinutes,omitempty" yaml:"lastActiveMinutes,omitempty"`
+}
+
+// ToServiceStatus convert service details to stable output schema, in the same order
+func ToServiceStatus(details []ServiceDetail) []ServiceStatus {
+	statuses := make([]ServiceStatus, 0, len(details))
+	for _, d := range details {
+		status := ServiceStatus{Name: d.Name, State: d.State, Owners: make([]string, 0), Versions: make([]string, 0)}
+		for _, owner := range d.Owners {
+			if owner != UnknownUser {
+				status.Owners = append(status.Owners, owner)
+			}
+		}
+		status.Versions = append(status.Versions, d.Versions...)
+		if d.HeartBeatAge >= 0 {
+			age := d.HeartBeatAge
+			status.HeartBeatAgeSeconds = &age
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,28 @@
+package birdseye
+
+import (
+	"encoding/json"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestToServiceStatus(t *testing.T) {
+	details := SortServiceDetails([]ServiceDetail{
+		{Name: "stock", State: StateNormal, HeartBeatAge: -1},
+		{Name: "order", State: StateExchanged, Owners: []string{UnknownUser}, HeartBeatAge: 75},
+		{Name: "cart", State: StateAutoMeshed, Owners: []string{"tom"}, HeartBeatAge: 30, Versions: []string{"v1"}},
+	}, util.SortByStatus)
+	data, err := json.Marshal(ToServiceStatus(details))
+	require.Nil(t, err)
+	require.Equal(t, `[`+
+		`{"name":"order","state":"exchanged","owners":[],"heartBeatAgeSeconds":75,"versions":[]},`+
+		`{"name":"cart","state":"meshed (auto)","owners":["tom"],"heartBeatAgeSeconds":30,"versions":["v1"]},`+
+		`{"name":"stock","state":"normal","owners":[],"versions":[]}]`, string(data))
+
+	names := make([]string, 0)
+	for _, d := range SortServiceDetails(details, util.SortByName) {
+		names = append(names, d.Name)
+	}
+	require.Equal(t, []string{"cart", "order", "stock"}, names)
+}
@@ -1,6 +1,9 @@
 package birdseye
 
-import "strings"
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"strings"
+)
 
 func SortServiceArray(svc [][]string, compIndex int) {
 	if len(svc) == 0 {
@@ -24,3 +27,22 @@ func SortServiceArray(svc [][]string, compIndex int) {
 		}
 	}
 }
+
+// SortServiceDetails sort service details by name, or by state and then name
+func SortServiceDetails(details []ServiceDetail, sortBy string) []ServiceDetail {
+	rows := make([][]string, 0, len(details))
+	index := make(map[string]ServiceDetail, len(details))
+	for _, d := range details {
+		rows = append(rows, []string{d.Name, d.State})
+		index[d.Name] = d
+	}
+	SortServiceArray(rows, 0)
+	if sortBy == util.SortByStatus {
+		SortServiceArray(rows, 1)
+	}
+	sorted := make([]ServiceDetail, 0, len(rows))
+	for _, row := range rows {
+		sorted = append(sorted, index[row[0]])
+	}
+	return sorted
+}
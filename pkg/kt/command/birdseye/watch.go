@@ -0,0 +1,90 @@
+package birdseye
+
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	coreV1 "k8s.io/api/core/v1"
+	"sync"
+)
+
+// ClusterState services and kt pods of namespace, kept up to date via informers
+type ClusterState struct {
+	lock    sync.Mutex
+	pods    map[string]*coreV1.Pod
+	svcs    map[string]*coreV1.Service
+	Changed chan struct{}
+}
+
+// WatchClusterState start watching services and kt pods in namespace
+func WatchClusterState(namespace string) *ClusterState {
+	state := &ClusterState{
+		pods:    map[string]*coreV1.Pod{},
+		svcs:    map[string]*coreV1.Service{},
+		Changed: make(chan struct{}, 1),
+	}
+	go cluster.Ins().WatchService("", namespace, state.onServiceUpdate, state.onServiceDelete, state.onServiceUpdate)
+	go cluster.Ins().WatchPod("", namespace, state.onPodUpdate, state.onPodDelete, state.onPodUpdate)
+	return state
+}
+
+// Details get current status of each service
+func (s *ClusterState) Details() []ServiceDetail {
+	s.lock.Lock()
+	pods := make([]coreV1.Pod, 0, len(s.pods))
+	for _, p := range s.pods {
+		pods = append(pods, *p)
+	}
+	ktSvcs := make([]coreV1.Service, 0)
+	otherSvcs := make([]coreV1.Service, 0)
+	for _, svc := range s.svcs {
+		if svc.Labels[util.ControlBy] == util.KubernetesToolkit {
+			ktSvcs = append(ktSvcs, *svc)
+		} else {
+			otherSvcs = append(otherSvcs, *svc)
+		}
+	}
+	s.lock.Unlock()
+	return GetServiceDetails(ktSvcs, pods, otherSvcs)
+}
+
+func (s *ClusterState) onServiceUpdate(svc *coreV1.Service) {
+	s.lock.Lock()
+	s.svcs[svc.Name] = svc
+	s.lock.Unlock()
+	s.notify()
+}
+
+func (s *ClusterState) onServiceDelete(svc *coreV1.Service) {
+	s.lock.Lock()
+	delete(s.svcs, svc.Name)
+	s.lock.Unlock()
+	s.notify()
+}
+
+func (s *ClusterState) onPodUpdate(pod *coreV1.Pod) {
+	if pod.Labels[util.ControlBy] != util.KubernetesToolkit {
+		return
+	}
+	s.lock.Lock()
+	if pod.DeletionTimestamp == nil {
+		s.pods[pod.Name] = pod
+	} else {
+		delete(s.pods, pod.Name)
+	}
+	s.lock.Unlock()
+	s.notify()
+}
+
+func (s *ClusterState) onPodDelete(pod *coreV1.Pod) {
+	s.lock.Lock()
+	delete(s.pods, pod.Name)
+	s.lock.Unlock()
+	s.notify()
+}
+
+func (s *ClusterState) notify() {
+	select {
+	case s.Changed <- struct{}{}:
+	default:
+	}
+}
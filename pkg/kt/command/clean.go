@@ -19,6 +19,9 @@ func NewCleanCommand() *cobra.Command {
 			if len(args) > 0 {
 				return fmt.Errorf("too many options specified (%s)", strings.Join(args, ",") )
 			}
+			if err := general.CheckOutputFormat(opt.Get().Clean.Output); err != nil {
+				return err
+			}
 			return general.Prepare()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,9 +37,18 @@ func NewCleanCommand() *cobra.Command {
 
 // Clean delete unavailing shadow pods
 func Clean() error {
+	output := opt.Get().Clean.Output
+	resourceToClean := clean.NewResourceToClean()
 	if !opt.Get().Clean.LocalOnly {
-		if resourceToClean, err := clean.CheckClusterResources(); err != nil {
+		if r, err := clean.CheckClusterResources(); err != nil {
+			if output != "" {
+				return err
+			}
 			log.Warn().Err(err).Msgf("Failed to clean up cluster resources")
+		} else if resourceToClean = r; output != "" {
+			if !opt.Get().Clean.DryRun && !isEmpty(resourceToClean) {
+				clean.TidyClusterResources(resourceToClean)
+			}
 		} else if isEmpty(resourceToClean) {
 			log.Info().Msg("No unavailing kt resource found (^.^)YYa!!")
 		} else if opt.Get().Clean.DryRun {
@@ -48,6 +60,14 @@ func Clean() error {
 	if !opt.Get().Clean.DryRun {
 		clean.TidyLocalResources()
 	}
+	if output != "" {
+		if err := general.PrintOutput(output, resourceToClean); err != nil {
+			return err
+		}
+		if len(resourceToClean.Failures) > 0 {
+			return fmt.Errorf("failed to clean %d resources", len(resourceToClean.Failures))
+		}
+	}
 	return nil
 }
 
@@ -55,7 +75,7 @@ func isEmpty(r *clean.ResourceToClean) bool {
 	return len(r.PodsToDelete) == 0 &&
 		len(r.ConfigMapsToDelete) == 0 &&
 		len(r.DeploymentsToDelete) == 0 &&
-		len(r.DeploymentsToScale) == 0 &&
+		len(r.WorkloadsToScale) == 0 &&
 		len(r.ServicesToDelete) == 0 &&
 		len(r.ServicesToUnlock) == 0 &&
 		len(r.ServicesToRecover) == 0
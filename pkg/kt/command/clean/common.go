@@ -17,109 +17,157 @@ import (
 	"strings"
 )
 
+const commandName = "clean"
+
+// ResourceToClean resources found by clean, also the output schema of clean command
 type ResourceToClean struct {
-	PodsToDelete        []string
-	ServicesToDelete    []string
-	ConfigMapsToDelete  []string
-	DeploymentsToDelete []string
-	DeploymentsToScale  map[string]int32
-	ServicesToRecover   []string
-	ServicesToUnlock   []string
+	PodsToDelete        []string `json:"podsToDelete" yaml:"podsToDelete"`
+	ServicesToDelete    []string `json:"servicesToDelete" yaml:"servicesToDelete"`
+	ConfigMapsToDelete  []string `json:"configMapsToDelete" yaml:"configMapsToDelete"`
+	DeploymentsToDelete []string `json:"deploymentsToDelete" yaml:"deploymentsToDelete"`
+	// WorkloadsToScale key in '<kind>/<name>' format, value is replicas to restore
+	WorkloadsToScale  map[string]int32 `json:"workloadsToScale" yaml:"workloadsToScale"`
+	ServicesToRecover []string         `json:"servicesToRecover" yaml:"servicesToRecover"`
+	ServicesToUnlock  []string         `json:"servicesToUnlock" yaml:"servicesToUnlock"`
+	// Failures key in '<kind>/<name>' format, value is the error of cleaning that resource
+	Failures map[string]string `json:"failures" yaml:"failures"`
 }
 
-
-func CheckClusterResources() (*ResourceToClean, error) {
-	pods, cfs, apps, svcs, err := cluster.Ins().GetKtResources(opt.Get().Global.Namespace)
-	if err != nil {
-		return nil, err
-	}
-	log.Debug().Msgf("Find %d kt pods", len(pods))
-	resourceToClean := ResourceToClean{
+// NewResourceToClean create an empty plan, all fields are initialized to output empty arrays
+func NewResourceToClean() *ResourceToClean {
+	return &ResourceToClean{
 		PodsToDelete:        make([]string, 0),
 		ServicesToDelete:    make([]string, 0),
 		ConfigMapsToDelete:  make([]string, 0),
 		DeploymentsToDelete: make([]string, 0),
-		DeploymentsToScale:  make(map[string]int32),
+		WorkloadsToScale:    make(map[string]int32),
 		ServicesToRecover:   make([]string, 0),
 		ServicesToUnlock:    make([]string, 0),
+		Failures:            make(map[string]string),
 	}
+}
+
+// addFailure record resource failed to clean
+func (r *ResourceToClean) addFailure(kind, name string, err error) {
+	r.Failures[fmt.Sprintf("%s/%s", kind, name)] = err.Error()
+}
+
+
+func CheckClusterResources() (*ResourceToClean, error) {
+	pods, cfs, apps, svcs, err := cluster.Ins().GetKtResources(opt.Get().Global.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Msgf("Find %d kt pods", len(pods))
+	resourceToClean := NewResourceToClean()
 	for _, pod := range pods {
-		analysisExpiredPods(pod, opt.Get().Clean.ThresholdInMinus, &resourceToClean)
+		analysisExpiredPods(pod, opt.Get().Clean.ThresholdInMinus, resourceToClean)
 	}
 	for _, cf := range cfs {
-		analysisExpiredConfigmaps(cf, opt.Get().Clean.ThresholdInMinus, &resourceToClean)
+		analysisExpiredConfigmaps(cf, opt.Get().Clean.ThresholdInMinus, resourceToClean)
 	}
 	for _, app := range apps {
-		analysisExpiredDeployments(app, opt.Get().Clean.ThresholdInMinus, &resourceToClean)
+		analysisExpiredDeployments(app, opt.Get().Clean.ThresholdInMinus, resourceToClean)
 	}
 	for _, svc := range svcs {
-		analysisExpiredServices(svc, opt.Get().Clean.ThresholdInMinus, &resourceToClean)
+		analysisExpiredServices(svc, opt.Get().Clean.ThresholdInMinus, resourceToClean)
 	}
 	svcList, err := cluster.Ins().GetAllServiceInNamespace(opt.Get().Global.Namespace)
-	analysisLockAndOrphanServices(svcList.Items, &resourceToClean)
-	return &resourceToClean, nil
+	if err != nil {
+		return nil, err
+	}
+	analysisLockAndOrphanServices(svcList.Items, resourceToClean)
+	return resourceToClean, nil
 }
 
 func TidyClusterResources(r *ResourceToClean) {
 	log.Info().Msgf("Deleting %d unavailing kt pods", len(r.PodsToDelete))
 	for _, name := range r.PodsToDelete {
+		ref := general.GetObjectRef("Pod", name, opt.Get().Global.Namespace)
 		err := cluster.Ins().RemovePod(name, opt.Get().Global.Namespace)
 		if err != nil {
 			log.Warn().Err(err).Msgf("Failed to delete pods %s", name)
+			r.addFailure("Pod", name, err)
 		} else {
 			log.Info().Msgf(" * %s", name)
+			general.RecordChange(commandName, ref, "DeletePod", "")
 		}
 	}
 	log.Info().Msgf("Deleting %d unavailing config maps", len(r.ConfigMapsToDelete))
 	for _, name := range r.ConfigMapsToDelete {
+		ref := general.GetObjectRef("ConfigMap", name, opt.Get().Global.Namespace)
 		err := cluster.Ins().RemoveConfigMap(name, opt.Get().Global.Namespace)
 		if err != nil {
 			log.Warn().Err(err).Msgf("Failed to delete config map %s", name)
+			r.addFailure("ConfigMap", name, err)
 		} else {
 			log.Info().Msgf(" * %s", name)
+			general.RecordChange(commandName, ref, "DeleteConfigMap", "")
 		}
 	}
 	log.Info().Msgf("Deleting %d unavailing deployments", len(r.DeploymentsToDelete))
 	for _, name := range r.DeploymentsToDelete {
+		ref := general.GetObjectRef("Deployment", name, opt.Get().Global.Namespace)
 		err := cluster.Ins().RemoveDeployment(name, opt.Get().Global.Namespace)
 		if err != nil {
 			log.Warn().Err(err).Msgf("Failed to delete deployment %s", name)
+			r.addFailure("Deployment", name, err)
 		} else {
 			log.Info().Msgf(" * %s", name)
+			general.RecordChange(commandName, ref, "DeleteDeployment", "")
 		}
 	}
-	log.Info().Msgf("Recovering %d scaled deployments", len(r.DeploymentsToScale))
-	for name, replica := range r.DeploymentsToScale {
-		err := cluster.Ins().ScaleTo(name, opt.Get().Global.Namespace, &replica)
+	log.Info().Msgf("Recovering %d scaled workloads", len(r.WorkloadsToScale))
+	for name, replica := range r.WorkloadsToScale {
+		kind, app, _ := strings.Cut(name, "/")
+		err := cluster.Ins().ScaleWorkload(kind, app, opt.Get().Global.Namespace, replica)
 		if err != nil {
-			log.Warn().Err(err).Msgf("Failed to scale deployment %s to %d", name, replica)
+			log.Warn().Err(err).Msgf("Failed to scale %s %s to %d", kind, app, replica)
+			r.addFailure(kind, app, err)
 		} else {
 			log.Info().Msgf(" * %s", name)
+			general.RecordChange(commandName, general.GetObjectRef(kind, app, opt.Get().Global.Namespace),
+				"ScaleWorkload", fmt.Sprintf("replicas: %d", replica))
 		}
 	}
 	log.Info().Msgf("Deleting %d unavailing services", len(r.ServicesToDelete))
 	for _, name := range r.ServicesToDelete {
+		ref := general.GetObjectRef("Service", name, opt.Get().Global.Namespace)
 		err := cluster.Ins().RemoveService(name, opt.Get().Global.Namespace)
 		if err != nil {
 			log.Warn().Err(err).Msgf("Failed to delete service %s", name)
+			r.addFailure("Service", name, err)
 		} else {
 			log.Info().Msgf(" * %s", name)
+			general.RecordChange(commandName, ref, "DeleteService", "")
 		}
 	}
 	log.Info().Msgf("Recovering %d meshed services", len(r.ServicesToRecover))
 	for _, name := range r.ServicesToRecover {
-		general.RecoverOriginalService(name, opt.Get().Global.Namespace)
-		log.Info().Msgf(" * %s", name)
+		if err := general.RecoverOriginalService(name, opt.Get().Global.Namespace); err != nil {
+			log.Warn().Err(err).Msgf("Failed to recover service %s", name)
+			r.addFailure("Service", name, err)
+		} else {
+			log.Info().Msgf(" * %s", name)
+			general.RecordChange(commandName, general.GetObjectRef("Service", name, opt.Get().Global.Namespace),
+				"RecoverService", "")
+		}
 	}
 	log.Info().Msgf("Recovering %d locked services", len(r.ServicesToUnlock))
 	for _, name := range r.ServicesToUnlock {
-		if app, err := cluster.Ins().GetService(name, opt.Get().Global.Namespace); err == nil {
+		if app, err := cluster.Ins().GetService(name, opt.Get().Global.Namespace); err != nil {
+			log.Warn().Err(err).Msgf("Failed to fetch service %s", name)
+			r.addFailure("Service", name, err)
+		} else {
 			delete(app.Annotations, util.KtLock)
 			_, err = cluster.Ins().UpdateService(app)
 			if err != nil {
-				log.Warn().Err(err).Msgf("Failed to lock service %s", name)
+				log.Warn().Err(err).Msgf("Failed to unlock service %s", name)
+				r.addFailure("Service", name, err)
 			} else {
 				log.Info().Msgf(" * %s", name)
+				general.RecordChange(commandName, general.ObjectRef("Service", app), "UnlockService",
+					fmt.Sprintf("annotations.%s: -", util.KtLock))
 			}
 		}
 	}
@@ -139,8 +187,8 @@ func PrintClusterResourcesToClean(r *ResourceToClean) {
 	for _, name := range r.DeploymentsToDelete {
 		log.Info().Msgf(" * %s", name)
 	}
-	log.Info().Msgf("Find %d exchanged deployments to recover:", len(r.DeploymentsToScale))
-	for name, replica := range r.DeploymentsToScale {
+	log.Info().Msgf("Find %d exchanged workloads to recover:", len(r.WorkloadsToScale))
+	for name, replica := range r.WorkloadsToScale {
 		log.Info().Msgf(" * %s -> %d", name, replica)
 	}
 	log.Info().Msgf("Find %d unavailing service to delete:", len(r.ServicesToDelete))
@@ -260,7 +308,12 @@ func analysisLockAndOrphanServices(svcs []coreV1.Service, resourceToClean *Resou
 		if lock, exists := svc.Annotations[util.KtLock]; exists && util.GetTime() - util.ParseTimestamp(lock) > general.LockTimeout {
 			resourceToClean.ServicesToUnlock = append(resourceToClean.ServicesToUnlock, svc.Name)
 		}
-		if svc.Annotations[util.KtSelector] != "" {
+		if sliceName := svc.Annotations[util.KtEndpointSlice]; sliceName != "" {
+			// it's an exchanged service via endpoint slice, but shadow pod already gone
+			if !isPodExist(sliceName, svc.Namespace) {
+				resourceToClean.ServicesToRecover = append(resourceToClean.ServicesToRecover, svc.Name)
+			}
+		} else if svc.Annotations[util.KtSelector] != "" {
 			if svc.Spec.Selector[util.KtRole] == util.RoleRouter {
 				// it's a meshed service, but router pod already gone
 				if !isRouterPodExist(svc.Name, svc.Namespace) {
@@ -282,8 +335,12 @@ func analysisConfigAnnotation(role string, config map[string]string, resourceToC
 	if role == util.RoleExchangeShadow {
 		replica, _ := strconv.ParseInt(config["replicas"], 10, 32)
 		app := config["app"]
+		kind := config["kind"]
+		if kind == "" {
+			kind = util.WorkloadDeployment
+		}
 		if replica > 0 && app != "" {
-			resourceToClean.DeploymentsToScale[app] = int32(replica)
+			resourceToClean.WorkloadsToScale[kind+"/"+app] = int32(replica)
 		}
 	}
 	// auto mesh and selector exchange
@@ -308,8 +365,11 @@ func isShadowPodExist(selector map[string]string, svcName, namespace, suffix str
 }
 
 func isRouterPodExist(svcName, namespace string) bool {
-	routerPodName := svcName + util.RouterPodSuffix
-	_, err := cluster.Ins().GetPod(routerPodName, namespace)
+	return isPodExist(svcName + util.RouterPodSuffix, namespace)
+}
+
+func isPodExist(name, namespace string) bool {
+	_, err := cluster.Ins().GetPod(name, namespace)
 	return err == nil
 }
 
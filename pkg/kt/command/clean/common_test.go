@@ -1,6 +1,8 @@
 package clean
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 )
 
@@ -18,3 +20,17 @@ func Test_toPid(t *testing.T) {
 		t.Errorf("unmatch %d", pid)
 	}
 }
+
+func Test_emptyResourceToClean(t *testing.T) {
+	r := NewResourceToClean()
+	r.addFailure("Pod", "kt-rectifier-abc", fmt.Errorf("forbidden"))
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Errorf("failed to marshal: %s", err)
+	}
+	expected := `{"podsToDelete":[],"servicesToDelete":[],"configMapsToDelete":[],"deploymentsToDelete":[],` +
+		`"workloadsToScale":{},"servicesToRecover":[],"servicesToUnlock":[],"failures":{"Pod/kt-rectifier-abc":"forbidden"}}`
+	if string(data) != expected {
+		t.Errorf("unmatch %s", string(data))
+	}
+}
@@ -2,48 +2,86 @@ package config
 
 import (
 	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/command/general"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/spf13/cobra"
 )
 
+const sourceConfig = "config"
+const sourceBuildIn = "build-in"
+
 var showAll bool
+var showOutput string
 
 var hiddenOptions = []string{
 	"global.as-worker",
 }
 
+// ConfigItem machine-readable value of a config option
+type ConfigItem struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	// Source 'config' for value from config file, 'build-in' for value built in ktctl, empty for not configured
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// ConfigReport machine-readable output schema of config show command
+type ConfigReport struct {
+	Items []ConfigItem `json:"items" yaml:"items"`
+}
+
 func Show(args []string) error {
 	if len(args) > 0 {
 		return fmt.Errorf("parameter '%s' is invalid", args[0])
 	}
+	if err := general.CheckOutputFormat(showOutput); err != nil {
+		return err
+	}
 	customConfig := loadCustomConfig()
 	config, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("config file is damaged, please try repair it or use 'ktctl config unset --all'")
 	}
+	items := make([]ConfigItem, 0)
 	travelConfigItem(func(groupName string, itemName string) {
-		if util.Contains(hiddenOptions, fmt.Sprintf("%s.%s", groupName, itemName)) {
+		key := fmt.Sprintf("%s.%s", groupName, itemName)
+		if util.Contains(hiddenOptions, key) {
 			return
 		}
 		if groupValue, groupExist := config[groupName]; groupExist {
 			if itemValue, itemExist := groupValue[itemName]; itemExist {
-				fmt.Printf("%s.%s = %v\n", groupName, itemName, itemValue)
+				items = append(items, ConfigItem{Key: key, Value: itemValue, Source: sourceConfig})
 				return
 			}
 		}
 		if groupValue, groupExist := customConfig[groupName]; groupExist {
 			if itemValue, itemExist := groupValue[itemName]; itemExist {
-				fmt.Printf("%s.%s = %v  (build-in)\n", groupName, itemName, itemValue)
+				items = append(items, ConfigItem{Key: key, Value: itemValue, Source: sourceBuildIn})
 				return
 			}
 		}
 		if showAll {
-			fmt.Printf("%s.%s\n", groupName, itemName)
+			items = append(items, ConfigItem{Key: key})
 		}
 	})
+	if showOutput != "" {
+		return general.PrintOutput(showOutput, ConfigReport{Items: items})
+	}
+	for _, item := range items {
+		switch item.Source {
+		case sourceConfig:
+			fmt.Printf("%s = %v\n", item.Key, item.Value)
+		case sourceBuildIn:
+			fmt.Printf("%s = %v  (build-in)\n", item.Key, item.Value)
+		default:
+			fmt.Printf("%s\n", item.Key)
+		}
+	}
 	return nil
 }
 
 func ShowHandle(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&showAll, "all", "a", false, "Show all available config options")
+	cmd.Flags().StringVarP(&showOutput, "output", "o", "",
+		fmt.Sprintf("Print options in '%s' or '%s' format", util.OutputJson, util.OutputYaml))
 }
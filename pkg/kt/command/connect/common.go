@@ -10,11 +10,13 @@ import (
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/rs/zerolog/log"
 	coreV1 "k8s.io/api/core/v1"
+	"net"
 	"strings"
 	"time"
 )
 
-func setupDns(shadowPodName, shadowPodIp string) error {
+// setupDns forwardDns is used to forward dns port of shadow pod to specified local port in local dns mode
+func setupDns(shadowPodIp string, forwardDns func(localPort int) error) error {
 	if strings.HasPrefix(opt.Get().Connect.DnsMode, util.DnsModeHosts) {
 		log.Info().Msgf("Setting up dns in hosts mode")
 		dump2HostsNamespaces := ""
@@ -37,7 +39,7 @@ func setupDns(shadowPodName, shadowPodIp string) error {
 		watchServicesAndPods(opt.Get().Global.Namespace, svcToIp, headlessPods, true)
 
 		forwardedPodPort := util.GetRandomTcpPort()
-		if _, err := transmission.SetupPortForwardToLocal(shadowPodName, common.StandardDnsPort, forwardedPodPort); err != nil {
+		if err := forwardDns(forwardedPodPort); err != nil {
 			return err
 		}
 
@@ -61,6 +63,14 @@ func setupDns(shadowPodName, shadowPodIp string) error {
 	return nil
 }
 
+// forwardDnsByPortForward forward dns port of shadow pod via kubernetes port-forward
+func forwardDnsByPortForward(shadowPodName string) func(int) error {
+	return func(localPort int) error {
+		_, err := transmission.SetupPortForwardToLocal(shadowPodName, common.StandardDnsPort, localPort)
+		return err
+	}
+}
+
 func getDnsOrder(dnsMode string) []string {
 	if ! strings.Contains(dnsMode, ":") {
 		return []string{ util.DnsOrderCluster, util.DnsOrderUpstream }
@@ -117,9 +127,18 @@ func getServiceHosts(namespace string, shortDomainOnly bool) (map[string]string,
 	podNames := make([]string, 0)
 	services, err := cluster.Ins().GetAllServiceInNamespace(namespace)
 	if err == nil {
+		var externalNameServices []coreV1.Service
 		for _, service := range services.Items {
+			if service.Spec.Type == coreV1.ServiceTypeExternalName {
+				externalNameServices = append(externalNameServices, service)
+				continue
+			}
 			ip := service.Spec.ClusterIP
 			if ip == "" || ip == "None" {
+				if len(service.Spec.Selector) == 0 {
+					// service without selector has no pod to resolve
+					continue
+				}
 				pods, err2 := cluster.Ins().GetPodsByLabel(service.Spec.Selector, namespace)
 				if err2 != nil || len(pods.Items) == 0 {
 					continue
@@ -135,20 +154,54 @@ func getServiceHosts(namespace string, shortDomainOnly bool) (map[string]string,
 			} else {
 				log.Debug().Msgf("Service found: %s.%s %s", service.Name, namespace, ip)
 			}
-			if shortDomainOnly {
-				hosts[service.Name] = ip
-			} else {
-				if namespace == opt.Get().Global.Namespace {
-					hosts[service.Name] = ip
-				}
-				hosts[fmt.Sprintf("%s.%s", service.Name, namespace)] = ip
-				hosts[fmt.Sprintf("%s.%s.svc.%s", service.Name, namespace, opt.Get().Connect.ClusterDomain)] = ip
+			addServiceHosts(hosts, service.Name, namespace, ip, shortDomainOnly)
+		}
+		if shortDomainOnly {
+			// local dns will answer external name services with CNAME record from cluster dns
+			return hosts, podNames
+		}
+		for _, service := range externalNameServices {
+			// hosts file can not hold CNAME record, use the ip of target domain instead
+			ip := resolveExternalName(service.Spec.ExternalName, hosts)
+			if ip == "" {
+				log.Debug().Msgf("Skip external name service %s.%s -> %s", service.Name, namespace, service.Spec.ExternalName)
+				continue
 			}
+			log.Debug().Msgf("External name service found: %s.%s %s (%s)", service.Name, namespace, service.Spec.ExternalName, ip)
+			addServiceHosts(hosts, service.Name, namespace, ip, shortDomainOnly)
 		}
 	}
 	return hosts, podNames
 }
 
+func addServiceHosts(hosts map[string]string, name, namespace, ip string, shortDomainOnly bool) {
+	if shortDomainOnly {
+		hosts[name] = ip
+	} else {
+		if namespace == opt.Get().Global.Namespace {
+			hosts[name] = ip
+		}
+		hosts[fmt.Sprintf("%s.%s", name, namespace)] = ip
+		hosts[fmt.Sprintf("%s.%s.svc.%s", name, namespace, opt.Get().Connect.ClusterDomain)] = ip
+	}
+}
+
+// resolveExternalName find target domain in resolved hosts first, then look up via local dns
+func resolveExternalName(externalName string, hosts map[string]string) string {
+	target := strings.TrimSuffix(externalName, ".")
+	if ip, exists := hosts[target]; exists {
+		return ip
+	}
+	if ips, err := net.LookupIP(target); err == nil {
+		for _, ip := range ips {
+			if ip.To4() != nil {
+				return ip.String()
+			}
+		}
+	}
+	return ""
+}
+
 func getOrCreateShadow() (string, string, string, error) {
 	shadowPodName := fmt.Sprintf("kt-connect-shadow-%s", strings.ToLower(util.RandomString(5)))
 	if opt.Get().Connect.ShareShadow {
@@ -156,7 +209,7 @@ func getOrCreateShadow() (string, string, string, error) {
 	}
 
 	endPointIP, podName, privateKeyPath, err := cluster.Ins().GetOrCreateShadow(shadowPodName, getLabels(),
-		make(map[string]string), getEnvs(), "", map[int]string{})
+		make(map[string]string), getEnvs(), nil, "", map[int]string{}, nil)
 	if err != nil {
 		return "", "", "", err
 	}
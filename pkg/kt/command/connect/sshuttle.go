@@ -1,9 +1,11 @@
 package connect
 
 import (
+	"fmt"
 	"github.com/alibaba/kt-connect/pkg/common"
 	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
 	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/service/sshchannel"
 	"github.com/alibaba/kt-connect/pkg/kt/service/sshuttle"
 	"github.com/alibaba/kt-connect/pkg/kt/transmission"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
@@ -37,7 +39,43 @@ func BySshuttle() error {
 		return err
 	}
 
-	return setupDns(podName, podIP)
+	return setupDns(podIP, forwardDnsBySsh(privateKeyPath, localSshPort))
+}
+
+// forwardDnsBySsh forward dns port of shadow pod via the ssh connection used by sshuttle
+func forwardDnsBySsh(privateKeyPath string, localSshPort int) func(int) error {
+	return func(localPort int) error {
+		return startDnsTunnel(privateKeyPath, localSshPort, localPort, true)
+	}
+}
+
+func startDnsTunnel(privateKeyPath string, localSshPort, localDnsPort int, isInitConnect bool) error {
+	var res = make(chan error)
+	sshAddress := fmt.Sprintf("%s:%d", common.Localhost, localSshPort)
+	localEndpoint := fmt.Sprintf("%s:%d", common.Localhost, localDnsPort)
+	remoteEndpoint := fmt.Sprintf("%s:%d", common.Localhost, common.StandardDnsPort)
+	gone := false
+	go func() {
+		// will hang here if not error happen
+		err := sshchannel.Ins().ForwardLocalToRemote(privateKeyPath, sshAddress, localEndpoint, remoteEndpoint)
+		if !gone {
+			res <-err
+		}
+		log.Debug().Err(err).Msgf("Dns tunnel interrupted")
+		time.Sleep(10 * time.Second)
+		log.Debug().Msgf("Dns tunnel reconnecting ...")
+		_ = startDnsTunnel(privateKeyPath, localSshPort, localDnsPort, false)
+	}()
+	select {
+	case err := <-res:
+		if isInitConnect {
+			log.Warn().Err(err).Msgf("Failed to setup dns tunnel")
+		}
+		return err
+	case <-time.After(1 * time.Second):
+		gone = true
+		return nil
+	}
 }
 
 func startSshuttle(req *sshuttle.SSHVPNRequest) error {
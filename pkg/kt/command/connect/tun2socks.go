@@ -52,7 +52,7 @@ func ByTun2Socks() error {
 			log.Info().Msgf("Route to tun device completed")
 		}
 	}
-	return setupDns(podName, podIP)
+	return setupDns(podIP, forwardDnsByPortForward(podName))
 }
 
 func setupTunRoute() error {
@@ -17,17 +17,16 @@ func NewExchangeCommand() *cobra.Command {
 		Use:  "exchange",
 		Short: "Redirect all requests of specified kubernetes service to local",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
+			if len(args) == 0 && opt.Get().Exchange.SessionFile == "" {
 				return fmt.Errorf("name of service to exchange is required")
-			} else if len(args) > 1 {
-				return fmt.Errorf("too many service names are spcified (%s), should be one", strings.Join(args, ",") )
 			}
 			return general.Prepare()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return Exchange(args[0])
+			return Exchange(args)
 		},
-		Example: "ktctl exchange <service-name> [command options]",
+		Example: "ktctl exchange <service-name> [command options]\n" +
+			"ktctl exchange <service-name>=<ports> <service-name>=<ports> ... [command options]",
 	}
 
 	cmd.SetUsageTemplate(general.UsageTemplate(true))
@@ -35,44 +34,147 @@ func NewExchangeCommand() *cobra.Command {
 	return cmd
 }
 
-//Exchange exchange kubernetes workload
-func Exchange(resourceName string) error {
+//Exchange exchange kubernetes workloads, all targets share the same process and are recovered together
+func Exchange(args []string) error {
+	targets, err := exchange.ParseTargets(args, opt.Get().Exchange.Expose, opt.Get().Exchange.SessionFile)
+	if err != nil {
+		return err
+	}
+	if len(targets) > 1 && (opt.Get().Exchange.RunImage != "" || opt.Get().Exchange.MountSync ||
+		opt.Get().Exchange.HealthCheck != "") {
+		return fmt.Errorf("'--runImage', '--mountSync' and '--healthCheck' only work when exchanging single target")
+	}
+
 	ch, err := general.SetupProcess(util.ComponentExchange)
 	if err != nil {
 		return err
 	}
 
-	if opt.Get().Exchange.SkipPortChecking {
-		if port := util.FindBrokenLocalPort(opt.Get().Exchange.Expose); port != "" {
-			return fmt.Errorf("no application is running on port %s", port)
+	if opt.Get().Exchange.RunImage != "" {
+		// volumes of target workload are always synced and mounted to the local container
+		targets[0].Expose, err = general.RunImageLocally(targets[0].Resource, opt.Get().Exchange.RunImage,
+			targets[0].Expose, opt.Get().Exchange.LocalDir, opt.Get().Exchange.SyncEmptyDir)
+		if err != nil {
+			return err
+		}
+	} else if opt.Get().Exchange.MountSync {
+		// sync before exchange, so that content of empty dir can be copied from original pod
+		if err = general.SyncMounts(targets[0].Resource, opt.Get().Exchange.LocalDir, opt.Get().Exchange.SyncEmptyDir); err != nil {
+			return err
+		}
+	}
+	for _, target := range targets {
+		if target.Expose == "" {
+			return fmt.Errorf("required flag(s) \"expose\" not set")
 		}
 	}
 
-	log.Info().Msgf("Using %s mode", opt.Get().Exchange.Mode)
-	if opt.Get().Exchange.Mode == util.ExchangeModeScale {
-		err = exchange.ByScale(resourceName)
-	} else if opt.Get().Exchange.Mode == util.ExchangeModeEphemeral {
-		err = exchange.ByEphemeralContainer(resourceName)
-	} else if opt.Get().Exchange.Mode == util.ExchangeModeSelector {
-		err = exchange.BySelector(resourceName)
-	} else {
-		err = fmt.Errorf("invalid exchange method '%s', supportted are %s, %s, %s", opt.Get().Exchange.Mode,
-			util.ExchangeModeSelector, util.ExchangeModeScale, util.ExchangeModeEphemeral)
+	mode := opt.Get().Exchange.Mode
+	var lockedSvcs []string
+	if mode == util.ExchangeModeSelector || mode == util.ExchangeModeCanary || mode == util.ExchangeModeEndpoint {
+		// lock all services before exchange any of them, if one failed, none of them would be exchanged
+		svcNames, err2 := getServiceNames(targets)
+		if err2 != nil {
+			return err2
+		}
+		if err = general.LockServices(svcNames, opt.Get().Global.Namespace); err != nil {
+			return err
+		}
+		lockedSvcs = svcNames
 	}
+
+	log.Info().Msgf("Using %s mode", mode)
+	exposes, err := exchangeTargets(targets)
+	// lock only protects the exchange procedure, it should not outlive the setup
+	general.UnlockServices(lockedSvcs, opt.Get().Global.Namespace)
 	if err != nil {
+		// exchanged targets are recovered when process exit
 		return err
 	}
-	resourceType, realName := toTypeAndName(resourceName)
-	log.Info().Msg("---------------------------------------------------------------")
-	log.Info().Msgf(" Now all request to %s '%s' will be redirected to local", resourceType, realName)
+	for _, target := range targets {
+		resourceType, realName := toTypeAndName(target.Resource)
+		log.Info().Msg("---------------------------------------------------------------")
+		if mode == util.ExchangeModeCanary {
+			// original pods are still selected, only a replica ratio share of requests reach local
+			log.Info().Msgf(" Now part of the requests to %s '%s' will be redirected to local", resourceType, realName)
+		} else {
+			log.Info().Msgf(" Now all request to %s '%s' will be redirected to local", resourceType, realName)
+		}
+	}
 	log.Info().Msg("---------------------------------------------------------------")
 
+	if len(exposes) == 1 {
+		// fall back to original pods if local application stop working
+		go general.MonitorLocalHealth(exposes[0], ch)
+	} else {
+		// falling back terminates the whole session, one unhealthy target should not recover the others
+		log.Info().Msgf("Auto fall back is disabled when exchanging multiple targets")
+	}
+
 	// watch background process, clean the workspace and exit if background process occur exception
 	s := <-ch
 	log.Info().Msgf("Terminal Signal is %s", s)
 	return nil
 }
 
+func exchangeTargets(targets []exchange.Target) ([]string, error) {
+	exposes := make([]string, 0)
+	for _, target := range targets {
+		if err := exchangeTarget(target.Resource, target.Expose); err != nil {
+			return nil, err
+		}
+		if opt.Store.Origin != "" {
+			opt.Store.Exchanged = append(opt.Store.Exchanged, opt.ExchangedTarget{
+				Origin:     opt.Store.Origin,
+				OriginKind: opt.Store.OriginKind,
+				Replicas:   opt.Store.Replicas,
+			})
+			opt.Store.Origin, opt.Store.OriginKind, opt.Store.Replicas = "", "", 0
+		}
+		exposes = append(exposes, target.Expose)
+	}
+	return exposes, nil
+}
+
+func exchangeTarget(resourceName, exposePorts string) error {
+	if opt.Get().Exchange.Mode != util.ExchangeModeEphemeral {
+		// in ephemeral mode, sidecar of target pods are detected respectively
+		general.DetectSidecar(resourceName)
+	}
+
+	var err error
+	if opt.Get().Exchange.Mode == util.ExchangeModeScale {
+		err = exchange.ByScale(resourceName, exposePorts)
+	} else if opt.Get().Exchange.Mode == util.ExchangeModeEphemeral {
+		err = exchange.ByEphemeralContainer(resourceName, exposePorts)
+	} else if opt.Get().Exchange.Mode == util.ExchangeModeSelector {
+		err = exchange.BySelector(resourceName, exposePorts)
+	} else if opt.Get().Exchange.Mode == util.ExchangeModeCanary {
+		err = exchange.ByCanary(resourceName, exposePorts)
+	} else if opt.Get().Exchange.Mode == util.ExchangeModeEndpoint {
+		err = exchange.ByEndpoint(resourceName, exposePorts)
+	} else {
+		err = fmt.Errorf("invalid exchange method '%s', supportted are %s, %s, %s, %s, %s", opt.Get().Exchange.Mode,
+			util.ExchangeModeSelector, util.ExchangeModeScale, util.ExchangeModeCanary, util.ExchangeModeEndpoint,
+			util.ExchangeModeEphemeral)
+	}
+	return err
+}
+
+func getServiceNames(targets []exchange.Target) ([]string, error) {
+	svcNames := make([]string, 0)
+	for _, target := range targets {
+		svc, err := general.GetServiceByResourceName(target.Resource, opt.Get().Global.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !util.Contains(svcNames, svc.Name) {
+			svcNames = append(svcNames, svc.Name)
+		}
+	}
+	return svcNames, nil
+}
+
 func toTypeAndName(name string) (string, string) {
 	parts := strings.Split(name, "/")
 	if len(parts) > 1 {
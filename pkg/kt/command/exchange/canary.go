@@ -0,0 +1,135 @@
+package exchange
+
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/command/general"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	"math"
+	"strings"
+)
+
+// ByCanary keep original pods selected by the service, and add shadow pods alongside them,
+// so that only part of the requests are redirected to local
+func ByCanary(resourceName, exposePorts string) error {
+	// Get service to exchange
+	svc, err := general.GetServiceByResourceName(resourceName, opt.Get().Global.Namespace)
+	if err != nil {
+		return err
+	}
+	if port := util.FindInvalidRemotePort(exposePorts, general.GetTargetPorts(svc)); port != "" {
+		return fmt.Errorf("target port %s not exists in service %s", port, svc.Name)
+	}
+	ratio := opt.Get().Exchange.ReplicaRatio
+	if ratio < 0 || ratio >= 100 {
+		return fmt.Errorf("replica ratio should be in range [0, 100), but got %d", ratio)
+	}
+
+	// Service should have been locked before exchange, see general.LockServices
+	if err = checkServiceNotOccupied(svc); err != nil {
+		return err
+	}
+	originSelector := svc.Spec.Selector
+	if len(originSelector) == 0 {
+		return fmt.Errorf("service '%s' has no selector, cannot apply canary exchange", svc.Name)
+	}
+	originPods, err := getOriginPods(originSelector, svc.Namespace)
+	if err != nil {
+		return err
+	}
+
+	var origin *cluster.Workload
+	if opt.Get().Exchange.CloneTemplate {
+		if origin, err = general.GetWorkloadByResourceName(resourceName, opt.Get().Global.Namespace); err != nil {
+			return err
+		}
+	}
+
+	// Label original pods, and keep labeling the new ones
+	canary := strings.ToLower(util.RandomString(20))
+	for _, pod := range originPods {
+		if err = cluster.Ins().AddPodLabel(pod.Name, pod.Namespace, util.KtCanary, canary); err != nil {
+			return err
+		}
+	}
+	go cluster.Ins().WatchPod("", svc.Namespace, func(pod *coreV1.Pod) {
+		labelNewOriginPod(pod, originSelector, canary)
+	}, nil, func(pod *coreV1.Pod) {
+		labelNewOriginPod(pod, originSelector, canary)
+	})
+
+	// Create shadow pods
+	shadowCount := getCanaryShadowCount(ratio, len(originPods))
+	log.Info().Msgf("Creating %d shadow pods alongside %d original pods", shadowCount, len(originPods))
+	for i := 0; i < shadowCount; i++ {
+		shadowName := svc.Name + util.ExchangePodInfix + strings.ToLower(util.RandomString(5))
+		shadowLabels := map[string]string{
+			util.KtRole:   util.RoleExchangeShadow,
+			util.KtCanary: canary,
+			util.KtTarget: util.RandomString(20),
+		}
+		annotation := map[string]string{
+			util.KtConfig: fmt.Sprintf("service=%s", svc.Name),
+		}
+		if err = general.CreateShadowAndInbound(shadowName, exposePorts,
+			shadowLabels, annotation, general.GetTargetPorts(svc), origin); err != nil {
+			return err
+		}
+	}
+
+	// Wait for local application ready before redirect requests to it
+	if err = general.WaitLocalReady(exposePorts); err != nil {
+		return err
+	}
+
+	// Let target service select both original and shadow pods
+	opt.Store.Origin = svc.Name
+	if err = general.UpdateServiceSelector(svc.Name, opt.Get().Global.Namespace,
+		map[string]string{util.KtCanary: canary}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func getOriginPods(selector map[string]string, namespace string) ([]coreV1.Pod, error) {
+	pods, err := cluster.Ins().GetPodsByLabel(selector, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var originPods []coreV1.Pod
+	for _, pod := range pods.Items {
+		if pod.Labels[util.KtRole] == "" && pod.DeletionTimestamp == nil && pod.Status.Phase == coreV1.PodRunning {
+			originPods = append(originPods, pod)
+		}
+	}
+	return originPods, nil
+}
+
+func labelNewOriginPod(pod *coreV1.Pod, originSelector map[string]string, canary string) {
+	if pod.DeletionTimestamp != nil || pod.Labels[util.KtRole] != "" || pod.Labels[util.KtCanary] == canary ||
+		!util.MapContains(pod.Labels, originSelector) {
+		return
+	}
+	if err := cluster.Ins().AddPodLabel(pod.Name, pod.Namespace, util.KtCanary, canary); err != nil {
+		log.Warn().Err(err).Msgf("Failed to label new pod %s", pod.Name)
+	} else {
+		log.Info().Msgf("New pod %s joined canary exchange", pod.Name)
+	}
+}
+
+// getCanaryShadowCount calculate shadow pod count to make up specified percentage of all pods behind service,
+// it's a replica ratio rather than an exact traffic split, at least one shadow pod is required
+func getCanaryShadowCount(ratio, originCount int) int {
+	if ratio <= 0 || originCount == 0 {
+		return 1
+	}
+	count := int(math.Round(float64(ratio*originCount) / float64(100-ratio)))
+	if count < 1 {
+		return 1
+	}
+	return count
+}
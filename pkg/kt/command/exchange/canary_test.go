@@ -0,0 +1,17 @@
+package exchange
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_getCanaryShadowCount(t *testing.T) {
+	require.Equal(t, 1, getCanaryShadowCount(0, 3))
+	require.Equal(t, 1, getCanaryShadowCount(10, 0))
+	require.Equal(t, 1, getCanaryShadowCount(10, 3))
+	require.Equal(t, 1, getCanaryShadowCount(50, 1))
+	require.Equal(t, 3, getCanaryShadowCount(50, 3))
+	require.Equal(t, 1, getCanaryShadowCount(10, 9))
+	require.Equal(t, 2, getCanaryShadowCount(20, 8))
+	require.Equal(t, 9, getCanaryShadowCount(90, 1))
+}
@@ -0,0 +1,63 @@
+package exchange
+
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/command/general"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"strings"
+)
+
+// ByEndpoint route service to shadow pod via endpoint slice, without changing the service spec
+func ByEndpoint(resourceName, exposePorts string) error {
+	// Get service to exchange
+	svc, err := general.GetServiceByResourceName(resourceName, opt.Get().Global.Namespace)
+	if err != nil {
+		return err
+	}
+	if port := util.FindInvalidRemotePort(exposePorts, general.GetTargetPorts(svc)); port != "" {
+		return fmt.Errorf("target port %s not exists in service %s", port, svc.Name)
+	}
+
+	// Service should have been locked before exchange, see general.LockServices
+	if err = checkServiceNotOccupied(svc); err != nil {
+		return err
+	}
+
+	var origin *cluster.Workload
+	if opt.Get().Exchange.CloneTemplate {
+		if origin, err = general.GetWorkloadByResourceName(resourceName, opt.Get().Global.Namespace); err != nil {
+			return err
+		}
+	}
+
+	// Create shadow pod
+	targetPorts := general.GetTargetPorts(svc)
+	shadowName := svc.Name + util.ExchangePodInfix + strings.ToLower(util.RandomString(5))
+	shadowLabels := map[string]string{
+		util.KtRole:   util.RoleExchangeShadow,
+		util.KtTarget: util.RandomString(20),
+	}
+	annotation := map[string]string{
+		util.KtConfig: fmt.Sprintf("service=%s", svc.Name),
+	}
+	if err = general.CreateShadowAndInbound(shadowName, exposePorts,
+		shadowLabels, annotation, targetPorts, origin); err != nil {
+		return err
+	}
+
+	// Wait for local application ready before redirect requests to it
+	if err = general.WaitLocalReady(exposePorts); err != nil {
+		return err
+	}
+
+	// Let target service route to shadow pod, endpoint slice has the same name as shadow pod
+	opt.Store.Origin = svc.Name
+	if err = general.UpdateServiceEndpoints(svc.Name, opt.Get().Global.Namespace, shadowName,
+		shadowLabels, targetPorts); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,23 +2,37 @@ package exchange
 
 import (
 	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
 	"github.com/alibaba/kt-connect/pkg/kt/command/general"
 	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
 	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
-	"github.com/alibaba/kt-connect/pkg/kt/service/sshchannel"
 	"github.com/alibaba/kt-connect/pkg/kt/transmission"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/alibaba/kt-connect/pkg/navigator"
+	"github.com/alibaba/kt-connect/pkg/shadow/udprelay"
 	"github.com/rs/zerolog/log"
 	coreV1 "k8s.io/api/core/v1"
+	"net"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func ByEphemeralContainer(resourceName string) error {
-	log.Warn().Msgf("Experimental feature. It just works on kubernetes above v1.23, and it can NOT work with istio.")
+func ByEphemeralContainer(resourceName, exposePorts string) error {
+	log.Warn().Msgf("Experimental feature. It just works on kubernetes above v1.23.")
+	if opt.Get().Exchange.CloneTemplate {
+		log.Warn().Msgf("Option --cloneTemplate is ignored in %s mode", util.ExchangeModeEphemeral)
+	}
 
 	pods, err := getPodsOfResource(resourceName, opt.Get().Global.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// Wait for local application ready before redirect requests to it
+	if err = general.WaitLocalReady(exposePorts); err != nil {
+		return err
+	}
 
 	for _, pod := range pods {
 		if pod.Status.Phase != coreV1.PodRunning {
@@ -33,19 +47,18 @@ func ByEphemeralContainer(resourceName string) error {
 		// record data
 		opt.Store.Shadow = util.Append(opt.Store.Shadow, pod.Name)
 
-		localSSHPort, err2 := transmission.ForwardPodToLocal(opt.Get().Exchange.Expose, pod.Name, privateKey)
-		if err2 != nil {
-			return err2
+		// with sidecar, inbound traffic is captured by proxy, only requests forwarded by proxy should be redirected
+		sidecar, proxyUid := cluster.GetSidecarOfPod(&pod)
+		if sidecar != "" {
+			log.Info().Msgf("Pod %s has %s sidecar, redirecting requests forwarded by proxy", pod.Name, sidecar)
 		}
-		err = exchangeWithEphemeralContainer(opt.Get().Exchange.Expose, localSSHPort, privateKey)
-		if err != nil {
+		if err = exchangeWithEphemeralContainer(exposePorts, &pod, privateKey, proxyUid); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-
 func getPodsOfResource(resourceName, namespace string) ([]coreV1.Pod, error) {
 	resourceType, name, err := general.ParseResourceName(resourceName)
 	if err != nil {
@@ -91,7 +104,7 @@ func createEphemeralContainer(containerName, podName string) (string, error) {
 
 	for i := 0; i < 10; i++ {
 		log.Info().Msgf("Waiting for ephemeral container %s to be ready", containerName)
-		ready, err2 := isEphemeralContainerReady(containerName, podName, opt.Get().Global.Namespace)
+		ready, err2 := isEphemeralContainerReady(podName, containerName, opt.Get().Global.Namespace)
 		if err2 != nil {
 			return "", err2
 		} else if ready {
@@ -121,9 +134,10 @@ func isEphemeralContainerReady(podName, containerName, namespace string) (bool,
 	return false, nil
 }
 
-func exchangeWithEphemeralContainer(exposePorts string, localSSHPort int, privateKey string) error {
+func exchangeWithEphemeralContainer(exposePorts string, pod *coreV1.Pod, privateKey string, proxyUid int) error {
+	podName := pod.Name
 	// Get all listened ports on remote host
-	listenedPorts, err := getListenedPorts(localSSHPort, privateKey)
+	listenedPorts, err := getListenedPorts(podName)
 	if err != nil {
 		return err
 	}
@@ -132,63 +146,189 @@ func exchangeWithEphemeralContainer(exposePorts string, localSSHPort int, privat
 	if err != nil {
 		return err
 	}
-	var redirectPortStr string
-	for k, v := range redirectPorts {
-		redirectPortStr += fmt.Sprintf("%d:%d,", k, v)
+	protocols := getPortProtocols(pod)
+	if proxyUid > 0 {
+		// sidecar proxy only forwards tcp traffic
+		protocols = map[int][]string{}
 	}
-	redirectPortStr = redirectPortStr[:len(redirectPortStr)-1]
-	err = setupIptables(redirectPortStr, localSSHPort, privateKey)
-	if err != nil {
-		return err
+	// datagrams of udp port are redirected to another port, and carried to local via a tunnel port
+	udpRedirectPorts, udpTunnelPorts := map[int]int{}, map[int]int{}
+	if udpPorts := udpExposePorts(exposePorts, protocols); udpPorts != "" {
+		if udpRedirectPorts, err = remoteRedirectPort(udpPorts, listenedPorts); err != nil {
+			return err
+		}
+		if udpTunnelPorts, err = remoteRedirectPort(udpPorts, listenedPorts); err != nil {
+			return err
+		}
 	}
 
-	if err = transmission.ForwardRemotePortsViaSshTunnel(exposePorts, localSSHPort, privateKey); err != nil {
+	// reverse tunnel listen on redirect ports, traffic to original ports are redirected to them by navigator
+	localSshPort := util.GetRandomTcpPort()
+	if _, err = transmission.SetupPortForwardToLocal(podName, common.StandardSshPort, localSshPort); err != nil {
+		return err
+	}
+	var tunnelPorts, relayRules []string
+	for _, exposePort := range strings.Split(exposePorts, ",") {
+		localPort, remotePort, _ := util.ParsePortMapping(exposePort)
+		if util.Contains(protocolsOf(protocols, remotePort), navigator.ProtocolTcp) {
+			tunnelPorts = append(tunnelPorts, fmt.Sprintf("%d:%d", localPort, redirectPorts[remotePort]))
+		}
+		if tunnelPort, exists := udpTunnelPorts[remotePort]; exists {
+			relayPort, err2 := startLocalUdpRelay(localPort)
+			if err2 != nil {
+				return err2
+			}
+			tunnelPorts = append(tunnelPorts, fmt.Sprintf("%d:%d", relayPort, tunnelPort))
+			relayRules = append(relayRules, navigator.RelayRule{Port: udpRedirectPorts[remotePort],
+				TunnelPort: tunnelPort, TargetPort: localPort}.String())
+		}
+	}
+	if err = transmission.ForwardRemotePortsViaSshTunnel(strings.Join(tunnelPorts, ","), localSshPort, privateKey); err != nil {
 		return err
 	}
 
+	rules := buildRedirectRules(exposePorts, protocols, redirectPorts, udpRedirectPorts)
+	if err = setupRedirect(podName, strings.Join(rules, ","), proxyUid); err != nil {
+		return err
+	}
+	if len(relayRules) > 0 {
+		if err = setupUdpRelay(podName, strings.Join(relayRules, ",")); err != nil {
+			return err
+		}
+	}
+	go renewRedirectLease(podName)
 	return nil
 }
 
+// getPortProtocols protocols of each port declared in container spec, in lower case
+func getPortProtocols(pod *coreV1.Pod) map[int][]string {
+	protocols := map[int][]string{}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			protocol := strings.ToLower(string(p.Protocol))
+			if protocol == "" {
+				protocol = navigator.ProtocolTcp
+			}
+			if (protocol == navigator.ProtocolTcp || protocol == navigator.ProtocolUdp) &&
+				!util.Contains(protocols[int(p.ContainerPort)], protocol) {
+				protocols[int(p.ContainerPort)] = append(protocols[int(p.ContainerPort)], protocol)
+			}
+		}
+	}
+	return protocols
+}
 
-func setupIptables(redirectPorts string, localSSHPort int, privateKey string) error {
-	res, err := sshchannel.Ins().RunScript(
-		privateKey,
-		fmt.Sprintf("127.0.0.1:%d", localSSHPort),
-		fmt.Sprintf("/setup_iptables.sh %s", redirectPorts))
+// protocolsOf protocols of port, port not declared in container spec is considered as tcp
+func protocolsOf(protocols map[int][]string, port int) []string {
+	if len(protocols[port]) == 0 {
+		return []string{navigator.ProtocolTcp}
+	}
+	return protocols[port]
+}
 
+// udpExposePorts expose ports with udp protocol
+func udpExposePorts(exposePorts string, protocols map[int][]string) string {
+	var udpPorts []string
+	for _, exposePort := range strings.Split(exposePorts, ",") {
+		if _, remotePort, err := util.ParsePortMapping(exposePort); err == nil &&
+			util.Contains(protocolsOf(protocols, remotePort), navigator.ProtocolUdp) {
+			udpPorts = append(udpPorts, exposePort)
+		}
+	}
+	return strings.Join(udpPorts, ",")
+}
+
+// buildRedirectRules redirect rules of navigator in "<protocol>/<port>:<redirect-port>" format, for each protocol of port
+func buildRedirectRules(exposePorts string, protocols map[int][]string, redirectPorts, udpRedirectPorts map[int]int) []string {
+	var rules []string
+	for _, exposePort := range strings.Split(exposePorts, ",") {
+		_, remotePort, _ := util.ParsePortMapping(exposePort)
+		for _, protocol := range protocolsOf(protocols, remotePort) {
+			if protocol == navigator.ProtocolUdp {
+				rules = append(rules, fmt.Sprintf("udp/%d:%d", remotePort, udpRedirectPorts[remotePort]))
+			} else {
+				rules = append(rules, fmt.Sprintf("tcp/%d:%d", remotePort, redirectPorts[remotePort]))
+			}
+		}
+	}
+	return rules
+}
+
+// startLocalUdpRelay listen a local tcp port for datagrams carried via reverse tunnel, and send them to local udp port
+func startLocalUdpRelay(localPort int) (int, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(common.Localhost, "0"))
 	if err != nil {
-		log.Error().Err(err).Msgf("Setup iptables failed, error")
+		return 0, err
 	}
+	go udprelay.Serve(listener, []string{net.JoinHostPort(common.Localhost, strconv.Itoa(localPort))})
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
 
-	log.Debug().Msgf("Run setup iptables result: %s", res)
-	return err
+func setupRedirect(podName, rules string, proxyUid int) error {
+	stdout, stderr, err := cluster.Ins().ExecInPod(util.KtExchangeContainer, podName, opt.Get().Global.Namespace,
+		util.NavigatorBin, "setup", rules, strconv.Itoa(util.NavigatorLeaseSeconds), strconv.Itoa(proxyUid))
+	log.Debug().Msgf("Stdout: %s", stdout)
+	log.Debug().Msgf("Stderr: %s", stderr)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to setup redirect rules in pod %s", podName)
+		return err
+	}
+	log.Info().Msgf("Redirect rules %s added to pod %s", rules, podName)
+	return nil
 }
 
-func getListenedPorts(localSSHPort int, privateKey string) (map[int]struct{}, error) {
-	result, err := sshchannel.Ins().RunScript(
-		privateKey,
-		fmt.Sprintf("127.0.0.1:%d", localSSHPort),
-		`netstat -tuln | grep -E '^(tcp|udp|tcp6)' | grep LISTEN | awk '{print $4}' | awk -F: '{printf("%s\n", $NF)}'`)
+func setupUdpRelay(podName, relayRules string) error {
+	stdout, stderr, err := cluster.Ins().ExecInPod(util.KtExchangeContainer, podName, opt.Get().Global.Namespace,
+		util.NavigatorBin, "relay", relayRules)
+	log.Debug().Msgf("Stdout: %s", stdout)
+	log.Debug().Msgf("Stderr: %s", stderr)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to setup udp relay in pod %s", podName)
+		return err
+	}
+	log.Info().Msgf("Udp relay %s added to pod %s", relayRules, podName)
+	return nil
+}
+
+// renewRedirectLease keep redirect rules alive, navigator will remove them once ktctl exit unexpectedly
+func renewRedirectLease(podName string) {
+	ticker := time.NewTicker(util.NavigatorLeaseSeconds * time.Second / 3)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, _, err := cluster.Ins().ExecInPod(util.KtExchangeContainer, podName, opt.Get().Global.Namespace,
+			util.NavigatorBin, "renew", strconv.Itoa(util.NavigatorLeaseSeconds)); err != nil {
+			log.Warn().Err(err).Msgf("Failed to renew redirect rules of pod %s", podName)
+		} else {
+			log.Debug().Msgf("Redirect rules of pod %s renewed", podName)
+		}
+	}
+}
 
+func getListenedPorts(podName string) (map[int]struct{}, error) {
+	stdout, stderr, err := cluster.Ins().ExecInPod(util.KtExchangeContainer, podName, opt.Get().Global.Namespace,
+		util.NavigatorBin, "ports")
 	if err != nil {
+		log.Debug().Msgf("Stderr: %s", stderr)
 		return nil, err
 	}
 
-	log.Debug().Msgf("Run get listened ports result: %s", result)
+	log.Debug().Msgf("Listened ports of pod %s: %s", podName, stdout)
 	var listenedPorts = make(map[int]struct{})
-	// The result should be a string like
-	// 38059
-	// 22
-	parts := strings.Split(result, "\n")
-	for i := range parts {
-		if len(parts[i]) > 0 {
-			port, err2 := strconv.Atoi(parts[i])
-			if err2 != nil {
-				log.Warn().Err(err2).Msgf("Failed to fetch listened ports (got '%s')", parts[i])
-				continue
-			}
-			listenedPorts[port] = struct{}{}
+	// The result should be lines like
+	// tcp 8080
+	// udp 53
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
 		}
+		port, err2 := strconv.Atoi(fields[1])
+		if err2 != nil {
+			log.Warn().Err(err2).Msgf("Failed to fetch listened ports (got '%s')", line)
+			continue
+		}
+		// avoid conflict with both tcp and udp ports
+		listenedPorts[port] = struct{}{}
 	}
 
 	return listenedPorts, nil
@@ -206,6 +346,7 @@ func remoteRedirectPort(exposePorts string, listenedPorts map[int]struct{}) (map
 		if port == -1 {
 			return nil, fmt.Errorf("failed to find redirect port for port: %d", remotePort)
 		}
+		listenedPorts[port] = struct{}{}
 		redirectPort[remotePort] = port
 	}
 
@@ -221,4 +362,3 @@ func randPort(listenedPorts map[int]struct{}) int {
 	}
 	return -1
 }
-
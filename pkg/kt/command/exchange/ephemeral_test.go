@@ -0,0 +1,29 @@
+package exchange
+
+import (
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func Test_getPortProtocols(t *testing.T) {
+	pod := &coreV1.Pod{Spec: coreV1.PodSpec{Containers: []coreV1.Container{{
+		Ports: []coreV1.ContainerPort{
+			{ContainerPort: 53, Protocol: coreV1.ProtocolUDP},
+			{ContainerPort: 53, Protocol: coreV1.ProtocolTCP},
+			{ContainerPort: 8080},
+			{ContainerPort: 9000, Protocol: coreV1.ProtocolSCTP},
+		},
+	}}}}
+	require.Equal(t, map[int][]string{53: {"udp", "tcp"}, 8080: {"tcp"}}, getPortProtocols(pod))
+}
+
+func Test_buildRedirectRules(t *testing.T) {
+	protocols := map[int][]string{53: {"udp", "tcp"}, 514: {"udp"}}
+	redirectPorts := map[int]int{53: 20001, 514: 20002, 80: 20003}
+	udpRedirectPorts := map[int]int{53: 20004, 514: 20005}
+	require.Equal(t, "53,1514:514", udpExposePorts("53,1514:514,8080:80", protocols))
+	require.Equal(t, []string{"udp/53:20004", "tcp/53:20001", "udp/514:20005", "tcp/80:20003"},
+		buildRedirectRules("53,1514:514,8080:80", protocols, redirectPorts, udpRedirectPorts))
+	require.Equal(t, "", udpExposePorts("8080:80", map[int][]string{}))
+}
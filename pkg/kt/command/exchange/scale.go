@@ -7,30 +7,33 @@ import (
 	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/rs/zerolog/log"
-	appV1 "k8s.io/api/apps/v1"
 	"strings"
 )
 
-func ByScale(resourceName string) error {
-	app, err := general.GetDeploymentByResourceName(resourceName, opt.Get().Global.Namespace)
+func ByScale(resourceName, exposePorts string) error {
+	app, err := general.GetWorkloadByResourceName(resourceName, opt.Get().Global.Namespace)
 	if err != nil {
 		return err
 	}
 
 	// record context inorder to remove after command exit
 	opt.Store.Origin = app.Name
-	opt.Store.Replicas = *app.Spec.Replicas
+	opt.Store.OriginKind = app.Kind
+	opt.Store.Replicas = app.Replicas
 
 	shadowPodName := app.Name + util.ExchangePodInfix + strings.ToLower(util.RandomString(5))
 
 	log.Info().Msgf("Creating exchange shadow %s in namespace %s", shadowPodName, opt.Get().Global.Namespace)
-	if err = general.CreateShadowAndInbound(shadowPodName, opt.Get().Exchange.Expose,
-		getExchangeLabels(app), getExchangeAnnotation(), map[int]string{}); err != nil {
+	if err = general.CreateShadowAndInbound(shadowPodName, exposePorts,
+		getExchangeLabels(app), getExchangeAnnotation(), map[int]string{}, getWorkloadToClone(app)); err != nil {
 		return err
 	}
 
-	down := int32(0)
-	if err = cluster.Ins().ScaleTo(app.Name, opt.Get().Global.Namespace, &down); err != nil {
+	// Wait for local application ready before scale down original workload
+	if err = general.WaitLocalReady(exposePorts); err != nil {
+		return err
+	}
+	if err = cluster.Ins().ScaleWorkload(app.Kind, app.Name, opt.Get().Global.Namespace, 0); err != nil {
 		return err
 	}
 
@@ -39,19 +42,26 @@ func ByScale(resourceName string) error {
 
 func getExchangeAnnotation() map[string]string {
 	return map[string]string{
-		util.KtConfig: fmt.Sprintf("app=%s,replicas=%d",
-			opt.Store.Origin, opt.Store.Replicas),
+		util.KtConfig: fmt.Sprintf("app=%s,replicas=%d,kind=%s",
+			opt.Store.Origin, opt.Store.Replicas, opt.Store.OriginKind),
 	}
 }
 
-func getExchangeLabels(origin *appV1.Deployment) map[string]string {
+func getExchangeLabels(origin *cluster.Workload) map[string]string {
 	labels := map[string]string{
 		util.KtRole: util.RoleExchangeShadow,
 	}
 	if origin != nil {
-		for k, v := range origin.Spec.Selector.MatchLabels {
+		for k, v := range origin.Selector {
 			labels[k] = v
 		}
 	}
 	return labels
 }
+
+func getWorkloadToClone(origin *cluster.Workload) *cluster.Workload {
+	if !opt.Get().Exchange.CloneTemplate {
+		return nil
+	}
+	return origin
+}
@@ -4,37 +4,32 @@ import (
 	"fmt"
 	"github.com/alibaba/kt-connect/pkg/kt/command/general"
 	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
 	"strings"
 )
 
-func BySelector(resourceName string) error {
+func BySelector(resourceName, exposePorts string) error {
 	// Get service to exchange
 	svc, err := general.GetServiceByResourceName(resourceName, opt.Get().Global.Namespace)
 	if err != nil {
 		return err
 	}
-	if port := util.FindInvalidRemotePort(opt.Get().Exchange.Expose, general.GetTargetPorts(svc)); port != "" {
+	if port := util.FindInvalidRemotePort(exposePorts, general.GetTargetPorts(svc)); port != "" {
 		return fmt.Errorf("target port %s not exists in service %s", port, svc.Name)
 	}
 
-	// Lock service to avoid conflict, must be first step
-	svc, err = general.LockService(svc.Name, opt.Get().Global.Namespace, 0);
-	if err != nil {
+	// Service should have been locked before exchange, see general.LockServices
+	if err = checkServiceNotOccupied(svc); err != nil {
 		return err
 	}
-	defer general.UnlockService(svc.Name, opt.Get().Global.Namespace)
 
-	if svc.Annotations != nil && svc.Annotations[util.KtSelector] != "" {
-		if svc.Spec.Selector[util.KtRole] == util.RoleExchangeShadow {
-			return fmt.Errorf("service '%s' is already exchanging by another user%s, cannot apply exchange",
-				svc.Name, general.GetOccupiedUser(svc.Spec.Selector))
-		} else if svc.Spec.Selector[util.KtRole] == util.RoleRouter {
-			return fmt.Errorf("another user is meshing service '%s', cannot apply exchange", svc.Name)
-		} else {
-			log.Warn().Msgf("Service '%s' has %s annotation, but either selecting shadow or router pod", svc.Name, util.KtSelector)
-			return fmt.Errorf("service '%s' in invalid status, please manually remove %s annotation before exchange", svc.Name, util.KtSelector)
+	var origin *cluster.Workload
+	if opt.Get().Exchange.CloneTemplate {
+		if origin, err = general.GetWorkloadByResourceName(resourceName, opt.Get().Global.Namespace); err != nil {
+			return err
 		}
 	}
 
@@ -47,8 +42,13 @@ func BySelector(resourceName string) error {
 	annotation := map[string]string{
 		util.KtConfig: fmt.Sprintf("service=%s", svc.Name),
 	}
-	if err = general.CreateShadowAndInbound(shadowName, opt.Get().Exchange.Expose,
-		shadowLabels, annotation, general.GetTargetPorts(svc)); err != nil {
+	if err = general.CreateShadowAndInbound(shadowName, exposePorts,
+		shadowLabels, annotation, general.GetTargetPorts(svc), origin); err != nil {
+		return err
+	}
+
+	// Wait for local application ready before redirect requests to it
+	if err = general.WaitLocalReady(exposePorts); err != nil {
 		return err
 	}
 
@@ -60,3 +60,28 @@ func BySelector(resourceName string) error {
 
 	return nil
 }
+
+func checkServiceNotOccupied(svc *coreV1.Service) error {
+	if svc.Annotations != nil && svc.Annotations[util.KtEndpointSlice] != "" {
+		return fmt.Errorf("service '%s' is already exchanging by another user via endpoint slice %s, cannot apply exchange",
+			svc.Name, svc.Annotations[util.KtEndpointSlice])
+	}
+	if svc.Annotations != nil && svc.Annotations[util.KtSelector] != "" {
+		if svc.Spec.Selector[util.KtRole] == util.RoleExchangeShadow {
+			return fmt.Errorf("service '%s' is already exchanging by another user%s, cannot apply exchange",
+				svc.Name, general.GetOccupiedUser(svc.Spec.Selector))
+		} else if svc.Spec.Selector[util.KtCanary] != "" {
+			return fmt.Errorf("service '%s' is already exchanging by another user%s, cannot apply exchange",
+				svc.Name, general.GetOccupiedUser(map[string]string{
+					util.KtCanary: svc.Spec.Selector[util.KtCanary],
+					util.KtRole: util.RoleExchangeShadow,
+				}))
+		} else if svc.Spec.Selector[util.KtRole] == util.RoleRouter {
+			return fmt.Errorf("another user is meshing service '%s', cannot apply exchange", svc.Name)
+		} else {
+			log.Warn().Msgf("Service '%s' has %s annotation, but either selecting shadow or router pod", svc.Name, util.KtSelector)
+			return fmt.Errorf("service '%s' in invalid status, please manually remove %s annotation before exchange", svc.Name, util.KtSelector)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package exchange
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// Target a resource to exchange and its ports to expose
+type Target struct {
+	Resource string `yaml:"resource"`
+	Expose   string `yaml:"expose"`
+}
+
+// Session targets to exchange in one session
+type Session struct {
+	Targets []Target `yaml:"targets"`
+}
+
+// ParseTargets get targets to exchange from command arguments in '<resource>' or '<resource>=<ports>' format,
+// and from session file if specified, the '--expose' option is used when only one target without ports specified
+func ParseTargets(args []string, expose, sessionFile string) ([]Target, error) {
+	targets := make([]Target, 0)
+	if sessionFile != "" {
+		content, err := os.ReadFile(sessionFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session file %s: %s", sessionFile, err)
+		}
+		var session Session
+		if err = yaml.Unmarshal(content, &session); err != nil {
+			return nil, fmt.Errorf("invalid session file %s: %s", sessionFile, err)
+		}
+		targets = append(targets, session.Targets...)
+	}
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		target := Target{Resource: parts[0]}
+		if len(parts) > 1 {
+			target.Expose = parts[1]
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("name of service to exchange is required")
+	}
+
+	resources := map[string]bool{}
+	for i, t := range targets {
+		if t.Resource == "" {
+			return nil, fmt.Errorf("name of service to exchange is required")
+		}
+		if resources[t.Resource] {
+			return nil, fmt.Errorf("'%s' is specified more than once", t.Resource)
+		}
+		resources[t.Resource] = true
+		if t.Expose == "" {
+			// '--expose' option only works for single target
+			if len(targets) > 1 {
+				return nil, fmt.Errorf("ports to expose of '%s' is not specified, use '%s=<ports>' format",
+					t.Resource, t.Resource)
+			}
+			targets[i].Expose = expose
+		}
+	}
+	return targets, nil
+}
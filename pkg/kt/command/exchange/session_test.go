@@ -0,0 +1,37 @@
+package exchange
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := ParseTargets([]string{"svc-a"}, "8080", "")
+	require.NoError(t, err)
+	require.Equal(t, []Target{{Resource: "svc-a", Expose: "8080"}}, targets)
+
+	targets, err = ParseTargets([]string{"svc-a=8080", "deploy/b=9090:80,7001"}, "", "")
+	require.NoError(t, err)
+	require.Equal(t, []Target{{Resource: "svc-a", Expose: "8080"}, {Resource: "deploy/b", Expose: "9090:80,7001"}}, targets)
+
+	_, err = ParseTargets([]string{"svc-a", "svc-b=9090"}, "8080", "")
+	require.Error(t, err)
+	_, err = ParseTargets([]string{"svc-a=8080", "svc-a=9090"}, "", "")
+	require.Error(t, err)
+	_, err = ParseTargets([]string{}, "8080", "")
+	require.Error(t, err)
+
+	sessionFile := filepath.Join(t.TempDir(), "session.yaml")
+	require.NoError(t, os.WriteFile(sessionFile, []byte(`targets:
+- resource: svc-a
+  expose: "8080"
+- resource: deploy/b
+  expose: "9090:80"
+`), 0644))
+	targets, err = ParseTargets([]string{"svc-c=7001"}, "", sessionFile)
+	require.NoError(t, err)
+	require.Equal(t, []Target{{Resource: "svc-a", Expose: "8080"}, {Resource: "deploy/b", Expose: "9090:80"},
+		{Resource: "svc-c", Expose: "7001"}}, targets)
+}
@@ -18,12 +18,19 @@ func NewForwardCommand() *cobra.Command {
 		Use:   "forward",
 		Short: "Redirect local port to a service or any remote address",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
+			if isMultipleServices(args) {
+				if (opt.Get().Forward.Selector != "" || opt.Get().Forward.All) && len(args) > 0 {
+					return fmt.Errorf("service names cannot be specified along with '--selector' or '--all'")
+				}
+				for _, arg := range args {
+					if strings.Contains(arg, ".") {
+						return fmt.Errorf("'%s' is not a service name, only one remote address can be forwarded", arg)
+					}
+				}
+			} else if len(args) == 0 {
 				return fmt.Errorf("a service name or target address must be specified")
 			} else if len(args) == 1 && strings.Contains(args[0], ".") {
 				return fmt.Errorf("a port must be specified because '%s' is not a service name", args[0])
-			} else if len(args) > 2 {
-				return fmt.Errorf("too many target addresses are spcified (%s)", strings.Join(args, ",") )
 			}
 			opt.Get().Global.UseLocalTime = true
 			return general.Prepare()
@@ -31,7 +38,9 @@ func NewForwardCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return Forward(args)
 		},
-		Example: "ktctl forward <service-name|remote-address> [<local-port>:<remote-port>] [command options]",
+		Example: "ktctl forward <service-name|remote-address> [<local-port>:<remote-port>] [command options]\n" +
+			"ktctl forward <service-name> <service-name> ... [command options]\n" +
+			"ktctl forward --selector <label-selector>|--all [command options]",
 	}
 
 	cmd.SetUsageTemplate(general.UsageTemplate(true))
@@ -45,6 +54,40 @@ func Forward(args []string) error {
 		return err
 	}
 
+	if isMultipleServices(args) {
+		plans, err2 := forward.RedirectServices(args)
+		if err2 != nil {
+			return err2
+		}
+		log.Info().Msg("---------------------------------------------------------------")
+		log.Info().Msgf(" Now you can access services via following local ports")
+		for _, plan := range plans {
+			log.Info().Msgf("   %s:%d -> localhost:%d", plan.Service, plan.Port, plan.LocalPort)
+		}
+		log.Info().Msg("---------------------------------------------------------------")
+	} else if err = forwardSingle(args); err != nil {
+		return err
+	}
+
+	// watch background process, clean the workspace and exit if background process occur exception
+	s := <-ch
+	log.Info().Msgf("Terminal Signal is %s", s)
+	return nil
+}
+
+// isMultipleServices whether to forward more than one service, i.e. the second argument is not a port
+func isMultipleServices(args []string) bool {
+	if opt.Get().Forward.Selector != "" || opt.Get().Forward.All {
+		return true
+	}
+	if len(args) < 2 {
+		return false
+	}
+	_, _, err := parsePort(args)
+	return err != nil || len(args) > 2
+}
+
+func forwardSingle(args []string) error {
 	target := args[0]
 	localPort, remotePort, err := parsePort(args)
 	if err != nil {
@@ -72,10 +115,6 @@ func Forward(args []string) error {
 		log.Info().Msgf(" Now you can access%s service '%s' via 'localhost:%d'", portMsg, target, localPort)
 		log.Info().Msg("---------------------------------------------------------------")
 	}
-
-	// watch background process, clean the workspace and exit if background process occur exception
-	s := <-ch
-	log.Info().Msgf("Terminal Signal is %s", s)
 	return nil
 }
 
@@ -1,16 +1,21 @@
 package forward
 
 import (
+	"errors"
 	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
 	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
 	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
 	"github.com/alibaba/kt-connect/pkg/kt/transmission"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+	"strconv"
 )
 
 func RedirectService(serviceName string, localPort, remotePort int) (int, error) {
-	podName, podPort, svcPort, err := getPodNameAndPort(serviceName, remotePort, opt.Get().Global.Namespace)
+	svc, svcPort, err := getServiceAndPort(serviceName, remotePort, opt.Get().Global.Namespace)
 	if err != nil {
 		return 0, err
 	}
@@ -18,11 +23,73 @@ func RedirectService(serviceName string, localPort, remotePort int) (int, error)
 		// local port note provided, use same as remote port
 		localPort = svcPort
 	}
-	gone, err := transmission.SetupPortForwardToLocal(podName, podPort, localPort)
+	for _, p := range svc.Spec.Ports {
+		if int(p.Port) == svcPort && p.Protocol == coreV1.ProtocolUDP {
+			relayPort, err2 := startUdpRelay([]string{udpTarget(svc, svcPort)})
+			if err2 != nil {
+				return 0, err2
+			}
+			return localPort, redirectUdpPort(svc, svcPort, localPort, relayPort)
+		}
+	}
+	return localPort, redirectServicePort(svc, svcPort, localPort)
+}
+
+// redirectServicePort listen local port, and forward each connection to a ready endpoint of service,
+// thus new connections always go to ready pods even during rollout
+func redirectServicePort(svc *coreV1.Service, svcPort, localPort int) error {
+	tracker, portName, err := getServicePortEndpoints(svc, svcPort)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(common.Localhost, strconv.Itoa(localPort)))
+	if err != nil {
+		return fmt.Errorf("failed to listen port %d: %s", localPort, err)
+	}
 	go func() {
-		<-gone
+		for {
+			conn, err2 := listener.Accept()
+			if errors.Is(err2, net.ErrClosed) {
+				return
+			} else if err2 != nil {
+				log.Warn().Err(err2).Msgf("Failed to accept connection on port %d", localPort)
+				continue
+			}
+			go func() {
+				endpoint, err3 := tracker.pick(portName, opt.Get().Forward.LoadBalance)
+				if err3 != nil {
+					log.Warn().Msgf("Service %s port %d not available: %s", svc.Name, svcPort, err3)
+					_ = conn.Close()
+					return
+				}
+				log.Debug().Msgf("Forwarding connection of local:%d to pod %s:%d", localPort, endpoint.Pod, endpoint.Port)
+				if err3 = transmission.ForwardConnToPod(conn, endpoint.Pod, endpoint.Port); err3 != nil {
+					log.Warn().Err(err3).Msgf("Failed to forward connection of local:%d", localPort)
+				}
+			}()
+		}
 	}()
-	return localPort, err
+	cluster.SetupPortForwardHeartBeat(localPort)
+	log.Info().Msgf("Port forward local:%d -> service %s:%d established", localPort, svc.Name, svcPort)
+	return nil
+}
+
+// getServicePortEndpoints get endpoint tracker and port name of service port, fail if it has no ready endpoint
+func getServicePortEndpoints(svc *coreV1.Service, svcPort int) (*serviceEndpoints, string, error) {
+	portName := ""
+	for _, p := range svc.Spec.Ports {
+		if int(p.Port) == svcPort {
+			portName = p.Name
+		}
+	}
+	tracker, err := getServiceEndpoints(svc)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err = tracker.pick(portName, false); err != nil {
+		return nil, "", fmt.Errorf("service %s port %d not available: %s", svc.Name, svcPort, err)
+	}
+	return tracker, portName, nil
 }
 
 func RedirectAddress(remoteAddress string, localPort, remotePort int) error {
@@ -36,59 +103,30 @@ func RedirectAddress(remoteAddress string, localPort, remotePort int) error {
 	return fmt.Errorf("redirecting to an arbitrary address havn't been implemented yet")
 }
 
-func getPodNameAndPort(serviceName string, remotePort int, namespace string) (string, int, int, error) {
+func getServiceAndPort(serviceName string, remotePort int, namespace string) (*coreV1.Service, int, error) {
 	svc, err := cluster.Ins().GetService(serviceName, namespace)
 	if err != nil {
 		if k8sErrors.IsNotFound(err) {
-			return "", 0, 0, fmt.Errorf("service '%s' is not found in namespace %s", serviceName, namespace)
+			return nil, 0, fmt.Errorf("service '%s' is not found in namespace %s", serviceName, namespace)
 		}
-		return "", 0, 0, err
+		return nil, 0, err
 	}
 	if len(svc.Spec.Ports) == 0 {
-		return "", 0, 0, fmt.Errorf("service '%s' has not port available", serviceName)
+		return nil, 0, fmt.Errorf("service '%s' has not port available", serviceName)
 	}
 
 	if remotePort <= 0 {
 		// remote port not provided, try fetch from service
 		if len(svc.Spec.Ports) > 1 {
-			return "", 0, 0, fmt.Errorf("service '%s' has multiple ports, must specify one", serviceName)
+			return nil, 0, fmt.Errorf("service '%s' has multiple ports, must specify one", serviceName)
 		} else {
 			remotePort = int(svc.Spec.Ports[0].Port)
 		}
 	}
-
-	targetPort := intstr.IntOrString{Type: -1}
 	for _, p := range svc.Spec.Ports {
 		if int(p.Port) == remotePort {
-			targetPort = p.TargetPort
-		}
-	}
-	if targetPort.Type == -1 {
-		return "", 0, 0, fmt.Errorf("port %d not available for service %s", remotePort, serviceName)
-	}
-	pods, err := cluster.Ins().GetPodsByLabel(svc.Spec.Selector, opt.Get().Global.Namespace)
-	if err != nil {
-		return "", 0, 0, err
-	}
-	if len(pods.Items) == 0 {
-		return "", 0, 0, fmt.Errorf("no pod available for service %s", serviceName)
-	}
-	podPort := -1
-	if targetPort.Type == intstr.Int {
-		podPort = int(targetPort.IntVal)
-	} else {
-	containerLoop:
-		for _, c := range pods.Items[0].Spec.Containers {
-			for _, p := range c.Ports {
-				if p.Name == targetPort.StrVal {
-					podPort = int(p.ContainerPort)
-					break containerLoop
-				}
-			}
+			return svc, remotePort, nil
 		}
 	}
-	if podPort == -1 {
-		return "", 0, 0, fmt.Errorf("port %d not fit for any pod of service %s", remotePort, serviceName)
-	}
-	return pods.Items[0].Name, podPort, remotePort, nil
+	return nil, 0, fmt.Errorf("port %d not available for service %s", remotePort, serviceName)
 }
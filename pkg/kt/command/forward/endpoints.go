@@ -0,0 +1,143 @@
+package forward
+
+import (
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	discoveryV1 "k8s.io/api/discovery/v1"
+	"sort"
+	"sync"
+)
+
+// podEndpoint a ready pod of service and its port
+type podEndpoint struct {
+	Pod  string
+	Port int
+}
+
+// serviceEndpoints keep track of endpoint slices of a service
+type serviceEndpoints struct {
+	lock    sync.Mutex
+	slices  map[string]*discoveryV1.EndpointSlice
+	current map[string]string
+	counter int
+}
+
+var trackedServices = map[string]*serviceEndpoints{}
+var trackedServicesLock sync.Mutex
+var watchEndpointsOnce sync.Once
+
+// getServiceEndpoints start tracking endpoint slices of service, endpoint slices of all services in namespace
+// are watched only once and dispatched to the tracked services
+func getServiceEndpoints(svc *coreV1.Service) (*serviceEndpoints, error) {
+	trackedServicesLock.Lock()
+	defer trackedServicesLock.Unlock()
+	if tracker, exists := trackedServices[svc.Name]; exists {
+		return tracker, nil
+	}
+	slices, err := cluster.Ins().GetEndpointSlicesByLabel(map[string]string{util.EndpointSliceServiceName: svc.Name}, svc.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	tracker := &serviceEndpoints{slices: map[string]*discoveryV1.EndpointSlice{}, current: map[string]string{}}
+	for i := range slices.Items {
+		tracker.slices[slices.Items[i].Name] = &slices.Items[i]
+	}
+	trackedServices[svc.Name] = tracker
+	watchEndpointsOnce.Do(func() {
+		go cluster.Ins().WatchEndpointSlice(opt.Get().Global.Namespace, onEndpointSliceUpdate, onEndpointSliceDelete,
+			onEndpointSliceUpdate)
+	})
+	return tracker, nil
+}
+
+func onEndpointSliceUpdate(slice *discoveryV1.EndpointSlice) {
+	if tracker := getTracker(slice); tracker != nil {
+		tracker.lock.Lock()
+		tracker.slices[slice.Name] = slice
+		tracker.lock.Unlock()
+	}
+}
+
+func onEndpointSliceDelete(slice *discoveryV1.EndpointSlice) {
+	if tracker := getTracker(slice); tracker != nil {
+		tracker.lock.Lock()
+		delete(tracker.slices, slice.Name)
+		tracker.lock.Unlock()
+	}
+}
+
+func getTracker(slice *discoveryV1.EndpointSlice) *serviceEndpoints {
+	trackedServicesLock.Lock()
+	defer trackedServicesLock.Unlock()
+	return trackedServices[slice.Labels[util.EndpointSliceServiceName]]
+}
+
+// pick choose a ready endpoint for the service port, with load balance the endpoints are chosen in turn,
+// otherwise current endpoint is used as long as it's ready
+func (s *serviceEndpoints) pick(portName string, loadBalance bool) (*podEndpoint, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var slices []*discoveryV1.EndpointSlice
+	for _, slice := range s.slices {
+		slices = append(slices, slice)
+	}
+	endpoints := readyEndpoints(slices, portName)
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no ready endpoint available")
+	}
+	if loadBalance {
+		s.counter++
+		return &endpoints[s.counter%len(endpoints)], nil
+	}
+	for i, ep := range endpoints {
+		if ep.Pod == s.current[portName] {
+			return &endpoints[i], nil
+		}
+	}
+	if s.current[portName] != "" {
+		log.Info().Msgf("Endpoint switched from pod %s to %s", s.current[portName], endpoints[0].Pod)
+	}
+	s.current[portName] = endpoints[0].Pod
+	return &endpoints[0], nil
+}
+
+// readyEndpoints get ready pod endpoints of specified service port, sorted by pod name
+func readyEndpoints(slices []*discoveryV1.EndpointSlice, portName string) []podEndpoint {
+	var endpoints []podEndpoint
+	for _, slice := range slices {
+		port := -1
+		for _, p := range slice.Ports {
+			name := ""
+			if p.Name != nil {
+				name = *p.Name
+			}
+			if name == portName && p.Port != nil {
+				port = int(*p.Port)
+			}
+		}
+		if port < 0 {
+			continue
+		}
+		for _, ep := range slice.Endpoints {
+			// nil ready condition should be interpreted as ready
+			if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				continue
+			}
+			if ep.Conditions.Terminating != nil && *ep.Conditions.Terminating {
+				continue
+			}
+			if ep.TargetRef == nil || ep.TargetRef.Kind != "Pod" {
+				continue
+			}
+			endpoints = append(endpoints, podEndpoint{Pod: ep.TargetRef.Name, Port: port})
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].Pod < endpoints[j].Pod
+	})
+	return endpoints
+}
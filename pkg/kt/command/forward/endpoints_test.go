@@ -0,0 +1,70 @@
+package forward
+
+import (
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	discoveryV1 "k8s.io/api/discovery/v1"
+	"testing"
+)
+
+func endpoint(pod string, ready, terminating *bool) discoveryV1.Endpoint {
+	return discoveryV1.Endpoint{
+		Conditions: discoveryV1.EndpointConditions{Ready: ready, Terminating: terminating},
+		TargetRef:  &coreV1.ObjectReference{Kind: "Pod", Name: pod},
+	}
+}
+
+func Test_readyEndpoints(t *testing.T) {
+	yes, no := true, false
+	httpName, grpcName := "http", "grpc"
+	httpPort, grpcPort := int32(8080), int32(9090)
+	slices := []*discoveryV1.EndpointSlice{
+		{
+			Ports: []discoveryV1.EndpointPort{{Name: &httpName, Port: &httpPort}, {Name: &grpcName, Port: &grpcPort}},
+			Endpoints: []discoveryV1.Endpoint{
+				endpoint("order-c", &yes, nil),
+				endpoint("order-a", nil, nil),
+				endpoint("order-b", &no, nil),
+				endpoint("order-d", &yes, &yes),
+				{Conditions: discoveryV1.EndpointConditions{Ready: &yes}},
+			},
+		},
+	}
+	require.Equal(t, []podEndpoint{{Pod: "order-a", Port: 8080}, {Pod: "order-c", Port: 8080}}, readyEndpoints(slices, "http"))
+	require.Equal(t, []podEndpoint{{Pod: "order-a", Port: 9090}, {Pod: "order-c", Port: 9090}}, readyEndpoints(slices, "grpc"))
+	require.Empty(t, readyEndpoints(slices, ""))
+}
+
+func Test_pick(t *testing.T) {
+	yes, no := true, false
+	port := int32(8080)
+	slice := &discoveryV1.EndpointSlice{
+		Ports:     []discoveryV1.EndpointPort{{Port: &port}},
+		Endpoints: []discoveryV1.Endpoint{endpoint("order-b", &yes, nil), endpoint("order-a", &yes, nil)},
+	}
+	tracker := &serviceEndpoints{slices: map[string]*discoveryV1.EndpointSlice{"s": slice}, current: map[string]string{}}
+
+	ep, err := tracker.pick("", false)
+	require.Nil(t, err)
+	require.Equal(t, "order-a", ep.Pod)
+	// sticky to current pod while it's ready
+	slice.Endpoints = append(slice.Endpoints, endpoint("order-0", &yes, nil))
+	ep, _ = tracker.pick("", false)
+	require.Equal(t, "order-a", ep.Pod)
+	// switch when current pod is not ready
+	slice.Endpoints[1] = endpoint("order-a", &no, nil)
+	ep, _ = tracker.pick("", false)
+	require.Equal(t, "order-0", ep.Pod)
+
+	// distribute across ready pods
+	pods := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		ep, _ = tracker.pick("", true)
+		pods[ep.Pod] = true
+	}
+	require.Equal(t, map[string]bool{"order-0": true, "order-b": true}, pods)
+
+	slice.Endpoints = nil
+	_, err = tracker.pick("", false)
+	require.NotNil(t, err)
+}
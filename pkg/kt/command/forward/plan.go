@@ -0,0 +1,167 @@
+package forward
+
+import (
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var invalidEnvChars = regexp.MustCompile("[^A-Z0-9]+")
+
+// PortPlan local port allocated to a service port
+type PortPlan struct {
+	Service   string
+	PortName  string
+	Protocol  coreV1.Protocol
+	Port      int
+	LocalPort int
+}
+
+// RedirectServices forward all tcp and udp ports of specified services, or services selected by label,
+// or all services in namespace
+func RedirectServices(serviceNames []string) ([]PortPlan, error) {
+	services, err := getTargetServices(serviceNames, opt.Get().Forward.Selector, opt.Get().Global.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	plans := buildPortPlan(services, opt.Get().Forward.BasePort)
+	svcDict := map[string]*coreV1.Service{}
+	for i := range services {
+		svcDict[services[i].Name] = &services[i]
+	}
+	// all udp ports share one relay, which only relays to these ports,
+	// tcp ports are checked in advance, so that no port is listened when any service is unavailable
+	var udpTargets []string
+	for _, plan := range plans {
+		if plan.Protocol == coreV1.ProtocolUDP {
+			udpTargets = append(udpTargets, udpTarget(svcDict[plan.Service], plan.Port))
+		} else if _, _, err = getServicePortEndpoints(svcDict[plan.Service], plan.Port); err != nil {
+			return nil, err
+		}
+	}
+	relayPort := 0
+	if len(udpTargets) > 0 {
+		if relayPort, err = startUdpRelay(udpTargets); err != nil {
+			return nil, fmt.Errorf("failed to start udp relay: %s", err)
+		}
+	}
+	for _, plan := range plans {
+		if plan.Protocol == coreV1.ProtocolUDP {
+			err = redirectUdpPort(svcDict[plan.Service], plan.Port, plan.LocalPort, relayPort)
+		} else {
+			err = redirectServicePort(svcDict[plan.Service], plan.Port, plan.LocalPort)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to forward port %d of service %s: %s", plan.Port, plan.Service, err)
+		}
+	}
+	if opt.Get().Forward.PlanFile != "" {
+		if err = os.WriteFile(opt.Get().Forward.PlanFile, []byte(renderPlan(plans)), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write port plan file %s: %s", opt.Get().Forward.PlanFile, err)
+		}
+		log.Info().Msgf("Port plan saved to %s", opt.Get().Forward.PlanFile)
+	}
+	return plans, nil
+}
+
+// getTargetServices get services by name, by label selector, or all services in namespace when neither specified,
+// services without selector are skipped since there is no pod to forward to
+func getTargetServices(serviceNames []string, selector, namespace string) ([]coreV1.Service, error) {
+	var services []coreV1.Service
+	if len(serviceNames) > 0 {
+		for _, name := range serviceNames {
+			svc, err := cluster.Ins().GetService(name, namespace)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get service '%s': %s", name, err)
+			}
+			services = append(services, *svc)
+		}
+		return services, nil
+	}
+	var labels map[string]string
+	if selector != "" {
+		labels = util.String2Map(selector)
+	}
+	svcList, err := cluster.Ins().GetServicesByLabel(labels, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range svcList.Items {
+		if len(svc.Spec.Selector) == 0 || len(svc.Spec.Ports) == 0 {
+			log.Debug().Msgf("Skip service %s without selector or port", svc.Name)
+			continue
+		}
+		services = append(services, svc)
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no service available to forward in namespace %s", namespace)
+	}
+	return services, nil
+}
+
+// buildPortPlan allocate local ports in order of service name and port number, starting from base port,
+// thus the same services always get the same local ports
+func buildPortPlan(services []coreV1.Service, basePort int) []PortPlan {
+	var plans []PortPlan
+	for _, svc := range services {
+		for _, p := range svc.Spec.Ports {
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = coreV1.ProtocolTCP
+			}
+			if protocol != coreV1.ProtocolTCP && protocol != coreV1.ProtocolUDP {
+				continue
+			}
+			plans = append(plans, PortPlan{Service: svc.Name, PortName: p.Name, Protocol: protocol, Port: int(p.Port)})
+		}
+	}
+	sort.SliceStable(plans, func(i, j int) bool {
+		if plans[i].Service != plans[j].Service {
+			return plans[i].Service < plans[j].Service
+		}
+		if plans[i].Port != plans[j].Port {
+			return plans[i].Port < plans[j].Port
+		}
+		return plans[i].Protocol < plans[j].Protocol
+	})
+	for i := range plans {
+		plans[i].LocalPort = basePort + i
+	}
+	return plans
+}
+
+// renderPlan generate port plan in env file format, e.g. ORDER_HOST=127.0.0.1 and ORDER_PORT=30000,
+// for service with multiple ports, ORDER_PORT is the first port, and ORDER_PORT_<name or port> for each port
+func renderPlan(plans []PortPlan) string {
+	var lines []string
+	lastService := ""
+	for _, plan := range plans {
+		prefix := toEnvName(plan.Service)
+		if plan.Service != lastService {
+			lines = append(lines, fmt.Sprintf("%s_HOST=127.0.0.1", prefix))
+			lines = append(lines, fmt.Sprintf("%s_PORT=%d", prefix, plan.LocalPort))
+			lastService = plan.Service
+		}
+		suffix := plan.PortName
+		if suffix == "" {
+			suffix = strconv.Itoa(plan.Port)
+			if plan.Protocol == coreV1.ProtocolUDP {
+				suffix += "_udp"
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s_PORT_%s=%d", prefix, toEnvName(suffix), plan.LocalPort))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func toEnvName(name string) string {
+	return strings.Trim(invalidEnvChars.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
@@ -0,0 +1,44 @@
+package forward
+
+import (
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func Test_buildPortPlan(t *testing.T) {
+	services := []coreV1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "order"},
+			Spec: coreV1.ServiceSpec{Ports: []coreV1.ServicePort{
+				{Name: "http", Port: 80},
+				{Name: "sctp", Port: 3868, Protocol: coreV1.ProtocolSCTP},
+				{Name: "grpc", Port: 9090, Protocol: coreV1.ProtocolTCP},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cart-api"},
+			Spec: coreV1.ServiceSpec{Ports: []coreV1.ServicePort{
+				{Port: 8125, Protocol: coreV1.ProtocolUDP},
+				{Port: 8080},
+			}},
+		},
+	}
+	plans := buildPortPlan(services, 30000)
+	require.Equal(t, []PortPlan{
+		{Service: "cart-api", Protocol: coreV1.ProtocolTCP, Port: 8080, LocalPort: 30000},
+		{Service: "cart-api", Protocol: coreV1.ProtocolUDP, Port: 8125, LocalPort: 30001},
+		{Service: "order", PortName: "http", Protocol: coreV1.ProtocolTCP, Port: 80, LocalPort: 30002},
+		{Service: "order", PortName: "grpc", Protocol: coreV1.ProtocolTCP, Port: 9090, LocalPort: 30003},
+	}, plans)
+
+	require.Equal(t, "CART_API_HOST=127.0.0.1\n"+
+		"CART_API_PORT=30000\n"+
+		"CART_API_PORT_8080=30000\n"+
+		"CART_API_PORT_8125_UDP=30001\n"+
+		"ORDER_HOST=127.0.0.1\n"+
+		"ORDER_PORT=30002\n"+
+		"ORDER_PORT_HTTP=30002\n"+
+		"ORDER_PORT_GRPC=30003\n", renderPlan(plans))
+}
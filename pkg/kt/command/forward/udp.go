@@ -0,0 +1,61 @@
+package forward
+
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/transmission"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/alibaba/kt-connect/pkg/shadow/udprelay"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// startUdpRelay create a shadow pod with udp relay which only relays to specified targets,
+// and forward the relay port to local
+func startUdpRelay(targets []string) (int, error) {
+	shadowPodName := fmt.Sprintf("kt-forward-shadow-%s", strings.ToLower(util.RandomString(5)))
+	labels := map[string]string{
+		util.KtRole: util.RoleForwardShadow,
+	}
+	envs := map[string]string{
+		common.EnvVarUdpRelay: strings.Join(targets, ","),
+	}
+	if opt.Get().Global.Debug {
+		envs[common.EnvVarLogLevel] = "debug"
+	}
+	_, podName, _, err := cluster.Ins().GetOrCreateShadow(shadowPodName, labels, make(map[string]string), envs,
+		nil, "", map[int]string{}, nil)
+	if err != nil {
+		return 0, err
+	}
+	localPort := util.GetRandomTcpPort()
+	if _, err = transmission.SetupPortForwardToLocal(podName, common.StandardUdpRelayPort, localPort); err != nil {
+		return 0, err
+	}
+	return localPort, nil
+}
+
+// udpTarget address of service port in udp relay
+func udpTarget(svc *coreV1.Service, svcPort int) string {
+	return fmt.Sprintf("%s.%s:%d", svc.Name, svc.Namespace, svcPort)
+}
+
+// redirectUdpPort listen local udp port, datagrams from each local client are relayed to the service port
+// through its own tcp stream to the udp relay in shadow pod
+func redirectUdpPort(svc *coreV1.Service, svcPort, localPort, relayPort int) error {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(common.Localhost), Port: localPort})
+	if err != nil {
+		return fmt.Errorf("failed to listen udp port %d: %s", localPort, err)
+	}
+	target := udpTarget(svc, svcPort)
+	log.Info().Msgf("Udp relay local:%d -> %s established", localPort, target)
+	go udprelay.Relay(conn, target, func() (net.Conn, error) {
+		return net.Dial("tcp", net.JoinHostPort(common.Localhost, strconv.Itoa(relayPort)))
+	})
+	return nil
+}
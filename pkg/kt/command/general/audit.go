@@ -0,0 +1,131 @@
+package general
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditRecord a change made to cluster resource by ktctl command
+type AuditRecord struct {
+	Time      string `json:"time"`
+	User      string `json:"user"`
+	Command   string `json:"command"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Action    string `json:"action"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+var workloadKinds = map[string]string{
+	util.WorkloadDeployment:  "Deployment",
+	util.WorkloadStatefulSet: "StatefulSet",
+	util.WorkloadReplicaSet:  "ReplicaSet",
+	util.WorkloadDaemonSet:   "DaemonSet",
+	util.WorkloadRollout:     "Rollout",
+}
+
+var apiVersions = map[string]string{
+	"Pod":           "v1",
+	"Service":       "v1",
+	"ConfigMap":     "v1",
+	"Deployment":    "apps/v1",
+	"StatefulSet":   "apps/v1",
+	"ReplicaSet":    "apps/v1",
+	"DaemonSet":     "apps/v1",
+	"Rollout":       "argoproj.io/v1alpha1",
+	"EndpointSlice": "discovery.k8s.io/v1",
+}
+
+// ObjectRef reference of an object at hand, kind can also be a workload kind like 'deployment'
+func ObjectRef(kind string, object metav1.Object) coreV1.ObjectReference {
+	if k, exists := workloadKinds[kind]; exists {
+		kind = k
+	}
+	return coreV1.ObjectReference{
+		Kind:       kind,
+		APIVersion: apiVersions[kind],
+		Name:       object.GetName(),
+		Namespace:  object.GetNamespace(),
+		UID:        object.GetUID(),
+	}
+}
+
+// GetObjectRef fetch the object to get its reference, should be called before the object deleted,
+// uid is left empty if the object cannot be fetched
+func GetObjectRef(kind, name, namespace string) coreV1.ObjectReference {
+	var object metav1.Object
+	var err error
+	switch kind {
+	case "Pod":
+		object, err = cluster.Ins().GetPod(name, namespace)
+	case "Service":
+		object, err = cluster.Ins().GetService(name, namespace)
+	case "ConfigMap":
+		object, err = cluster.Ins().GetConfigMap(name, namespace)
+	case "Deployment":
+		object, err = cluster.Ins().GetDeployment(name, namespace)
+	case "EndpointSlice":
+		object, err = cluster.Ins().GetEndpointSlice(name, namespace)
+	default:
+		if workload, err2 := cluster.Ins().GetWorkload(kind, name, namespace); err2 == nil {
+			object = &metav1.ObjectMeta{Name: name, Namespace: namespace, UID: workload.UID}
+		} else {
+			err = err2
+		}
+	}
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to fetch %s %s", kind, name)
+		object = &metav1.ObjectMeta{Name: name, Namespace: namespace}
+	}
+	return ObjectRef(kind, object)
+}
+
+// RecordChange write the change to local audit log, and record it as an event of the affected object
+func RecordChange(command string, object coreV1.ObjectReference, action, detail string) {
+	record := AuditRecord{
+		Time:      time.Now().Format(time.RFC3339),
+		User:      util.GetLocalUserName(),
+		Command:   command,
+		Namespace: object.Namespace,
+		Kind:      object.Kind,
+		Name:      object.Name,
+		Action:    action,
+		Detail:    detail,
+	}
+	if err := appendAuditRecord(util.KtAuditLogFile, record); err != nil {
+		log.Debug().Err(err).Msgf("Failed to write audit log")
+	}
+	message := fmt.Sprintf("%s by %s via ktctl %s", action, record.User, command)
+	if detail != "" {
+		message = fmt.Sprintf("%s: %s", message, detail)
+	}
+	if err := cluster.Ins().CreateEvent(object, "Kt"+action, message); err != nil {
+		log.Debug().Err(err).Msgf("Failed to record event of %s %s", object.Kind, object.Name)
+	}
+}
+
+func appendAuditRecord(file string, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,35 @@
+package general
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_appendAuditRecord(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "kt", "audit.log")
+	require.Nil(t, appendAuditRecord(file, AuditRecord{Command: "recover", Kind: "Service", Name: "order", Action: "UpdateService"}))
+	require.Nil(t, appendAuditRecord(file, AuditRecord{Command: "clean", Kind: "Pod", Name: "order-kt-exchange-abcde", Action: "DeletePod"}))
+
+	content, err := os.ReadFile(file)
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	var record AuditRecord
+	require.Nil(t, json.Unmarshal([]byte(lines[1]), &record))
+	require.Equal(t, "clean", record.Command)
+	require.Equal(t, "DeletePod", record.Action)
+	require.NotContains(t, lines[1], "detail")
+}
+
+func TestObjectRef(t *testing.T) {
+	ref := ObjectRef("deployment", &metav1.ObjectMeta{Name: "order", Namespace: "default", UID: "1234"})
+	require.Equal(t, coreV1.ObjectReference{Kind: "Deployment", APIVersion: "apps/v1", Name: "order",
+		Namespace: "default", UID: "1234"}, ref)
+	require.Equal(t, "v1", ObjectRef("Service", &metav1.ObjectMeta{Name: "order"}).APIVersion)
+}
@@ -0,0 +1,151 @@
+package general
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/rs/zerolog/log"
+	"io"
+	coreV1 "k8s.io/api/core/v1"
+	netV1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sLabels "k8s.io/apimachinery/pkg/labels"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GetNetworkPolicyLabels get labels of pod template which are referred by network policies in namespace,
+// shadow pod with these labels would be treated the same as original pod by network policies
+func GetNetworkPolicyLabels(template *coreV1.PodTemplateSpec, namespace string) map[string]string {
+	policies, err := cluster.Ins().GetAllNetworkPolicyInNamespace(namespace)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to list network policies")
+		return map[string]string{}
+	}
+	labels := networkPolicyLabels(policies.Items, template.Labels)
+	log.Debug().Msgf("Labels referred by network policies: %v", labels)
+	return labels
+}
+
+// networkPolicyLabels only labels used by selectors which actually select the pod are picked
+func networkPolicyLabels(policies []netV1.NetworkPolicy, podLabels map[string]string) map[string]string {
+	keys := map[string]bool{}
+	for _, policy := range policies {
+		addSelectorKeys(keys, &policy.Spec.PodSelector, podLabels)
+		for _, rule := range policy.Spec.Ingress {
+			for _, peer := range rule.From {
+				addSelectorKeys(keys, peer.PodSelector, podLabels)
+			}
+		}
+		for _, rule := range policy.Spec.Egress {
+			for _, peer := range rule.To {
+				addSelectorKeys(keys, peer.PodSelector, podLabels)
+			}
+		}
+	}
+	labels := map[string]string{}
+	for k, v := range podLabels {
+		if keys[k] {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+func syncMountPath(podName, containerName, namespace, mountPath, localDir string) error {
+	parent, base := filepath.Dir(mountPath), filepath.Base(mountPath)
+	stdout, _, err := cluster.Ins().ExecInPod(containerName, podName, namespace, "sh", "-c",
+		fmt.Sprintf("tar chf - -C '%s' '%s' | base64 -w0", parent, base))
+	if err != nil {
+		return err
+	}
+	content, err := base64.StdEncoding.DecodeString(stdout)
+	if err != nil {
+		return err
+	}
+	return untar(bytes.NewReader(content), filepath.Join(localDir, parent))
+}
+
+func untar(reader io.Reader, targetDir string) error {
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if isAtomicWriterPath(header.Name) {
+			// config map and secret volumes contain timestamped directories and '..data' link
+			continue
+		}
+		target := filepath.Join(targetDir, header.Name)
+		if !strings.HasPrefix(target, filepath.Clean(targetDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path %s", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			f, err2 := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err2 != nil {
+				return err2
+			}
+			_, err = io.Copy(f, tr)
+			_ = f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func isAtomicWriterPath(name string) bool {
+	for _, segment := range strings.Split(filepath.ToSlash(name), "/") {
+		if strings.HasPrefix(segment, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+func toEnvFile(envs map[string]string) string {
+	lines := make([]string, 0)
+	for k, v := range envs {
+		lines = append(lines, fmt.Sprintf("%s=%s", k, quoteEnvValue(v)))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func quoteEnvValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\"'\\$#`") {
+		return value
+	}
+	replacer := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "`", "\\`", "\n", "\\n")
+	return "\"" + replacer.Replace(value) + "\""
+}
+
+func addSelectorKeys(keys map[string]bool, selector *metav1.LabelSelector, podLabels map[string]string) {
+	if selector == nil {
+		return
+	}
+	if matcher, err := metav1.LabelSelectorAsSelector(selector); err != nil || !matcher.Matches(k8sLabels.Set(podLabels)) {
+		return
+	}
+	for k := range selector.MatchLabels {
+		keys[k] = true
+	}
+	for _, expression := range selector.MatchExpressions {
+		keys[expression.Key] = true
+	}
+}
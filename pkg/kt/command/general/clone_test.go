@@ -0,0 +1,37 @@
+package general
+
+import (
+	"github.com/stretchr/testify/require"
+	netV1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func Test_toEnvFile(t *testing.T) {
+	content := toEnvFile(map[string]string{
+		"DB_HOST":  "mysql",
+		"PASSWORD": "a b$c",
+		"EMPTY":    "",
+	})
+	require.Equal(t, "DB_HOST=mysql\nEMPTY=\"\"\nPASSWORD=\"a b\\$c\"\n", content)
+}
+
+func Test_networkPolicyLabels(t *testing.T) {
+	podLabels := map[string]string{"app": "order", "tier": "backend", "version": "v1"}
+	policies := []netV1.NetworkPolicy{
+		{Spec: netV1.NetworkPolicySpec{
+			// selects other pods, its keys should not be copied
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "payment"}},
+			Ingress: []netV1.NetworkPolicyIngressRule{{From: []netV1.NetworkPolicyPeer{{
+				PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}},
+			}}}},
+		}},
+		{Spec: netV1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "version", Operator: metav1.LabelSelectorOpIn, Values: []string{"v1", "v2"}},
+			}},
+		}},
+	}
+	require.Equal(t, map[string]string{"tier": "backend", "version": "v1"}, networkPolicyLabels(policies, podLabels))
+	require.Empty(t, networkPolicyLabels(nil, podLabels))
+}
@@ -0,0 +1,122 @@
+package general
+
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	discoveryV1 "k8s.io/api/discovery/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// UpdateServiceEndpoints let service route to shadow pod via a dedicated endpoint slice without changing its spec,
+// endpoint slices maintained by kubernetes are drained until the service recovered
+func UpdateServiceEndpoints(svcName, namespace, sliceName string, shadowLabels map[string]string, targetPorts map[int]string) error {
+	svc, err := cluster.Ins().GetService(svcName, namespace)
+	if err != nil {
+		return err
+	}
+	if svc.Annotations != nil && svc.Annotations[util.KtEndpointSlice] != "" {
+		return fmt.Errorf("service '%s' is already routing to endpoint slice %s", svcName, svc.Annotations[util.KtEndpointSlice])
+	}
+
+	pods, err := cluster.Ins().GetPodsByLabel(shadowLabels, namespace)
+	if err != nil {
+		return err
+	}
+	var shadowPod *coreV1.Pod
+	for i, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == coreV1.PodRunning && pod.Status.PodIP != "" {
+			shadowPod = &pods.Items[i]
+			break
+		}
+	}
+	if shadowPod == nil {
+		return fmt.Errorf("no running shadow pod found with labels %v", shadowLabels)
+	}
+
+	if _, err = cluster.Ins().CreateEndpointSlice(sliceName, svc, shadowPod, targetPorts); err != nil {
+		return err
+	}
+	svc.Annotations = util.MapPut(svc.Annotations, util.KtEndpointSlice, sliceName)
+	if _, err = cluster.Ins().UpdateService(svc); err != nil {
+		_ = cluster.Ins().RemoveEndpointSlice(sliceName, namespace)
+		return err
+	}
+
+	drainControllerSlices(svcName, namespace)
+	// endpoint slice controller reverts the drained endpoints whenever it reconciles, drain them again
+	go cluster.Ins().WatchEndpointSlice(namespace, func(slice *discoveryV1.EndpointSlice) {
+		redrainControllerSlice(slice, svcName, sliceName)
+	}, nil, func(slice *discoveryV1.EndpointSlice) {
+		redrainControllerSlice(slice, svcName, sliceName)
+	})
+	return nil
+}
+
+func recoverServiceEndpoints(svc *coreV1.Service, sliceName string) error {
+	if err := cluster.Ins().RemoveEndpointSlice(sliceName, svc.Namespace); err != nil && !k8sErrors.IsNotFound(err) {
+		return fmt.Errorf("failed to remove endpoint slice %s: %s", sliceName, err)
+	}
+	// updating service makes kubernetes re-sync the drained endpoint slices
+	delete(svc.Annotations, util.KtEndpointSlice)
+	if _, err := cluster.Ins().UpdateService(svc); err != nil {
+		return fmt.Errorf("failed to recover endpoints of original service %s: %s", svc.Name, err)
+	}
+	return nil
+}
+
+func drainControllerSlices(svcName, namespace string) {
+	slices, err := cluster.Ins().GetEndpointSlicesByLabel(map[string]string{
+		util.EndpointSliceServiceName: svcName,
+		util.EndpointSliceManagedBy:   util.EndpointSliceController,
+	}, namespace)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to fetch endpoint slices of service %s", svcName)
+		return
+	}
+	for i := range slices.Items {
+		drainEndpointSlice(&slices.Items[i])
+	}
+}
+
+func redrainControllerSlice(slice *discoveryV1.EndpointSlice, svcName, sliceName string) {
+	if slice.Labels[util.EndpointSliceServiceName] != svcName ||
+		slice.Labels[util.EndpointSliceManagedBy] != util.EndpointSliceController || !hasReadyEndpoint(slice) {
+		return
+	}
+	// do not touch endpoint slices after service recovered
+	if svc, err := cluster.Ins().GetService(svcName, slice.Namespace); err != nil ||
+		svc.Annotations == nil || svc.Annotations[util.KtEndpointSlice] != sliceName {
+		return
+	}
+	log.Debug().Msgf("Endpoint slice %s of service %s restored, draining again", slice.Name, svcName)
+	drainEndpointSlice(slice)
+}
+
+func drainEndpointSlice(slice *discoveryV1.EndpointSlice) {
+	if !hasReadyEndpoint(slice) {
+		return
+	}
+	for i := range slice.Endpoints {
+		notReady := false
+		slice.Endpoints[i].Conditions.Ready = &notReady
+		slice.Endpoints[i].Conditions.Serving = &notReady
+	}
+	if _, err := cluster.Ins().UpdateEndpointSlice(slice); err != nil {
+		log.Warn().Err(err).Msgf("Failed to drain endpoint slice %s", slice.Name)
+	} else {
+		log.Info().Msgf("Endpoint slice %s drained", slice.Name)
+	}
+}
+
+func hasReadyEndpoint(slice *discoveryV1.EndpointSlice) bool {
+	for _, ep := range slice.Endpoints {
+		if ep.Conditions.Ready == nil || *ep.Conditions.Ready ||
+			(ep.Conditions.Serving != nil && *ep.Conditions.Serving) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package general
+
+import (
+	"github.com/stretchr/testify/require"
+	discoveryV1 "k8s.io/api/discovery/v1"
+	"testing"
+)
+
+func Test_hasReadyEndpoint(t *testing.T) {
+	ready, notReady := true, false
+	toSlice := func(conditions ...discoveryV1.EndpointConditions) *discoveryV1.EndpointSlice {
+		slice := &discoveryV1.EndpointSlice{}
+		for _, c := range conditions {
+			slice.Endpoints = append(slice.Endpoints, discoveryV1.Endpoint{Conditions: c})
+		}
+		return slice
+	}
+	require.False(t, hasReadyEndpoint(toSlice()))
+	require.False(t, hasReadyEndpoint(toSlice(discoveryV1.EndpointConditions{Ready: &notReady, Serving: &notReady})))
+	// nil ready condition should be treated as ready
+	require.True(t, hasReadyEndpoint(toSlice(discoveryV1.EndpointConditions{})))
+	require.True(t, hasReadyEndpoint(toSlice(discoveryV1.EndpointConditions{Ready: &notReady, Serving: &notReady},
+		discoveryV1.EndpointConditions{Ready: &ready})))
+	// terminating endpoint still serving would receive traffic
+	require.True(t, hasReadyEndpoint(toSlice(discoveryV1.EndpointConditions{Ready: &notReady, Serving: &ready})))
+}
@@ -0,0 +1,64 @@
+package general
+
+import (
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	"os"
+	"time"
+)
+
+const (
+	healthCheckInterval    = 3 * time.Second
+	healthCheckMaxFailures = 3
+)
+
+// WaitLocalReady block until local application accept connections or health path returns 2xx,
+// so that requests are not redirected to local before it can serve them
+func WaitLocalReady(exposePorts string) error {
+	if opt.Get().Exchange.SkipPortChecking {
+		return nil
+	}
+	timeout := time.Duration(opt.Get().Exchange.CutoverTimeout) * time.Second
+	deadline := time.Now().Add(timeout)
+	for i := 0; ; i++ {
+		err := util.CheckLocalHealth(exposePorts, opt.Get().Exchange.HealthCheck)
+		if err == nil {
+			log.Info().Msgf("Local application is ready")
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("local application not ready in %d seconds, %s", opt.Get().Exchange.CutoverTimeout, err)
+		}
+		if i == 0 {
+			log.Info().Msgf("Waiting for local application ready (%s) ...", err)
+		} else {
+			log.Debug().Msgf("Local application not ready yet: %s", err)
+		}
+		time.Sleep(1 * time.Second)
+	}
+}
+
+// MonitorLocalHealth keep checking local application, terminate the exchange when it stop working,
+// thus original pods will be recovered to serve the requests
+func MonitorLocalHealth(exposePorts string, ch chan os.Signal) {
+	if opt.Get().Exchange.SkipPortChecking {
+		return
+	}
+	failures := 0
+	for {
+		time.Sleep(healthCheckInterval)
+		if err := util.CheckLocalHealth(exposePorts, opt.Get().Exchange.HealthCheck); err != nil {
+			failures++
+			log.Warn().Msgf("Local application unhealthy (%d/%d): %s", failures, healthCheckMaxFailures, err)
+			if failures >= healthCheckMaxFailures {
+				log.Error().Msgf("Local application stopped working, falling back to original pods")
+				ch <- os.Interrupt
+				return
+			}
+		} else {
+			failures = 0
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package general
+
+import (
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// containerEngines local container engines supported, in order of preference
+var containerEngines = []string{"docker", "podman"}
+
+// RunImageLocally start image in local container with env and volumes of target workload,
+// the container shares host network, thus it can access cluster via connect and be reached via local ports.
+// Ports to expose are returned, which default to container ports of target workload
+func RunImageLocally(resourceName, image, exposePorts, localDirOption string, syncEmptyDir bool) (string, error) {
+	engine, err := getContainerEngine()
+	if err != nil {
+		return "", err
+	}
+	if err = checkHostNetwork(engine); err != nil {
+		return "", err
+	}
+	if util.GetDaemonRunning(util.ComponentConnect) < 0 {
+		return "", fmt.Errorf("'ktctl connect' is not running, local container would not be able to access cluster")
+	}
+	workload, err := GetWorkloadByResourceName(resourceName, opt.Get().Global.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if len(workload.Template.Spec.Containers) == 0 {
+		return "", fmt.Errorf("no container found in %s '%s'", workload.Kind, workload.Name)
+	}
+	container := workload.Template.Spec.Containers[0]
+	if exposePorts == "" {
+		exposePorts = getContainerPorts(container)
+		if exposePorts == "" {
+			return "", fmt.Errorf("no container port found in %s '%s', please specify '--expose'", workload.Kind, workload.Name)
+		}
+	}
+	localDir, err := prepareLocalDir(localDirOption)
+	if err != nil {
+		return "", err
+	}
+	mountPaths := syncWorkloadMounts(workload, localDir, syncEmptyDir)
+	envs := getContainerEnvs(container, workload)
+
+	name := util.KtExchangeContainer + "-" + strings.ToLower(util.RandomString(5))
+	cmd := exec.Command(engine, toRunArgs(name, image, envs, mountPaths, localDir)...)
+	// pass env values via process environment, avoid exposing secrets in command line
+	cmd.Env = os.Environ()
+	for k, v := range envs {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	log.Info().Msgf("Starting image %s locally with %s", image, engine)
+	if _, stderr, err2 := util.RunAndWait(cmd); err2 != nil {
+		return "", fmt.Errorf("failed to start image %s: %s", image, strings.TrimSpace(stderr))
+	}
+	// record context inorder to remove after command exit
+	opt.Store.LocalContainer = name
+	log.Info().Msgf("Local container %s started, use '%s logs -f %s' to view its output", name, engine, name)
+	return exposePorts, nil
+}
+
+// RemoveLocalContainer stop and remove container started by RunImageLocally
+func RemoveLocalContainer(name string) {
+	engine, err := getContainerEngine()
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to remove local container %s", name)
+		return
+	}
+	if _, stderr, err2 := util.RunAndWait(exec.Command(engine, "rm", "-f", name)); err2 != nil {
+		log.Warn().Msgf("Failed to remove local container %s: %s", name, strings.TrimSpace(stderr))
+	} else {
+		log.Info().Msgf("Local container %s removed", name)
+	}
+}
+
+func getContainerEngine() (string, error) {
+	for _, engine := range containerEngines {
+		if _, err := exec.LookPath(engine); err == nil {
+			return engine, nil
+		}
+	}
+	return "", fmt.Errorf("neither %s is found, cannot run image locally", strings.Join(containerEngines, " nor "))
+}
+
+// checkHostNetwork container engine running inside a virtual machine (e.g. Docker Desktop or podman machine)
+// shares network of that virtual machine instead of local host, thus connect and local ports won't work
+func checkHostNetwork(engine string) error {
+	if !util.IsLinux() {
+		return fmt.Errorf("host network of %s on %s is inside a virtual machine, '--runImage' only works on linux",
+			engine, runtime.GOOS)
+	}
+	info, stderr, err := util.RunAndWait(exec.Command(engine, "info", "--format", engineInfoFormat(engine)))
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %s", engine, strings.TrimSpace(stderr))
+	}
+	if isVmEngine(engine, info) {
+		return fmt.Errorf("%s is running inside a virtual machine, '--runImage' requires a native container engine", engine)
+	}
+	return nil
+}
+
+func engineInfoFormat(engine string) string {
+	if engine == "podman" {
+		return "{{.Host.ServiceIsRemote}}"
+	}
+	return "{{.OperatingSystem}}"
+}
+
+func isVmEngine(engine, info string) bool {
+	info = strings.TrimSpace(info)
+	if engine == "podman" {
+		return info == "true"
+	}
+	return strings.Contains(info, "Docker Desktop")
+}
+
+func getContainerPorts(container coreV1.Container) string {
+	ports := make([]string, 0)
+	for _, p := range container.Ports {
+		if p.Protocol == "" || p.Protocol == coreV1.ProtocolTCP {
+			ports = append(ports, fmt.Sprintf("%d", p.ContainerPort))
+		}
+	}
+	return strings.Join(ports, ",")
+}
+
+func toRunArgs(name, image string, envs map[string]string, mountPaths []string, localDir string) []string {
+	args := []string{"run", "-d", "--rm", "--name", name, "--network", "host"}
+	keys := make([]string, 0, len(envs))
+	for k := range envs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-e", k)
+	}
+	for _, path := range mountPaths {
+		args = append(args, "-v", fmt.Sprintf("%s:%s", filepath.Join(localDir, path), path))
+	}
+	return append(args, image)
+}
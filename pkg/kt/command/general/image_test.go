@@ -0,0 +1,32 @@
+package general
+
+import (
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func Test_toRunArgs(t *testing.T) {
+	args := toRunArgs("kt-exchange-container-abcde", "demo:latest",
+		map[string]string{"TOKEN": "secret", "DB_HOST": "mysql"}, []string{"/etc/config"}, "/tmp/kt")
+	require.Equal(t, []string{"run", "-d", "--rm", "--name", "kt-exchange-container-abcde", "--network", "host",
+		"-e", "DB_HOST", "-e", "TOKEN", "-v", "/tmp/kt/etc/config:/etc/config", "demo:latest"}, args)
+	require.NotContains(t, args, "secret")
+}
+
+func Test_getContainerPorts(t *testing.T) {
+	container := coreV1.Container{Ports: []coreV1.ContainerPort{
+		{ContainerPort: 8080},
+		{ContainerPort: 53, Protocol: coreV1.ProtocolUDP},
+		{ContainerPort: 9090, Protocol: coreV1.ProtocolTCP},
+	}}
+	require.Equal(t, "8080,9090", getContainerPorts(container))
+	require.Equal(t, "", getContainerPorts(coreV1.Container{}))
+}
+
+func Test_isVmEngine(t *testing.T) {
+	require.True(t, isVmEngine("docker", "Docker Desktop\n"))
+	require.False(t, isVmEngine("docker", "Ubuntu 22.04.1 LTS\n"))
+	require.True(t, isVmEngine("podman", "true\n"))
+	require.False(t, isVmEngine("podman", "false\n"))
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/rs/zerolog/log"
 	coreV1 "k8s.io/api/core/v1"
+	"sort"
 	"time"
 )
 
@@ -56,3 +57,29 @@ func UnlockService(serviceName, namespace string) {
 		log.Info().Msgf("Service %s doesn't have lock", serviceName)
 	}
 }
+
+// LockServices lock all specified services before exchange, services already locked are unlocked if any one failed
+func LockServices(serviceNames []string, namespace string) error {
+	names := make([]string, 0)
+	for _, name := range serviceNames {
+		if !util.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	// always lock in the same order to avoid dead lock with other users
+	sort.Strings(names)
+	for i, name := range names {
+		if _, err := LockService(name, namespace, 0); err != nil {
+			UnlockServices(names[:i], namespace)
+			return err
+		}
+	}
+	return nil
+}
+
+// UnlockServices unlock all specified services
+func UnlockServices(serviceNames []string, namespace string) {
+	for _, name := range serviceNames {
+		UnlockService(name, namespace)
+	}
+}
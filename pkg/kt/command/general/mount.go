@@ -0,0 +1,359 @@
+package general
+
+import (
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	serviceAccountMountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+	defaultTokenExpiration  = 3600
+	rootCaConfigMap         = "kube-root-ca.crt"
+)
+
+// mountedFiles files of a volume (or a projected volume source) written to local directory
+type mountedFiles struct {
+	target  string
+	subPath string
+	written map[string]bool
+	lock    *sync.Mutex
+}
+
+// SyncMounts mirror config map, secret, service account token and optionally empty dir volumes
+// of target workload to local directory, and keep them updated via watches
+func SyncMounts(resourceName, localDirOption string, syncEmptyDir bool) error {
+	workload, err := GetWorkloadByResourceName(resourceName, opt.Get().Global.Namespace)
+	if err != nil {
+		return err
+	}
+	localDir, envs, err := syncWorkloadFiles(workload, localDirOption, syncEmptyDir)
+	if err != nil {
+		return err
+	}
+	printEnvExports(envs, workload, localDir)
+	return nil
+}
+
+// syncWorkloadFiles sync volumes of first container of workload to local directory, and save its env to '.env' file,
+// return local directory and env of the container
+func syncWorkloadFiles(workload *cluster.Workload, localDirOption string, syncEmptyDir bool) (string, map[string]string, error) {
+	if len(workload.Template.Spec.Containers) == 0 {
+		return "", nil, fmt.Errorf("no container found in %s '%s'", workload.Kind, workload.Name)
+	}
+	localDir, err := prepareLocalDir(localDirOption)
+	if err != nil {
+		return "", nil, err
+	}
+
+	syncWorkloadMounts(workload, localDir, syncEmptyDir)
+	envs := getContainerEnvs(workload.Template.Spec.Containers[0], workload)
+	if err = os.WriteFile(filepath.Join(localDir, ".env"), []byte(toEnvFile(envs)), 0600); err != nil {
+		return "", nil, err
+	}
+	return localDir, envs, nil
+}
+
+// syncWorkloadMounts sync volumes mounted by first container of workload to local directory,
+// return mount paths of synced volumes
+func syncWorkloadMounts(workload *cluster.Workload, localDir string, syncEmptyDir bool) []string {
+	namespace := workload.Namespace
+	spec := workload.Template.Spec
+	container := spec.Containers[0]
+	volumes := map[string]coreV1.Volume{}
+	for _, v := range spec.Volumes {
+		volumes[v.Name] = v
+	}
+	serviceAccountMounted := false
+	mountPaths := make([]string, 0)
+	for _, mount := range container.VolumeMounts {
+		volume, exists := volumes[mount.Name]
+		if !exists {
+			continue
+		}
+		if mount.MountPath == serviceAccountMountPath {
+			serviceAccountMounted = true
+		}
+		files := newMountedFiles(filepath.Join(localDir, mount.MountPath), mount.SubPath)
+		if volume.ConfigMap != nil {
+			syncConfigMap(volume.ConfigMap.Name, namespace, volume.ConfigMap.Items, files)
+		} else if volume.Secret != nil {
+			syncSecret(volume.Secret.SecretName, namespace, volume.Secret.Items, files)
+		} else if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				sourceFiles := files.share()
+				if source.ConfigMap != nil {
+					syncConfigMap(source.ConfigMap.Name, namespace, source.ConfigMap.Items, sourceFiles)
+				} else if source.Secret != nil {
+					syncSecret(source.Secret.Name, namespace, source.Secret.Items, sourceFiles)
+				} else if source.ServiceAccountToken != nil {
+					syncServiceAccountToken(spec.ServiceAccountName, namespace, source.ServiceAccountToken, sourceFiles)
+				} else {
+					log.Debug().Msgf("Skip unsupported projected source of volume %s", volume.Name)
+				}
+			}
+		} else if volume.EmptyDir != nil && syncEmptyDir {
+			syncEmptyDirFromPod(workload, container.Name, mount.MountPath, localDir)
+		} else {
+			log.Debug().Msgf("Skip volume %s", volume.Name)
+			continue
+		}
+		log.Info().Msgf("Syncing volume %s to %s", volume.Name, filepath.Join(localDir, mount.MountPath))
+		mountPaths = append(mountPaths, mount.MountPath)
+	}
+	if !serviceAccountMounted && (spec.AutomountServiceAccountToken == nil || *spec.AutomountServiceAccountToken) {
+		files := newMountedFiles(filepath.Join(localDir, serviceAccountMountPath), "")
+		syncServiceAccountToken(spec.ServiceAccountName, namespace, &coreV1.ServiceAccountTokenProjection{
+			Path: "token",
+		}, files)
+		syncConfigMap(rootCaConfigMap, namespace, []coreV1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}}, files.share())
+		files.share().update(map[string][]byte{"namespace": []byte(namespace)})
+		mountPaths = append(mountPaths, serviceAccountMountPath)
+	}
+	return mountPaths
+}
+
+func prepareLocalDir(localDirOption string) (string, error) {
+	localDir := localDirOption
+	if localDir == "" {
+		localDir = filepath.Join(util.KtHome, "workspace", fmt.Sprintf("%s-%d", opt.Store.Component, os.Getpid()))
+		// record context inorder to remove after command exit
+		opt.Store.LocalDir = localDir
+	}
+	return localDir, os.MkdirAll(localDir, 0700)
+}
+
+func newMountedFiles(target, subPath string) *mountedFiles {
+	return &mountedFiles{
+		target:  target,
+		subPath: subPath,
+		written: map[string]bool{},
+		lock:    &sync.Mutex{},
+	}
+}
+
+// share create another file set writing to the same target, for multiple sources of projected volume
+func (m *mountedFiles) share() *mountedFiles {
+	return &mountedFiles{
+		target:  m.target,
+		subPath: m.subPath,
+		written: map[string]bool{},
+		lock:    m.lock,
+	}
+}
+
+// update write files to local, and remove those no longer exist
+func (m *mountedFiles) update(files map[string][]byte) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.subPath != "" {
+		// volume mounted with sub path is a single file
+		if content, exists := files[m.subPath]; exists {
+			writeMountedFile(m.target, content)
+		}
+		return
+	}
+	for path, content := range files {
+		writeMountedFile(filepath.Join(m.target, path), content)
+	}
+	for path := range m.written {
+		if _, exists := files[path]; !exists {
+			_ = os.Remove(filepath.Join(m.target, path))
+		}
+	}
+	m.written = map[string]bool{}
+	for path := range files {
+		m.written[path] = true
+	}
+}
+
+func writeMountedFile(path string, content []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		log.Warn().Err(err).Msgf("Failed to create directory for %s", path)
+	} else if err = os.WriteFile(path, content, 0600); err != nil {
+		log.Warn().Err(err).Msgf("Failed to write %s", path)
+	}
+}
+
+func syncConfigMap(name, namespace string, items []coreV1.KeyToPath, files *mountedFiles) {
+	update := func(cm *coreV1.ConfigMap) {
+		data := map[string][]byte{}
+		for k, v := range cm.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range cm.BinaryData {
+			data[k] = v
+		}
+		files.update(selectItems(data, items))
+	}
+	if cm, err := cluster.Ins().GetConfigMap(name, namespace); err != nil {
+		log.Warn().Err(err).Msgf("Failed to fetch config map %s", name)
+	} else {
+		update(cm)
+	}
+	go cluster.Ins().WatchConfigMap(name, namespace, nil, func(cm *coreV1.ConfigMap) {
+		log.Warn().Msgf("Config map %s is deleted, local files are kept", cm.Name)
+	}, func(cm *coreV1.ConfigMap) {
+		log.Info().Msgf("Config map %s changed, refreshing local files", cm.Name)
+		update(cm)
+	})
+}
+
+func syncSecret(name, namespace string, items []coreV1.KeyToPath, files *mountedFiles) {
+	update := func(secret *coreV1.Secret) {
+		files.update(selectItems(secret.Data, items))
+	}
+	if secret, err := cluster.Ins().GetSecret(name, namespace); err != nil {
+		log.Warn().Err(err).Msgf("Failed to fetch secret %s", name)
+	} else {
+		update(secret)
+	}
+	go cluster.Ins().WatchSecret(name, namespace, nil, func(secret *coreV1.Secret) {
+		log.Warn().Msgf("Secret %s is deleted, local files are kept", secret.Name)
+	}, func(secret *coreV1.Secret) {
+		log.Info().Msgf("Secret %s changed, refreshing local files", secret.Name)
+		update(secret)
+	})
+}
+
+func syncServiceAccountToken(serviceAccount, namespace string, projection *coreV1.ServiceAccountTokenProjection, files *mountedFiles) {
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+	expiration := int64(defaultTokenExpiration)
+	if projection.ExpirationSeconds != nil {
+		expiration = *projection.ExpirationSeconds
+	}
+	var audiences []string
+	if projection.Audience != "" {
+		audiences = []string{projection.Audience}
+	}
+	refresh := func() time.Duration {
+		token, err := cluster.Ins().CreateServiceAccountToken(serviceAccount, namespace, audiences, expiration)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to request token of service account %s", serviceAccount)
+			return time.Minute
+		}
+		files.update(map[string][]byte{projection.Path: []byte(token.Status.Token)})
+		// refresh token when 80% of its lifetime passed, same as kubelet
+		return time.Until(token.Status.ExpirationTimestamp.Time) * 4 / 5
+	}
+	interval := refresh()
+	go func() {
+		for {
+			time.Sleep(interval)
+			log.Debug().Msgf("Refreshing token of service account %s", serviceAccount)
+			interval = refresh()
+		}
+	}()
+}
+
+func syncEmptyDirFromPod(workload *cluster.Workload, containerName, mountPath, localDir string) {
+	pods, err := cluster.Ins().GetPodsByLabel(workload.Selector, workload.Namespace)
+	if err != nil || len(pods.Items) == 0 {
+		log.Warn().Msgf("No pod of %s '%s' found, skip syncing %s", workload.Kind, workload.Name, mountPath)
+		return
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == coreV1.PodRunning && pod.Labels[util.KtRole] == "" {
+			// empty dir content is only copied once
+			if err = syncMountPath(pod.Name, containerName, pod.Namespace, mountPath, localDir); err != nil {
+				log.Warn().Err(err).Msgf("Failed to copy %s from pod %s", mountPath, pod.Name)
+			}
+			return
+		}
+	}
+	log.Warn().Msgf("No running pod of %s '%s' found, skip syncing %s", workload.Kind, workload.Name, mountPath)
+}
+
+func selectItems(data map[string][]byte, items []coreV1.KeyToPath) map[string][]byte {
+	if len(items) == 0 {
+		return data
+	}
+	selected := map[string][]byte{}
+	for _, item := range items {
+		if content, exists := data[item.Key]; exists {
+			selected[item.Path] = content
+		}
+	}
+	return selected
+}
+
+func printEnvExports(envs map[string]string, workload *cluster.Workload, localDir string) {
+	lines := make([]string, 0)
+	for k, v := range envs {
+		lines = append(lines, fmt.Sprintf("export %s='%s'", k, strings.ReplaceAll(v, "'", "'\\''")))
+	}
+	sort.Strings(lines)
+	log.Info().Msg("---------------------------------------------------------------")
+	log.Info().Msgf(" Volumes are synced to %s", localDir)
+	log.Info().Msgf(" Run following commands to set env of %s '%s'", workload.Kind, workload.Name)
+	log.Info().Msg("---------------------------------------------------------------")
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+// getContainerEnvs resolve env and env from of container to plain values
+func getContainerEnvs(container coreV1.Container, workload *cluster.Workload) map[string]string {
+	envs := map[string]string{}
+	for _, source := range container.EnvFrom {
+		var data map[string][]byte
+		if source.ConfigMapRef != nil {
+			if cm, err := cluster.Ins().GetConfigMap(source.ConfigMapRef.Name, workload.Namespace); err == nil {
+				data = map[string][]byte{}
+				for k, v := range cm.Data {
+					data[k] = []byte(v)
+				}
+			}
+		} else if source.SecretRef != nil {
+			if secret, err := cluster.Ins().GetSecret(source.SecretRef.Name, workload.Namespace); err == nil {
+				data = secret.Data
+			}
+		}
+		for k, v := range data {
+			envs[source.Prefix+k] = string(v)
+		}
+	}
+	for _, env := range container.Env {
+		if value, ok := resolveEnvValue(env, workload); ok {
+			envs[env.Name] = value
+		}
+	}
+	return envs
+}
+
+func resolveEnvValue(env coreV1.EnvVar, workload *cluster.Workload) (string, bool) {
+	if env.ValueFrom == nil {
+		return env.Value, true
+	}
+	if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+		if cm, err := cluster.Ins().GetConfigMap(ref.Name, workload.Namespace); err == nil {
+			value, exists := cm.Data[ref.Key]
+			return value, exists
+		}
+	} else if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+		if secret, err := cluster.Ins().GetSecret(ref.Name, workload.Namespace); err == nil {
+			value, exists := secret.Data[ref.Key]
+			return string(value), exists
+		}
+	} else if ref := env.ValueFrom.FieldRef; ref != nil {
+		switch ref.FieldPath {
+		case "metadata.namespace":
+			return workload.Namespace, true
+		case "metadata.name":
+			return workload.Name, true
+		case "spec.serviceAccountName":
+			return workload.Template.Spec.ServiceAccountName, true
+		}
+	}
+	log.Debug().Msgf("Skip env %s which cannot be resolved locally", env.Name)
+	return "", false
+}
@@ -0,0 +1,43 @@
+package general
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+)
+
+// CheckOutputFormat verify format of machine-readable output, empty for human-readable logs
+func CheckOutputFormat(format string) error {
+	if format != "" && format != util.OutputJson && format != util.OutputYaml {
+		return fmt.Errorf("invalid output format '%s', should be '%s' or '%s'", format, util.OutputJson, util.OutputYaml)
+	}
+	return nil
+}
+
+// PrintOutput print data to stdout in specified format
+func PrintOutput(format string, data any) error {
+	return writeOutput(os.Stdout, format, data)
+}
+
+func writeOutput(w io.Writer, format string, data any) error {
+	var content []byte
+	var err error
+	switch format {
+	case util.OutputJson:
+		if content, err = json.MarshalIndent(data, "", "  "); err == nil {
+			content = append(content, '\n')
+		}
+	case util.OutputYaml:
+		content, err = yaml.Marshal(data)
+	default:
+		err = CheckOutputFormat(format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
@@ -0,0 +1,26 @@
+package general
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_writeOutput(t *testing.T) {
+	data := struct {
+		Name  string   `json:"name" yaml:"name"`
+		Users []string `json:"users" yaml:"users"`
+	}{Name: "order", Users: []string{"tom"}}
+
+	var buf bytes.Buffer
+	require.Nil(t, writeOutput(&buf, "json", data))
+	require.Equal(t, "{\n  \"name\": \"order\",\n  \"users\": [\n    \"tom\"\n  ]\n}\n", buf.String())
+
+	buf.Reset()
+	require.Nil(t, writeOutput(&buf, "yaml", data))
+	require.Equal(t, "name: order\nusers:\n    - tom\n", buf.String())
+
+	require.NotNil(t, writeOutput(&buf, "xml", data))
+	require.Nil(t, CheckOutputFormat(""))
+	require.NotNil(t, CheckOutputFormat("table"))
+}
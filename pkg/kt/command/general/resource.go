@@ -8,7 +8,6 @@ import (
 	"github.com/alibaba/kt-connect/pkg/kt/transmission"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/rs/zerolog/log"
-	appV1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -16,14 +15,35 @@ import (
 	"time"
 )
 
-func CreateShadowAndInbound(shadowPodName, portsToExpose string, labels, annotations map[string]string, portNameDict map[int]string) error {
+// meshProtocols protocols recognized by istio via port name prefix
+var meshProtocols = []string{"http", "http2", "https", "grpc", "tcp", "tls", "mongo", "mysql", "redis", "udp"}
 
+// CreateShadowAndInbound create shadow pod and forward its ports to local,
+// pod template of origin workload is cloned into shadow when origin is specified
+func CreateShadowAndInbound(shadowPodName, portsToExpose string, labels, annotations map[string]string,
+	portNameDict map[int]string, origin *cluster.Workload) error {
+
+	var template *coreV1.PodTemplateSpec
+	if origin != nil {
+		template = &origin.Template
+		labels = util.MergeMap(GetNetworkPolicyLabels(template, opt.Get().Global.Namespace), labels)
+	}
 	envs := make(map[string]string)
-	_, podName, privateKeyPath, err := cluster.Ins().GetOrCreateShadow(shadowPodName, labels, annotations, envs, portsToExpose, portNameDict)
+	_, podName, privateKeyPath, err := cluster.Ins().GetOrCreateShadow(shadowPodName, labels, annotations, envs,
+		nil, portsToExpose, portNameDict, template)
 	if err != nil {
 		return err
 	}
 
+	// files are already synced when mount sync or run image is enabled
+	if origin != nil && !opt.Get().Exchange.MountSync && opt.Get().Exchange.RunImage == "" {
+		if localDir, _, err2 := syncWorkloadFiles(origin, opt.Get().Exchange.LocalDir, false); err2 != nil {
+			log.Warn().Err(err2).Msgf("Failed to sync env and volumes of %s '%s'", origin.Kind, origin.Name)
+		} else {
+			log.Info().Msgf("Env and volumes of %s '%s' synced to %s", origin.Kind, origin.Name, localDir)
+		}
+	}
+
 	if _, err = transmission.ForwardPodToLocal(portsToExpose, podName, privateKeyPath); err != nil {
 		return err
 	}
@@ -36,49 +56,33 @@ func GetServiceByResourceName(resourceName, namespace string) (*coreV1.Service,
 		return nil, err
 	}
 
-	switch resourceType {
-	case "deploy":
-		fallthrough
-	case "deployment":
-		app, err2 := cluster.Ins().GetDeployment(name, namespace)
-		if err2 != nil {
-			if k8sErrors.IsNotFound(err2) {
-				return nil, fmt.Errorf("deployment '%s' is not found in namespace %s", name, namespace)
-			}
-			return nil, err2
-		}
-		return getServiceByDeployment(app, namespace)
-	case "svc":
-		fallthrough
-	case "service":
+	if isServiceType(resourceType) {
 		svc, err2 := cluster.Ins().GetService(name, namespace)
 		if err2 != nil && k8sErrors.IsNotFound(err2) {
 			return nil, fmt.Errorf("service '%s' is not found in namespace %s", name, namespace)
 		}
 		return svc, err2
-	default:
-		return nil, fmt.Errorf("invalid resource type: %s", resourceType)
 	}
+	kind, err := toWorkloadKind(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	workload, err := getWorkload(kind, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return getServiceByWorkload(workload, namespace)
 }
 
-func GetDeploymentByResourceName(resourceName, namespace string) (*appV1.Deployment, error) {
+// GetWorkloadByResourceName get deployment, statefulset, replicaset, daemonset or rollout by resource name,
+// for service resource, the first workload selected by it is returned
+func GetWorkloadByResourceName(resourceName, namespace string) (*cluster.Workload, error) {
 	resourceType, name, err := ParseResourceName(resourceName)
 	if err != nil {
 		return nil, err
 	}
 
-	switch resourceType {
-	case "deploy":
-		fallthrough
-	case "deployment":
-		app, err2 := cluster.Ins().GetDeployment(name, namespace)
-		if err2 != nil && k8sErrors.IsNotFound(err2) {
-			return nil, fmt.Errorf("deployment '%s' is not found in namespace %s", name, namespace)
-		}
-		return app, err2
-	case "svc":
-		fallthrough
-	case "service":
+	if isServiceType(resourceType) {
 		svc, err2 := cluster.Ins().GetService(name, namespace)
 		if err2 != nil {
 			if k8sErrors.IsNotFound(err2) {
@@ -86,10 +90,13 @@ func GetDeploymentByResourceName(resourceName, namespace string) (*appV1.Deploym
 			}
 			return nil, err2
 		}
-		return getDeploymentByService(svc, namespace)
-	default:
-		return nil, fmt.Errorf("invalid resource type: %s", resourceType)
+		return getWorkloadByService(svc, namespace)
+	}
+	kind, err := toWorkloadKind(resourceType)
+	if err != nil {
+		return nil, err
 	}
+	return getWorkload(kind, name, namespace)
 }
 
 func ParseResourceName(resourceName string) (string, string, error) {
@@ -147,7 +154,7 @@ func UpdateServiceSelector(svcName, namespace string, selector map[string]string
 		} else if pods.Items[0].DeletionTimestamp != nil {
 			log.Warn().Msgf("Router pod is terminating")
 			return
-		} else if len(pods.Items) > 1 {
+		} else if len(pods.Items) > 1 && selector[util.KtRole] != "" {
 			log.Warn().Msgf("More than one router pod selected")
 		}
 		if !isServiceChanged(newSvc, selector, marshaledSelector) {
@@ -177,7 +184,7 @@ func GetTargetPorts(svc *coreV1.Service) map[int]string {
 	targetPorts := map[int]string{}
 	for _, p := range svcPorts {
 		if p.TargetPort.Type == intstr.Int {
-			targetPorts[p.TargetPort.IntValue()] = fmt.Sprintf("kt-%d", p.TargetPort.IntValue())
+			targetPorts[p.TargetPort.IntValue()] = getMeshPortName(p, p.TargetPort.IntValue())
 		} else {
 			if pod == nil {
 				pods, err := cluster.Ins().GetPodsByLabel(svc.Spec.Selector, opt.Get().Global.Namespace)
@@ -199,17 +206,71 @@ func GetTargetPorts(svc *coreV1.Service) map[int]string {
 	return targetPorts
 }
 
+// getMeshPortName name target port by protocol of service port, following the service mesh convention "<protocol>-<suffix>",
+// port of unknown protocol is named as kt-<port> to let service mesh detect protocol automatically
+func getMeshPortName(svcPort coreV1.ServicePort, port int) string {
+	protocol := ""
+	if svcPort.AppProtocol != nil {
+		// e.g. "http", "kubernetes.io/h2c"
+		segments := strings.Split(strings.ToLower(*svcPort.AppProtocol), "/")
+		protocol = segments[len(segments)-1]
+		if protocol == "h2c" {
+			protocol = "http2"
+		}
+	} else {
+		protocol = strings.Split(strings.ToLower(svcPort.Name), "-")[0]
+	}
+	for _, p := range meshProtocols {
+		if protocol == p {
+			return fmt.Sprintf("%s-%d", protocol, port)
+		}
+	}
+	if svcPort.Protocol == coreV1.ProtocolUDP {
+		return fmt.Sprintf("udp-%d", port)
+	}
+	return fmt.Sprintf("kt-%d", port)
+}
+
 func isServiceChanged(svc *coreV1.Service, selector map[string]string, marshaledSelector string) bool {
 	return !util.MapEquals(svc.Spec.Selector, selector) || svc.Annotations == nil || svc.Annotations[util.KtSelector] != marshaledSelector
 }
 
-func getServiceByDeployment(app *appV1.Deployment, namespace string) (*coreV1.Service, error) {
-	svcList, err := cluster.Ins().GetServicesBySelector(app.Spec.Selector.MatchLabels, namespace)
+func isServiceType(resourceType string) bool {
+	return resourceType == "svc" || resourceType == "service"
+}
+
+func toWorkloadKind(resourceType string) (string, error) {
+	switch resourceType {
+	case "deploy", "deployment":
+		return util.WorkloadDeployment, nil
+	case "sts", "statefulset":
+		return util.WorkloadStatefulSet, nil
+	case "rs", "replicaset":
+		return util.WorkloadReplicaSet, nil
+	case "ds", "daemonset":
+		return util.WorkloadDaemonSet, nil
+	case "ro", "rollout":
+		return util.WorkloadRollout, nil
+	default:
+		return "", fmt.Errorf("invalid resource type: %s", resourceType)
+	}
+}
+
+func getWorkload(kind, name, namespace string) (*cluster.Workload, error) {
+	workload, err := cluster.Ins().GetWorkload(kind, name, namespace)
+	if err != nil && k8sErrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%s '%s' is not found in namespace %s", kind, name, namespace)
+	}
+	return workload, err
+}
+
+func getServiceByWorkload(workload *cluster.Workload, namespace string) (*coreV1.Service, error) {
+	svcList, err := cluster.Ins().GetServicesBySelector(workload.Selector, namespace)
 	if err != nil {
 		return nil, err
 	} else if len(svcList) == 0 {
-		return nil, fmt.Errorf("failed to find service for deployment '%s', with labels '%v'",
-			app.Name, app.Spec.Selector.MatchLabels)
+		return nil, fmt.Errorf("failed to find service for %s '%s', with labels '%v'",
+			workload.Kind, workload.Name, workload.Selector)
 	} else if len(svcList) > 1 {
 		svcNames := svcList[0].Name
 		for i, svc := range svcList {
@@ -217,8 +278,8 @@ func getServiceByDeployment(app *appV1.Deployment, namespace string) (*coreV1.Se
 				svcNames = svcNames + ", " + svc.Name
 			}
 		}
-		log.Warn().Msgf("Found %d services match deployment '%s': %s. First one will be used.",
-			len(svcList), app.Name, svcNames)
+		log.Warn().Msgf("Found %d services match %s '%s': %s. First one will be used.",
+			len(svcList), workload.Kind, workload.Name, svcNames)
 	}
 	svc := svcList[0]
 	if strings.HasSuffix(svc.Name, util.StuntmanServiceSuffix) {
@@ -227,19 +288,27 @@ func getServiceByDeployment(app *appV1.Deployment, namespace string) (*coreV1.Se
 	return &svc, nil
 }
 
-func getDeploymentByService(svc *coreV1.Service, namespace string) (*appV1.Deployment, error) {
-	apps, err := cluster.Ins().GetAllDeploymentInNamespace(namespace)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, app := range apps.Items {
-		if util.MapContains(svc.Spec.Selector, app.Spec.Template.Labels) {
-			log.Info().Msgf("Using first matched deployment '%s'", app.Name)
-			return &app, nil
+func getWorkloadByService(svc *coreV1.Service, namespace string) (*cluster.Workload, error) {
+	kinds := []string{util.WorkloadDeployment, util.WorkloadStatefulSet, util.WorkloadRollout,
+		util.WorkloadReplicaSet, util.WorkloadDaemonSet}
+	for _, kind := range kinds {
+		workloads, err := cluster.Ins().GetAllWorkloadInNamespace(kind, namespace)
+		if err != nil {
+			if kind == util.WorkloadRollout {
+				// argo rollout crd may not be installed
+				log.Debug().Err(err).Msgf("Failed to list rollouts")
+				continue
+			}
+			return nil, err
+		}
+		for _, workload := range workloads {
+			if util.MapContains(svc.Spec.Selector, workload.Template.Labels) {
+				log.Info().Msgf("Using first matched %s '%s'", kind, workload.Name)
+				return &workload, nil
+			}
 		}
 	}
-	return nil, fmt.Errorf("failed to find deployment for service '%s', with selector '%v'", svc.Name, svc.Spec.Selector)
+	return nil, fmt.Errorf("failed to find workload for service '%s', with selector '%v'", svc.Name, svc.Spec.Selector)
 }
 
 func GetOccupiedUser(labels map[string]string) string {
@@ -0,0 +1,43 @@
+package general
+
+import (
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+)
+
+// DetectSidecar decide which service mesh sidecar should be injected into shadow and router pods,
+// according to injection setting of current namespace and target workload (if resource name is not empty)
+func DetectSidecar(resourceName string) {
+	switch opt.Get().Global.SidecarInject {
+	case util.SidecarInjectNone:
+		return
+	case util.SidecarIstio, util.SidecarLinkerd:
+		opt.Store.Sidecar = opt.Get().Global.SidecarInject
+		return
+	case util.SidecarInjectAuto:
+	default:
+		log.Warn().Msgf("Invalid sidecar inject option '%s', using '%s'", opt.Get().Global.SidecarInject, util.SidecarInjectAuto)
+	}
+
+	namespace, err := cluster.Ins().GetNamespace(opt.Get().Global.Namespace)
+	if err != nil {
+		// user may have no permission to read namespace
+		log.Debug().Err(err).Msgf("Failed to fetch namespace %s", opt.Get().Global.Namespace)
+		namespace = nil
+	}
+	var template *coreV1.PodTemplateSpec
+	if resourceName != "" {
+		if app, err2 := GetWorkloadByResourceName(resourceName, opt.Get().Global.Namespace); err2 == nil {
+			template = &app.Template
+		} else {
+			log.Debug().Err(err2).Msgf("Failed to fetch workload of %s", resourceName)
+		}
+	}
+	opt.Store.Sidecar = cluster.DetectSidecar(namespace, template)
+	if opt.Store.Sidecar != "" {
+		log.Info().Msgf("Sidecar injection of %s detected, shadow and router pods will be injected as well", opt.Store.Sidecar)
+	}
+}
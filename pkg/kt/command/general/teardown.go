@@ -25,10 +25,14 @@ func CleanupWorkspace() {
 	}
 
 	if opt.Store.Component == util.ComponentExchange {
-		recoverExchangedTarget()
+		recoverExchangedTargets()
+		if opt.Store.LocalContainer != "" {
+			RemoveLocalContainer(opt.Store.LocalContainer)
+		}
 	} else if opt.Store.Component == util.ComponentMesh {
 		recoverAutoMeshRoute()
 	}
+	cleanIngress()
 	cleanService()
 	cleanShadowPodAndConfigMap()
 }
@@ -59,6 +63,14 @@ func cleanLocalFiles() {
 		log.Info().Msgf("Removed pid file %s", pidFile)
 	}
 
+	if opt.Store.LocalDir != "" {
+		if err := os.RemoveAll(opt.Store.LocalDir); err != nil {
+			log.Debug().Err(err).Msgf("Remove synced directory %s failed", opt.Store.LocalDir)
+		} else {
+			log.Info().Msgf("Removed synced directory %s", opt.Store.LocalDir)
+		}
+	}
+
 	if opt.Store.Shadow != "" {
 		for _, sshcm := range strings.Split(opt.Store.Shadow, ",") {
 			file := util.PrivateKeyPath(sshcm)
@@ -73,29 +85,44 @@ func cleanLocalFiles() {
 	}
 }
 
-func recoverExchangedTarget() {
-	if opt.Store.Origin == "" {
-		// process exit before target exchanged
-		return
+func recoverExchangedTargets() {
+	targets := opt.Store.Exchanged
+	// origin is empty if process exit before current target exchanged
+	if opt.Store.Origin != "" {
+		targets = append(targets, opt.ExchangedTarget{
+			Origin:     opt.Store.Origin,
+			OriginKind: opt.Store.OriginKind,
+			Replicas:   opt.Store.Replicas,
+		})
+	}
+	for _, target := range targets {
+		recoverExchangedTarget(target)
 	}
+}
+
+func recoverExchangedTarget(target opt.ExchangedTarget) {
 	if opt.Get().Exchange.Mode == util.ExchangeModeScale {
-		log.Info().Msgf("Recovering origin deployment %s", opt.Store.Origin)
-		err := cluster.Ins().ScaleTo(opt.Store.Origin, opt.Get().Global.Namespace, &opt.Store.Replicas)
+		log.Info().Msgf("Recovering origin %s %s", target.OriginKind, target.Origin)
+		err := cluster.Ins().ScaleWorkload(target.OriginKind, target.Origin, opt.Get().Global.Namespace, target.Replicas)
 		if err != nil {
-			log.Error().Err(err).Msgf("Scale deployment %s to %d failed",
-				opt.Store.Origin, opt.Store.Replicas)
+			log.Error().Err(err).Msgf("Scale %s %s to %d failed",
+				target.OriginKind, target.Origin, target.Replicas)
 		}
 		// wait for scale complete
 		ch := make(chan os.Signal, 1)
 		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
 		go func() {
-			waitDeploymentRecoverComplete()
+			waitWorkloadRecoverComplete(target)
 			ch <- os.Interrupt
 		}()
 		_ = <-ch
-	} else if opt.Get().Exchange.Mode == util.ExchangeModeSelector {
-		RecoverOriginalService(opt.Store.Origin, opt.Get().Global.Namespace)
-		log.Info().Msgf("Original service %s recovered", opt.Store.Origin)
+	} else if opt.Get().Exchange.Mode == util.ExchangeModeSelector || opt.Get().Exchange.Mode == util.ExchangeModeCanary ||
+		opt.Get().Exchange.Mode == util.ExchangeModeEndpoint {
+		if err := RecoverOriginalService(target.Origin, opt.Get().Global.Namespace); err != nil {
+			log.Error().Err(err).Msgf("Failed to recover original service %s", target.Origin)
+		} else {
+			log.Info().Msgf("Original service %s recovered", target.Origin)
+		}
 	}
 }
 
@@ -132,8 +159,11 @@ func recoverAutoMeshRoute() {
 }
 
 func recoverService(originSvcName string) {
-	RecoverOriginalService(originSvcName, opt.Get().Global.Namespace)
-	log.Info().Msgf("Original service %s recovered", originSvcName)
+	if err := RecoverOriginalService(originSvcName, opt.Get().Global.Namespace); err != nil {
+		log.Error().Err(err).Msgf("Failed to recover original service %s", originSvcName)
+	} else {
+		log.Info().Msgf("Original service %s recovered", originSvcName)
+	}
 
 	stuntmanSvcName := originSvcName + util.StuntmanServiceSuffix
 	if err := cluster.Ins().RemoveService(stuntmanSvcName, opt.Get().Global.Namespace); err != nil {
@@ -142,51 +172,87 @@ func recoverService(originSvcName string) {
 	log.Info().Msgf("Stuntman service %s removed", stuntmanSvcName)
 }
 
-func RecoverOriginalService(svcName, namespace string) {
-	if svc, err := cluster.Ins().GetService(svcName, namespace); err != nil {
-		log.Error().Err(err).Msgf("Original service %s not found", svcName)
+// RecoverOriginalService restore selector or endpoints of service changed by exchange or mesh
+func RecoverOriginalService(svcName, namespace string) error {
+	svc, err := cluster.Ins().GetService(svcName, namespace)
+	if err != nil {
+		return fmt.Errorf("original service %s not found: %s", svcName, err)
+	}
+	if svc.Annotations == nil {
+		return fmt.Errorf("no annotation found in service %s", svcName)
+	}
+	if sliceName, exists := svc.Annotations[util.KtEndpointSlice]; exists {
+		return recoverServiceEndpoints(svc, sliceName)
+	}
+	originSelector, exists := svc.Annotations[util.KtSelector]
+	if !exists {
+		return fmt.Errorf("no selector annotation found in service %s", svcName)
+	}
+	var selector map[string]string
+	if err = json.Unmarshal([]byte(originSelector), &selector); err != nil {
+		return fmt.Errorf("failed to unmarshal original selector of service %s: %s", svcName, err)
+	}
+	canary := svc.Spec.Selector[util.KtCanary]
+	svc.Spec.Selector = selector
+	delete(svc.Annotations, util.KtSelector)
+	if _, err = cluster.Ins().UpdateService(svc); err != nil {
+		return fmt.Errorf("failed to recover selector of original service %s: %s", svcName, err)
+	}
+	if canary != "" {
+		removeCanaryLabel(canary, namespace)
+	}
+	return nil
+}
+
+func removeCanaryLabel(canary, namespace string) {
+	pods, err := cluster.Ins().GetPodsByLabel(map[string]string{util.KtCanary: canary}, namespace)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to fetch pods with canary label")
 		return
-	} else {
-		var selector map[string]string
-		if svc.Annotations == nil {
-			log.Warn().Msgf("No annotation found in service %s, skipping", svcName)
-			return
-		}
-		originSelector, exists := svc.Annotations[util.KtSelector]
-		if !exists {
-			log.Warn().Msgf("No selector annotation found in service %s, skipping", svcName)
-			return
-		}
-		if err = json.Unmarshal([]byte(originSelector), &selector); err != nil {
-			log.Error().Err(err).Msgf("Failed to unmarshal original selector of service %s", svcName)
-			return
+	}
+	for _, pod := range pods.Items {
+		if pod.Labels[util.KtRole] != "" || pod.DeletionTimestamp != nil {
+			continue
 		}
-		svc.Spec.Selector = selector
-		delete(svc.Annotations, util.KtSelector)
-		if _, err = cluster.Ins().UpdateService(svc); err != nil {
-			log.Error().Err(err).Msgf("Failed to recover selector of original service %s", svcName)
+		if err = cluster.Ins().RemovePodLabel(pod.Name, namespace, util.KtCanary); err != nil {
+			log.Warn().Err(err).Msgf("Failed to remove canary label of pod %s", pod.Name)
 		}
 	}
 }
 
-func waitDeploymentRecoverComplete() {
+func waitWorkloadRecoverComplete(target opt.ExchangedTarget) {
 	ok := false
 	counts := opt.Get().Exchange.RecoverWaitTime / 5
 	for i := 0; i < counts; i++ {
-		deployment, err := cluster.Ins().GetDeployment(opt.Store.Origin, opt.Get().Global.Namespace)
+		workload, err := cluster.Ins().GetWorkload(target.OriginKind, target.Origin, opt.Get().Global.Namespace)
 		if err != nil {
-			log.Error().Err(err).Msgf("Cannot fetch original deployment %s", opt.Store.Origin)
+			log.Error().Err(err).Msgf("Cannot fetch original %s %s", target.OriginKind, target.Origin)
 			break
-		} else if deployment.Status.ReadyReplicas == opt.Store.Replicas {
+		} else if workload.ReadyReplicas >= target.Replicas {
 			ok = true
 			break
 		} else {
-			log.Info().Msgf("Wait for deployment %s recover ...", opt.Store.Origin)
+			log.Info().Msgf("Wait for %s %s recover ...", target.OriginKind, target.Origin)
 			time.Sleep(5 * time.Second)
 		}
 	}
 	if !ok {
-		log.Warn().Msgf("Deployment %s recover timeout", opt.Store.Origin)
+		log.Warn().Msgf("%s %s recover timeout", util.Capitalize(target.OriginKind), target.Origin)
+	}
+}
+
+func cleanIngress() {
+	if opt.Store.Ingress != "" {
+		log.Info().Msgf("Cleaning %s %s", opt.Get().Preview.Ingress, opt.Store.Ingress)
+		var err error
+		if opt.Get().Preview.Ingress == util.PreviewHttpRoute {
+			err = cluster.Ins().RemoveHttpRoute(opt.Store.Ingress, opt.Get().Global.Namespace)
+		} else {
+			err = cluster.Ins().RemoveIngress(opt.Store.Ingress, opt.Get().Global.Namespace)
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Delete %s %s failed", opt.Get().Preview.Ingress, opt.Store.Ingress)
+		}
 	}
 }
 
@@ -222,6 +288,10 @@ func cleanShadowPodAndConfigMap() {
 				if err != nil {
 					log.Error().Err(err).Msgf("Delete configmap %s failed", shadow)
 				}
+				if opt.Store.Component == util.ComponentExchange && opt.Get().Exchange.Mode == util.ExchangeModeEphemeral {
+					// in ephemeral mode, shadow is the exchanged pod itself
+					continue
+				}
 				log.Info().Msgf("Cleaning shadow pod %s", shadow)
 				if opt.Get().Global.UseShadowDeployment {
 					err = cluster.Ins().RemoveDeployment(shadow, opt.Get().Global.Namespace)
@@ -235,7 +305,15 @@ func cleanShadowPodAndConfigMap() {
 		}
 		if opt.Get().Exchange.Mode == util.ExchangeModeEphemeral {
 			for _, shadow := range strings.Split(opt.Store.Shadow, ",") {
-				log.Info().Msgf("Removing ephemeral container of pod %s", shadow)
+				log.Info().Msgf("Removing redirect rules of pod %s", shadow)
+				_, stderr, err2 := cluster.Ins().ExecInPod(util.KtExchangeContainer, shadow, opt.Get().Global.Namespace,
+					util.NavigatorBin, "teardown")
+				if err2 == nil {
+					continue
+				}
+				log.Debug().Msgf("Stderr: %s", stderr)
+				// fallback to remove the pod, in case of redirect rules cannot be restored
+				log.Warn().Err(err2).Msgf("Failed to remove redirect rules, removing pod %s", shadow)
 				err = cluster.Ins().RemoveEphemeralContainer(util.KtExchangeContainer, shadow, opt.Get().Global.Namespace)
 				if err != nil {
 					log.Error().Err(err).Msgf("Remove ephemeral container of pod %s failed", shadow)
@@ -58,6 +58,14 @@ func Mesh(resourceName string) error {
 		return fmt.Errorf("target port %s not exists in service %s", port, svc.Name)
 	}
 
+	if opt.Get().Mesh.MountSync {
+		if err = general.SyncMounts(resourceName, opt.Get().Mesh.LocalDir, opt.Get().Mesh.SyncEmptyDir); err != nil {
+			return err
+		}
+	}
+
+	general.DetectSidecar(resourceName)
+
 	log.Info().Msgf("Using %s mode", opt.Get().Mesh.Mode)
 	if opt.Get().Mesh.Mode == util.MeshModeManual {
 		err = mesh.ManualMesh(svc)
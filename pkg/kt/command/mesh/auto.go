@@ -23,7 +23,9 @@ func AutoMesh(svc *coreV1.Service) error {
 	}
 	defer general.UnlockService(svc.Name, opt.Get().Global.Namespace)
 
-	if svc.Annotations != nil && svc.Annotations[util.KtSelector] != "" && svc.Spec.Selector[util.KtRole] == util.RoleExchangeShadow {
+	if svc.Annotations != nil && ((svc.Annotations[util.KtSelector] != "" &&
+		(svc.Spec.Selector[util.KtRole] == util.RoleExchangeShadow || svc.Spec.Selector[util.KtCanary] != "")) ||
+		svc.Annotations[util.KtEndpointSlice] != "") {
 		return fmt.Errorf("another user%s is exchanging service '%s', cannot apply mesh",
 			general.GetOccupiedUser(svc.Spec.Selector), svc.Name)
 	}
@@ -96,7 +98,7 @@ func AutoMesh(svc *coreV1.Service) error {
 		util.KtConfig: fmt.Sprintf("service=%s", shadowName),
 	}
 	if err = general.CreateShadowAndInbound(shadowName, opt.Get().Mesh.Expose,
-		shadowLabels, annotations, portToNames); err != nil {
+		shadowLabels, annotations, portToNames, nil); err != nil {
 		return err
 	}
 	log.Info().Msg("---------------------------------------------------------------")
@@ -148,6 +150,7 @@ func createShadowService(shadowSvcName string, ports map[int]int,
 		External:  false,
 		Ports:     ports,
 		Selectors: selectors,
+		PortNames: toHttpPortNames(ports),
 	}); err != nil {
 		return err
 	}
@@ -232,6 +235,7 @@ func createStuntmanService(svc *coreV1.Service, ports map[int]int) error {
 			External:  false,
 			Ports:     ports,
 			Selectors: svc.Spec.Selector,
+			PortNames: toHttpPortNames(ports),
 		}); err != nil {
 			return err
 		}
@@ -245,6 +249,15 @@ func createStuntmanService(svc *coreV1.Service, ports map[int]int) error {
 	return nil
 }
 
+// toHttpPortNames name ports with http protocol, following the service mesh convention, since router only forwards http requests
+func toHttpPortNames(ports map[int]int) map[int]string {
+	names := make(map[int]string)
+	for port := range ports {
+		names[port] = fmt.Sprintf("http-%d", port)
+	}
+	return names
+}
+
 func toPortMapParameter(ports map[int]int) string {
 	// input: { 80:8080, 70:7000 }
 	// output: "80:8080,70:7000"
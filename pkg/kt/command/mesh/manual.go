@@ -14,7 +14,7 @@ func ManualMesh(svc *coreV1.Service) error {
 	labels := getMeshLabels(meshKey, meshVersion, svc)
 	annotations := make(map[string]string)
 	if err := general.CreateShadowAndInbound(shadowPodName, opt.Get().Mesh.Expose, labels,
-		annotations, general.GetTargetPorts(svc)); err != nil {
+		annotations, general.GetTargetPorts(svc), nil); err != nil {
 		return err
 	}
 	log.Info().Msg("---------------------------------------------------------")
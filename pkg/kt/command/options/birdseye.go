@@ -22,6 +22,17 @@ func BirdseyeFlags() []OptionConfig {
 			DefaultValue: false,
 			Description: "Only show exchanged / meshed and previewing services",
 		},
+		{
+			Target:      "Watch",
+			DefaultValue: false,
+			Description: "Show services in an interactive dashboard which keeps refreshing",
+		},
+		{
+			Target:      "Output",
+			Alias:       "o",
+			DefaultValue: "",
+			Description: fmt.Sprintf("Print result in '%s' or '%s' format instead of logs", util.OutputJson, util.OutputYaml),
+		},
 	}
 	return flags
 }
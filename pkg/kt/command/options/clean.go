@@ -1,6 +1,7 @@
 package options
 
 import (
+	"fmt"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 )
 
@@ -21,6 +22,12 @@ func CleanFlags() []OptionConfig {
 			DefaultValue: false,
 			Description:  "Only check and restore local changes made by kt",
 		},
+		{
+			Target:       "Output",
+			Alias:        "o",
+			DefaultValue: "",
+			Description:  fmt.Sprintf("Print resources to clean in '%s' or '%s' format", util.OutputJson, util.OutputYaml),
+		},
 	}
 	return flags
 }
@@ -1,6 +1,7 @@
 package options
 
 import (
+	"github.com/alibaba/kt-connect/pkg/common"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 )
 
@@ -46,10 +47,30 @@ func ConnectFlags() []OptionConfig {
 			DefaultValue: "",
 			Description: "Do not route specified IPs to cluster, e.g. '192.168.64.2' or '192.168.64.0/24', use ',' separated",
 		},
+		{
+			Target:      "IngressDns",
+			DefaultValue: false,
+			Description: "(local dns mode only) Resolve ingress and http route domains to ingress address, implied when '--ingressIp' is specified",
+		},
 		{
 			Target:      "IngressIp",
 			DefaultValue: "",
-			Description: "Specify an IP address which all ingress domains should be resolve to",
+			Description: "Specify an IP address which all ingress domains should be resolve to, auto detect from ingress and gateway status by default",
+		},
+		{
+			Target:      "IngressNamespaces",
+			DefaultValue: "",
+			Description: "(local dns mode only) Namespaces to resolve ingress and http route domains, use ',' separated, or '*' for all namespaces (default current namespace)",
+		},
+		{
+			Target:      "IngressController",
+			DefaultValue: "",
+			Description: "(local dns mode only) Ingress controller service in '<namespace>/<name>' format, its address is used when ingress or gateway status has none",
+		},
+		{
+			Target:      "DnsAliases",
+			DefaultValue: "",
+			Description: "(local dns mode only) Domain aliases resolved as CNAME to cluster services, e.g. 'db.local=mysql.infra', use ',' separated",
 		},
 		{
 			Target:      "DisableTunDevice",
@@ -69,7 +90,17 @@ func ConnectFlags() []OptionConfig {
 		{
 			Target:      "DnsCacheTtl",
 			DefaultValue: 60,
-			Description: "(local dns mode only) DNS cache refresh interval in seconds",
+			Description: "(local dns mode only) Max DNS cache ttl in seconds, records are cached according to their own ttl",
+		},
+		{
+			Target:      "DnsCacheSize",
+			DefaultValue: common.DefaultDnsCacheSize,
+			Description: "(local dns mode only) Max count of records in DNS cache",
+		},
+		{
+			Target:      "DnsMetricsPort",
+			DefaultValue: 0,
+			Description: "(local dns mode only) Local port to expose DNS cache metrics in prometheus format at '/metrics', 0 for disabled",
 		},
 	}
 	if util.IsMacos() {
@@ -8,22 +8,66 @@ func ExchangeFlags() []OptionConfig {
 			Target:       "Expose",
 			DefaultValue: "",
 			Description:  "Ports to expose, use ',' separated, in [port] or [local:remote] format, e.g. 7001,8080:80",
-			Required:     true,
 		},
 		{
 			Target:       "Mode",
 			DefaultValue: util.ExchangeModeSelector,
-			Description:  "Exchange method 'selector', 'scale' or 'ephemeral'(experimental)",
+			Description:  "Exchange method 'selector', 'scale', 'canary', 'endpoint' or 'ephemeral'(experimental)",
 		},
 		{
 			Target:       "SkipPortChecking",
 			DefaultValue: false,
-			Description:  "Do not check whether specified local ports are listened",
+			Description:  "Do not wait for local application ready before redirecting requests, nor fall back when it stops working",
+		},
+		{
+			Target:       "HealthCheck",
+			DefaultValue: "",
+			Description:  "Http path on first local port to check whether application is healthy, e.g. /healthz, default is checking whether local ports are listened",
+		},
+		{
+			Target:       "CutoverTimeout",
+			DefaultValue: 60,
+			Description:  "Seconds to wait for local application ready before giving up redirecting requests",
 		},
 		{
 			Target:       "RecoverWaitTime",
 			DefaultValue: 120,
-			Description:  "(scale method only) Seconds to wait for original deployment recover before turn off the shadow pod",
+			Description:  "(scale method only) Seconds to wait for original workload recover before turn off the shadow pod",
+		},
+		{
+			Target:       "ReplicaRatio",
+			DefaultValue: 0,
+			Description:  "(canary method only) Percentage of shadow pods among all pods of service, which roughly decides share of requests to local, default is using one shadow pod",
+		},
+		{
+			Target:       "CloneTemplate",
+			DefaultValue: false,
+			Description:  "Inherit env, volumes, service account and network policy labels of original pod, and sync them to local",
+		},
+		{
+			Target:       "MountSync",
+			DefaultValue: false,
+			Description:  "Mirror config map, secret and service account token volumes of target workload to local and keep them updated",
+		},
+		{
+			Target:       "SyncEmptyDir",
+			DefaultValue: false,
+			Description:  "(mountSync only) Also copy content of empty dir volumes from a running pod of target workload",
+		},
+		{
+			Target:       "LocalDir",
+			DefaultValue: "",
+			Description:  "(cloneTemplate, mountSync or runImage only) Local directory to save env and volume files, default is a temporary directory under ~/.kt",
+		},
+		{
+			Target:       "RunImage",
+			DefaultValue: "",
+			Description:  "Start specified image locally with docker or podman to exchange with, using env and volumes of target workload, expose its container ports by default",
+		},
+		{
+			Target:       "SessionFile",
+			DefaultValue: "",
+			Description:  "Yaml file with targets to exchange in one session, each target has 'resource' and 'expose' fields",
 		},
 	}
 	return flags
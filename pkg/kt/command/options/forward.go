@@ -2,6 +2,31 @@ package options
 
 func ForwardFlags() []OptionConfig {
 	flags := []OptionConfig{
+		{
+			Target:       "Selector",
+			DefaultValue: "",
+			Description:  "Forward all services match the label selector, e.g. 'app=order,tier=backend'",
+		},
+		{
+			Target:       "All",
+			DefaultValue: false,
+			Description:  "Forward all services in the namespace",
+		},
+		{
+			Target:       "BasePort",
+			DefaultValue: 30000,
+			Description:  "Local port to start allocating from, when forwarding multiple services",
+		},
+		{
+			Target:       "PlanFile",
+			DefaultValue: "",
+			Description:  "Write allocated local ports of multiple services to the file in env format, e.g. ./.env",
+		},
+		{
+			Target:       "LoadBalance",
+			DefaultValue: false,
+			Description:  "Distribute new connections across all ready pods of the service, instead of sticking to one pod",
+		},
 	}
 	return flags
 }
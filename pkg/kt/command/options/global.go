@@ -94,6 +94,11 @@ func GlobalFlags() []OptionConfig {
 			Description:  "Run as worker process",
 			Hidden:       true,
 		},
+		{
+			Target:       "SidecarInject",
+			DefaultValue: util.SidecarInjectAuto,
+			Description:  "Service mesh sidecar of shadow and router pod, 'auto', 'istio', 'linkerd' or 'none'. In 'auto' mode, sidecar is injected when target namespace or workload has injection enabled",
+		},
 		{
 			Target:       "PodQuota",
 			DefaultValue: "",
@@ -33,6 +33,21 @@ func MeshFlags() []OptionConfig {
 			DefaultValue: fmt.Sprintf("%s:v%s", util.ImageKtRouter, Store.Version),
 			Description:  "(auto method only) Customize router image",
 		},
+		{
+			Target:       "MountSync",
+			DefaultValue: false,
+			Description:  "Mirror config map, secret and service account token volumes of target workload to local and keep them updated",
+		},
+		{
+			Target:       "SyncEmptyDir",
+			DefaultValue: false,
+			Description:  "(mountSync only) Also copy content of empty dir volumes from a running pod of target workload",
+		},
+		{
+			Target:       "LocalDir",
+			DefaultValue: "",
+			Description:  "(mountSync only) Local directory to save volume files, default is a temporary directory under ~/.kt",
+		},
 	}
 	return flags
 }
@@ -11,22 +11,28 @@ import (
 
 // ConnectOptions ...
 type ConnectOptions struct {
-	Global           bool
-	DisablePodIp     bool
-	DisableTunDevice bool
-	DisableTunRoute  bool
-	ProxyPort        int
-	DnsPort          int
-	DnsCacheTtl      int
-	IncludeIps       string
-	ExcludeIps       string
-	IngressIp        string
-	Mode             string
-	DnsMode          string
-	ShareShadow      bool
-	ClusterDomain    string
-	SkipCleanup      bool
-	IncludeDomains   string
+	Global            bool
+	DisablePodIp      bool
+	DisableTunDevice  bool
+	DisableTunRoute   bool
+	ProxyPort         int
+	DnsPort           int
+	DnsCacheTtl       int
+	DnsCacheSize      int
+	DnsMetricsPort    int
+	IncludeIps        string
+	ExcludeIps        string
+	IngressDns        bool
+	IngressIp         string
+	IngressNamespaces string
+	IngressController string
+	DnsAliases        string
+	Mode              string
+	DnsMode           string
+	ShareShadow       bool
+	ClusterDomain     string
+	SkipCleanup       bool
+	IncludeDomains    string
 }
 
 // ExchangeOptions ...
@@ -34,7 +40,16 @@ type ExchangeOptions struct {
 	Mode             string
 	Expose           string
 	RecoverWaitTime  int
+	ReplicaRatio     int
 	SkipPortChecking bool
+	HealthCheck      string
+	CutoverTimeout   int
+	CloneTemplate    bool
+	MountSync        bool
+	SyncEmptyDir     bool
+	LocalDir         string
+	RunImage         string
+	SessionFile      string
 }
 
 // MeshOptions ...
@@ -44,10 +59,14 @@ type MeshOptions struct {
 	VersionMark      string
 	RouterImage      string
 	SkipPortChecking bool
+	MountSync        bool
+	SyncEmptyDir     bool
+	LocalDir         string
 }
 
 // RecoverOptions ...
 type RecoverOptions struct {
+	DryRun bool
 }
 
 // PreviewOptions ...
@@ -55,10 +74,24 @@ type PreviewOptions struct {
 	External         bool
 	Expose           string
 	SkipPortChecking bool
+	Ingress          string
+	IngressHost      string
+	IngressClass     string
+	TlsSecret        string
+	Gateway          string
+	Auth             string
+	AllowCidr        string
+	TrustedProxy     string
+	AsVersionOf      string
 }
 
 // ForwardOptions ...
 type ForwardOptions struct {
+	Selector    string
+	All         bool
+	BasePort    int
+	PlanFile    string
+	LoadBalance bool
 }
 
 // CleanOptions ...
@@ -66,6 +99,7 @@ type CleanOptions struct {
 	DryRun           bool
 	ThresholdInMinus int64
 	LocalOnly        bool
+	Output           string
 }
 
 // ConfigOptions ...
@@ -77,6 +111,8 @@ type BirdseyeOptions struct {
 	SortBy             string
 	ShowConnector      bool
 	HideNaturalService bool
+	Watch              bool
+	Output             string
 }
 
 // GlobalOptions ...
@@ -99,6 +135,7 @@ type GlobalOptions struct {
 	Context             string
 	PodQuota            string
 	ListenCheck         bool
+	SidecarInject       string
 }
 
 // DaemonOptions cli options
@@ -18,6 +18,51 @@ func PreviewFlags() []OptionConfig {
 			DefaultValue: false,
 			Description:  "Do not check whether specified local ports are listened",
 		},
+		{
+			Target:       "Ingress",
+			DefaultValue: "",
+			Description:  "Also create an 'ingress' or gateway api 'httproute' to access the service from outside cluster",
+		},
+		{
+			Target:       "IngressHost",
+			DefaultValue: "",
+			Description:  "Host template of the ingress or http route, '{service}', '{user}' and '{namespace}' are replaced, e.g. {service}-{user}.preview.example.com",
+		},
+		{
+			Target:       "IngressClass",
+			DefaultValue: "",
+			Description:  "(ingress only) Ingress class name of the ingress, default is using the default ingress class of cluster",
+		},
+		{
+			Target:       "TlsSecret",
+			DefaultValue: "",
+			Description:  "(ingress only) Name of an existing tls secret to serve the host via https",
+		},
+		{
+			Target:       "Gateway",
+			DefaultValue: "",
+			Description:  "(httproute only) Gateway to attach the http route, in [namespace/]name format",
+		},
+		{
+			Target:       "Auth",
+			DefaultValue: "",
+			Description:  "Protect the service with 'basic' auth or bearer 'token', credential is generated and printed",
+		},
+		{
+			Target:       "AllowCidr",
+			DefaultValue: "",
+			Description:  "Only allow clients from specified address ranges, use ',' separated, e.g. 203.0.113.0/24,10.0.0.0/8",
+		},
+		{
+			Target:       "TrustedProxy",
+			DefaultValue: "",
+			Description:  "(with --allowCidr) Address ranges of in-cluster proxies whose X-Forwarded-For header is trusted, e.g. pod cidr of ingress controller",
+		},
+		{
+			Target:       "AsVersionOf",
+			DefaultValue: "",
+			Description:  "Create the service with same ports and labels as an existing service, service name is optional with this option",
+		},
 	}
 	return flags
 }
@@ -2,6 +2,11 @@ package options
 
 func RecoverFlags() []OptionConfig {
 	flags := []OptionConfig{
+		{
+			Target:       "DryRun",
+			DefaultValue: false,
+			Description:  "Only print changes to be made, without applying them",
+		},
 	}
 	return flags
 }
\ No newline at end of file
@@ -23,10 +23,29 @@ type RuntimeStore struct {
 	Router string
 	// Mesh version of mesh pod
 	Mesh string
-	// Origin the origin deployment or service name
+	// Origin the origin workload or service name
 	Origin string
+	// OriginKind kind of the origin workload
+	OriginKind string
 	// Replicas the origin replicas
 	Replicas int32
+	// Exchanged targets exchanged before current one, when exchanging multiple targets in one session
+	Exchanged []ExchangedTarget
 	// Service exposed service name
 	Service string
+	// Ingress name of ingress or http route created for exposed service
+	Ingress string
+	// LocalDir local directory of files synced from shadow pod
+	LocalDir string
+	// LocalContainer name of local container running the image to exchange with
+	LocalContainer string
+	// Sidecar service mesh sidecar to inject into shadow and router pod
+	Sidecar string
+}
+
+// ExchangedTarget context of an exchanged target to recover after command exit
+type ExchangedTarget struct {
+	Origin     string
+	OriginKind string
+	Replicas   int32
 }
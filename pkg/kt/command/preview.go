@@ -9,6 +9,7 @@ import (
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	coreV1 "k8s.io/api/core/v1"
 	"strings"
 )
 
@@ -18,17 +19,24 @@ func NewPreviewCommand() *cobra.Command {
 		Use:   "preview",
 		Short: "Expose a local service to kubernetes cluster",
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
+			if len(args) == 0 && opt.Get().Preview.AsVersionOf == "" {
 				return fmt.Errorf("a service name must be specified")
 			} else if len(args) > 1 {
 				return fmt.Errorf("too many service names are spcified (%s), should be one", strings.Join(args, ",") )
 			}
+			if err := preview.CheckIngressOptions(); err != nil {
+				return err
+			}
 			return general.Prepare()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return Preview("")
+			}
 			return Preview(args[0])
 		},
-		Example: "ktctl preview <service-name> [command options]",
+		Example: "ktctl preview <service-name> [command options]\n" +
+			"ktctl preview [service-name] --asVersionOf <existing-service> [command options]",
 	}
 
 	cmd.SetUsageTemplate(general.UsageTemplate(true))
@@ -49,11 +57,31 @@ func Preview(serviceName string) error {
 		}
 	}
 
-	if err = preview.Expose(serviceName); err != nil {
+	general.DetectSidecar("")
+
+	var origin *coreV1.Service
+	if opt.Get().Preview.AsVersionOf != "" {
+		if origin, err = general.GetServiceByResourceName(opt.Get().Preview.AsVersionOf, opt.Get().Global.Namespace); err != nil {
+			return err
+		}
+		if serviceName == "" {
+			serviceName = preview.VersionServiceName(origin.Name)
+		}
+		log.Info().Msgf("Previewing service %s as a version of %s", serviceName, origin.Name)
+	}
+
+	url, authHint, err := preview.Expose(serviceName, origin)
+	if err != nil {
 		return err
 	}
 	log.Info().Msg("---------------------------------------------------------------")
 	log.Info().Msgf(" Now you can access your local service in cluster by name '%s'", serviceName)
+	if url != "" {
+		log.Info().Msgf(" and from outside cluster by url '%s'", url)
+	}
+	if authHint != "" {
+		log.Info().Msgf(" Access is protected, use %s", authHint)
+	}
 	log.Info().Msg("---------------------------------------------------------------")
 
 	// watch background process, clean the workspace and exit if background process occur exception
@@ -0,0 +1,92 @@
+package preview
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	authUsername          = "kt"
+	authProxyInternalPort = 20000
+)
+
+// buildAuthConfig generate credential for preview service, nil is returned if no access control required
+func buildAuthConfig() (*common.AuthConfig, error) {
+	authType := opt.Get().Preview.Auth
+	if authType == "" && opt.Get().Preview.AllowCidr == "" {
+		return nil, nil
+	}
+	config := &common.AuthConfig{Type: authType}
+	switch authType {
+	case "":
+	case common.AuthTypeBasic:
+		config.Username = authUsername
+		config.Password = randomSecret(12)
+	case common.AuthTypeToken:
+		config.Token = randomSecret(24)
+	default:
+		return nil, fmt.Errorf("invalid auth type '%s', supportted are %s, %s", authType,
+			common.AuthTypeBasic, common.AuthTypeToken)
+	}
+	if opt.Get().Preview.AllowCidr != "" {
+		for _, cidr := range strings.Split(opt.Get().Preview.AllowCidr, ",") {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return nil, fmt.Errorf("invalid cidr '%s' in '--allowCidr'", cidr)
+			}
+			config.AllowCidrs = append(config.AllowCidrs, cidr)
+		}
+	}
+	if opt.Get().Preview.TrustedProxy != "" {
+		for _, cidr := range strings.Split(opt.Get().Preview.TrustedProxy, ",") {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return nil, fmt.Errorf("invalid cidr '%s' in '--trustedProxy'", cidr)
+			}
+			config.TrustedProxies = append(config.TrustedProxies, cidr)
+		}
+	}
+	return config, nil
+}
+
+// allocateInternalPorts pick a shadow pod internal port for each service port, auth proxy listens on the
+// service port and forwards to the internal port, which is listened by reverse tunnel on pod loopback address
+func allocateInternalPorts(servicePorts []int) map[int]int {
+	used := map[int]bool{}
+	for _, p := range servicePorts {
+		used[p] = true
+	}
+	sorted := append([]int{}, servicePorts...)
+	sort.Ints(sorted)
+	internalPorts := map[int]int{}
+	next := authProxyInternalPort
+	for _, p := range sorted {
+		for used[next] {
+			next++
+		}
+		internalPorts[p] = next
+		used[next] = true
+	}
+	return internalPorts
+}
+
+// authHint describe how to access the protected service
+func authHint(config *common.AuthConfig) string {
+	switch config.Type {
+	case common.AuthTypeBasic:
+		return fmt.Sprintf("username '%s' and password '%s'", config.Username, config.Password)
+	case common.AuthTypeToken:
+		return fmt.Sprintf("header 'Authorization: Bearer %s' or url parameter 'kt_token=%s'", config.Token, config.Token)
+	}
+	return fmt.Sprintf("client address in %s", strings.Join(config.AllowCidrs, ","))
+}
+
+func randomSecret(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,17 +1,26 @@
 package preview
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
+	"github.com/alibaba/kt-connect/pkg/kt/command/general"
 	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
 	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
 	"github.com/alibaba/kt-connect/pkg/kt/transmission"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
 	"strings"
 )
 
-// Expose create a new service in cluster
-func Expose(serviceName string) error {
+// Expose create a new service in cluster, url of ingress and hint of access control are returned if created,
+// when origin service is specified, the new service is created as another version of it
+func Expose(serviceName string, origin *coreV1.Service) (string, string, error) {
+	// ingress options are validated in advance, make sure the gateway exists before creating shadow
+	if err := checkGateway(opt.Get().Global.Namespace); err != nil {
+		return "", "", err
+	}
 	version := strings.ToLower(util.RandomString(5))
 	shadowPodName := fmt.Sprintf("%s-kt-%s", serviceName, version)
 	labels := map[string]string{
@@ -22,49 +31,113 @@ func Expose(serviceName string) error {
 		util.KtConfig: fmt.Sprintf("service=%s", serviceName),
 	}
 
-	return exposeLocalService(serviceName, shadowPodName, labels, annotations)
+	return exposeLocalService(serviceName, shadowPodName, labels, annotations, origin)
 }
 
 // exposeLocalService create shadow and expose service if need
-func exposeLocalService(serviceName, shadowPodName string, labels, annotations map[string]string) error {
-
-	envs := make(map[string]string)
-	_, podName, privateKeyPath, err := cluster.Ins().GetOrCreateShadow(shadowPodName, labels, annotations, envs,
-		opt.Get().Preview.Expose, map[int]string{})
+func exposeLocalService(serviceName, shadowPodName string, labels, annotations map[string]string,
+	origin *coreV1.Service) (string, string, error) {
+	authConfig, err := buildAuthConfig()
 	if err != nil {
-		return err
+		return "", "", err
 	}
-	log.Info().Msgf("Created shadow pod %s", podName)
 
 	portPairs := strings.Split(opt.Get().Preview.Expose, ",")
 	ports := make(map[int]int)
+	localPorts := make(map[int]int)
+	var remotePorts []int
+	firstPort := 0
 	for _, exposePort := range portPairs {
-		_, remotePort, err2 := util.ParsePortMapping(exposePort)
+		localPort, remotePort, err2 := util.ParsePortMapping(exposePort)
 		if err2 != nil {
-			return err
+			return "", "", err2
 		}
 		// service port to target port
 		ports[remotePort] = remotePort
+		localPorts[remotePort] = localPort
+		remotePorts = append(remotePorts, remotePort)
+		if firstPort == 0 {
+			firstPort = remotePort
+		}
 	}
-	if _, err = cluster.Ins().CreateService(&cluster.SvcMetaAndSpec{
+
+	svcLabels := map[string]string{}
+	var svcPortNames map[int]string
+	portNameDict := map[int]string{}
+	if origin != nil {
+		portNameDict = general.GetTargetPorts(origin)
+		if port := util.FindInvalidRemotePort(opt.Get().Preview.Expose, portNameDict); port != "" {
+			return "", "", fmt.Errorf("target port %s not exists in service %s", port, origin.Name)
+		}
+		if ports, svcPortNames, err = versionPorts(origin, portNameDict, remotePorts); err != nil {
+			return "", "", err
+		}
+		for svcPort, targetPort := range ports {
+			if targetPort == remotePorts[0] {
+				firstPort = svcPort
+			}
+		}
+		svcLabels = util.MergeMap(svcLabels, origin.Labels)
+	}
+
+	envs := make(map[string]string)
+	var configs map[string]string
+	tunnelPorts := opt.Get().Preview.Expose
+	if authConfig != nil {
+		// auth proxy take over the service ports, local ports are forwarded to internal ports instead
+		internalPorts := allocateInternalPorts(remotePorts)
+		var proxyPorts, tunnelPortPairs []string
+		for _, remotePort := range remotePorts {
+			proxyPorts = append(proxyPorts, fmt.Sprintf("%d:%d", remotePort, internalPorts[remotePort]))
+			tunnelPortPairs = append(tunnelPortPairs, fmt.Sprintf("%d:%d", localPorts[remotePort], internalPorts[remotePort]))
+		}
+		envs[common.EnvVarAuthProxy] = strings.Join(proxyPorts, ",")
+		tunnelPorts = strings.Join(tunnelPortPairs, ",")
+		content, _ := json.Marshal(authConfig)
+		configs = map[string]string{common.AuthConfigFile: string(content)}
+	}
+
+	_, podName, privateKeyPath, err := cluster.Ins().GetOrCreateShadow(shadowPodName, labels, annotations, envs,
+		configs, opt.Get().Preview.Expose, portNameDict, nil)
+	if err != nil {
+		return "", "", err
+	}
+	log.Info().Msgf("Created shadow pod %s", podName)
+
+	svc, err := cluster.Ins().CreateService(&cluster.SvcMetaAndSpec{
 		Meta: &cluster.ResourceMeta{
 			Name:        serviceName,
 			Namespace:   opt.Get().Global.Namespace,
-			Labels:      map[string]string{},
+			Labels:      svcLabels,
 			Annotations: map[string]string{},
 		},
 		External:  opt.Get().Preview.External,
 		Ports:     ports,
+		PortNames: svcPortNames,
 		Selectors: labels,
-	}); err != nil {
-		return err
+		// client address is required by cidr checking
+		PreserveSource: authConfig != nil && len(authConfig.AllowCidrs) > 0,
+	})
+	if err != nil {
+		return "", "", err
 	}
 	opt.Store.Service = serviceName
 
-	if _, err = transmission.ForwardPodToLocal(opt.Get().Preview.Expose, podName, privateKeyPath); err != nil {
-		return err
+	hint := ""
+	if authConfig != nil {
+		if _, err = transmission.ForwardPodLoopbackToLocal(tunnelPorts, podName, privateKeyPath); err != nil {
+			return "", "", err
+		}
+		hint = authHint(authConfig)
+	} else if _, err = transmission.ForwardPodToLocal(tunnelPorts, podName, privateKeyPath); err != nil {
+		return "", "", err
 	}
 
 	log.Info().Msgf("Forward remote %s:%v -> 127.0.0.1:%v", podName, opt.Get().Preview.Expose, opt.Get().Preview.Expose)
-	return nil
+
+	if opt.Get().Preview.Ingress != "" {
+		url, err2 := exposeIngress(svc, firstPort)
+		return url, hint, err2
+	}
+	return "", hint, nil
 }
@@ -0,0 +1,116 @@
+package preview
+
+import (
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"regexp"
+	"strings"
+)
+
+var invalidDnsLabelChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// CheckIngressOptions validate ingress related options, before any resource is created
+func CheckIngressOptions() error {
+	mode := opt.Get().Preview.Ingress
+	if mode == "" {
+		return nil
+	}
+	if mode != util.PreviewIngress && mode != util.PreviewHttpRoute {
+		return fmt.Errorf("invalid ingress type '%s', supportted are %s, %s", mode,
+			util.PreviewIngress, util.PreviewHttpRoute)
+	}
+	if opt.Get().Preview.IngressHost == "" {
+		return fmt.Errorf("'--ingressHost' is required to create %s", mode)
+	}
+	if mode == util.PreviewHttpRoute {
+		if gateway, _ := parseGateway(opt.Get().Preview.Gateway, ""); gateway == "" {
+			return fmt.Errorf("'--gateway' is required to create %s", mode)
+		}
+	}
+	return nil
+}
+
+// checkGateway make sure gateway of http route exists, before any resource is created
+func checkGateway(namespace string) error {
+	if opt.Get().Preview.Ingress != util.PreviewHttpRoute {
+		return nil
+	}
+	gateway, gatewayNamespace := parseGateway(opt.Get().Preview.Gateway, namespace)
+	_, err := cluster.Ins().GetGateway(gateway, gatewayNamespace)
+	return err
+}
+
+// exposeIngress create ingress or http route routing to preview service, return url to access it
+func exposeIngress(svc *coreV1.Service, port int) (string, error) {
+	mode := opt.Get().Preview.Ingress
+	host := renderIngressHost(opt.Get().Preview.IngressHost, svc.Name, svc.Namespace, util.GetLocalUserName())
+
+	scheme := "http"
+	if mode == util.PreviewIngress {
+		if _, err := cluster.Ins().CreateIngress(svc.Name, svc, host, port,
+			opt.Get().Preview.IngressClass, opt.Get().Preview.TlsSecret); err != nil {
+			return "", err
+		}
+		if opt.Get().Preview.TlsSecret != "" {
+			scheme = "https"
+		}
+	} else {
+		gateway, gatewayNamespace := parseGateway(opt.Get().Preview.Gateway, svc.Namespace)
+		if opt.Get().Preview.TlsSecret != "" {
+			log.Warn().Msgf("Option '--tlsSecret' is ignored, tls of http route is decided by gateway")
+		}
+		gw, err := cluster.Ins().GetGateway(gateway, gatewayNamespace)
+		if err != nil {
+			return "", err
+		}
+		if _, err = cluster.Ins().CreateHttpRoute(svc.Name, svc, host, port, gateway, gatewayNamespace); err != nil {
+			return "", err
+		}
+		if hasHttpsListener(gw) {
+			scheme = "https"
+		}
+	}
+	// record context inorder to remove after command exit
+	opt.Store.Ingress = svc.Name
+	log.Info().Msgf("Created %s %s for host %s", mode, svc.Name, host)
+	return fmt.Sprintf("%s://%s", scheme, host), nil
+}
+
+// renderIngressHost replace placeholders in host template, values are converted to valid dns labels
+func renderIngressHost(template, service, namespace, user string) string {
+	return strings.NewReplacer(
+		"{service}", toDnsLabel(service),
+		"{namespace}", toDnsLabel(namespace),
+		"{user}", toDnsLabel(user),
+	).Replace(template)
+}
+
+func toDnsLabel(value string) string {
+	label := strings.Trim(invalidDnsLabelChars.ReplaceAllString(strings.ToLower(value), "-"), "-")
+	if len(label) > 63 {
+		label = strings.TrimRight(label[:63], "-")
+	}
+	return label
+}
+
+func parseGateway(gateway, defaultNamespace string) (string, string) {
+	if parts := strings.SplitN(gateway, "/", 2); len(parts) == 2 {
+		return parts[1], parts[0]
+	}
+	return gateway, defaultNamespace
+}
+
+func hasHttpsListener(gateway *unstructured.Unstructured) bool {
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	for _, l := range listeners {
+		if listener, ok := l.(map[string]any); ok && listener["protocol"] == "HTTPS" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package preview
+
+import (
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_renderIngressHost(t *testing.T) {
+	require.Equal(t, "order-tom.preview.example.com",
+		renderIngressHost("{service}-{user}.preview.example.com", "order", "default", "tom"))
+	require.Equal(t, "order.dev-team.example.com",
+		renderIngressHost("{service}.{namespace}.example.com", "order", "dev-team", "tom"))
+	require.Equal(t, "order-domain-tom-lee.preview.example.com",
+		renderIngressHost("{service}-{user}.preview.example.com", "order", "default", "DOMAIN\\Tom.Lee"))
+}
+
+func Test_parseGateway(t *testing.T) {
+	name, namespace := parseGateway("public", "default")
+	require.Equal(t, "public", name)
+	require.Equal(t, "default", namespace)
+	name, namespace = parseGateway("gateway-system/public", "default")
+	require.Equal(t, "public", name)
+	require.Equal(t, "gateway-system", namespace)
+}
+
+func TestCheckIngressOptions(t *testing.T) {
+	preview := opt.Get().Preview
+	defer func() { opt.Get().Preview = preview }()
+	opt.Get().Preview = &opt.PreviewOptions{}
+	require.Nil(t, CheckIngressOptions())
+	opt.Get().Preview.Ingress = "route"
+	require.Error(t, CheckIngressOptions())
+	opt.Get().Preview.Ingress = util.PreviewIngress
+	require.Error(t, CheckIngressOptions())
+	opt.Get().Preview.IngressHost = "{service}.preview.example.com"
+	require.Nil(t, CheckIngressOptions())
+	opt.Get().Preview.Ingress = util.PreviewHttpRoute
+	require.Error(t, CheckIngressOptions())
+	opt.Get().Preview.Gateway = "infra/public"
+	require.Nil(t, CheckIngressOptions())
+}
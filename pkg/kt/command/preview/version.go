@@ -0,0 +1,57 @@
+package preview
+
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// VersionServiceName derive name of preview service from the original service and local user name
+func VersionServiceName(origin string) string {
+	return toDnsLabel(fmt.Sprintf("%s-%s", origin, util.GetLocalUserName()))
+}
+
+// versionPorts get ports of original service whose target port is exposed, and names of these ports,
+// so that the preview service could be accessed in the same way as the original one.
+// Shadow pod only serves exposed ports via ssh tunnel, thus ports of non-tcp protocol are skipped
+func versionPorts(origin *coreV1.Service, targetPorts map[int]string,
+	exposedPorts []int) (map[int]int, map[int]string, error) {
+	exposed := map[int]bool{}
+	for _, p := range exposedPorts {
+		exposed[p] = true
+	}
+	ports := map[int]int{}
+	names := map[int]string{}
+	served := map[int]bool{}
+	for _, p := range origin.Spec.Ports {
+		targetPort := p.TargetPort.IntValue()
+		if p.TargetPort.Type == intstr.String {
+			targetPort = 0
+			for port, name := range targetPorts {
+				if name == p.TargetPort.StrVal {
+					targetPort = port
+				}
+			}
+		}
+		if !exposed[targetPort] {
+			continue
+		}
+		if p.Protocol != "" && p.Protocol != coreV1.ProtocolTCP {
+			log.Warn().Msgf("Port %d of service %s is %s, skipped", p.Port, origin.Name, p.Protocol)
+			continue
+		}
+		ports[int(p.Port)] = targetPort
+		if p.Name != "" {
+			names[int(p.Port)] = p.Name
+		}
+		served[targetPort] = true
+	}
+	for _, p := range exposedPorts {
+		if !served[p] {
+			return nil, nil, fmt.Errorf("target port %d of service %s is not a tcp port, which cannot be previewed", p, origin.Name)
+		}
+	}
+	return ports, names, nil
+}
@@ -0,0 +1,38 @@
+package preview
+
+import (
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"testing"
+)
+
+func Test_versionPorts(t *testing.T) {
+	origin := &coreV1.Service{
+		Spec: coreV1.ServiceSpec{
+			Ports: []coreV1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)},
+				{Name: "grpc", Port: 9090, TargetPort: intstr.FromString("grpc-port")},
+				{Name: "metrics", Port: 9100, TargetPort: intstr.FromInt(9100)},
+				{Name: "syslog", Port: 514, TargetPort: intstr.FromInt(1514), Protocol: coreV1.ProtocolUDP},
+			},
+		},
+	}
+	targetPorts := map[int]string{8080: "http-8080", 9091: "grpc-port", 9100: "kt-9100", 1514: "kt-1514"}
+	ports, names, err := versionPorts(origin, targetPorts, []int{8080, 9091})
+	require.Nil(t, err)
+	require.Equal(t, map[int]int{80: 8080, 9090: 9091}, ports)
+	require.Equal(t, map[int]string{80: "http", 9090: "grpc"}, names)
+
+	// udp port cannot be served via ssh tunnel
+	_, _, err = versionPorts(origin, targetPorts, []int{8080, 1514})
+	require.NotNil(t, err)
+
+	// udp port sharing target port with a tcp one is skipped
+	origin.Spec.Ports = append(origin.Spec.Ports,
+		coreV1.ServicePort{Name: "dns-udp", Port: 53, TargetPort: intstr.FromInt(8080), Protocol: coreV1.ProtocolUDP})
+	ports, names, err = versionPorts(origin, targetPorts, []int{8080})
+	require.Nil(t, err)
+	require.Equal(t, map[int]int{80: 8080}, ports)
+	require.Equal(t, map[int]string{80: "http"}, names)
+}
@@ -57,6 +57,9 @@ func Recover(serviceName string) error {
 	}
 	targetDeployment, targetPod, targetRole := fetchTargetRole(apps, pods)
 	log.Debug().Msgf("Target role is: %s", targetRole)
+	if opt.Get().Recover.DryRun {
+		log.Info().Msgf("Running in dry run mode, following changes would NOT be applied")
+	}
 
 	if svc.Annotations == nil {
 		// put an empty map to avoid npe
@@ -73,15 +76,23 @@ func Recover(serviceName string) error {
 
 	needUnlock := checkAndMarkUnlock(serviceName, svc)
 
-	if originSelector, exists := svc.Annotations[util.KtSelector]; exists {
+	if sliceName, exists := svc.Annotations[util.KtEndpointSlice]; exists {
+		log.Info().Msgf("Service %s is exchanged by endpoint slice, recovering", serviceName)
+		delete(svc.Annotations, util.KtEndpointSlice)
+		return recover.HandleExchangedByEndpointService(svc, sliceName)
+	} else if originSelector, exists := svc.Annotations[util.KtSelector]; exists {
 		var selector map[string]string
 		if err = json.Unmarshal([]byte(originSelector), &selector); err != nil {
 			return fmt.Errorf("service %s has %s annotation, but selecting nothing", serviceName, util.KtSelector)
 		}
 		log.Debug().Msgf("Recovering selector to %v", selector)
+		canary := svc.Spec.Selector[util.KtCanary]
 		svc.Spec.Selector = selector
 		delete(svc.Annotations, util.KtSelector)
-		if targetRole == util.RoleRouter {
+		if canary != "" {
+			log.Info().Msgf("Service %s is exchanged by canary, recovering", serviceName)
+			return recover.HandleExchangedByCanaryService(svc, canary)
+		} else if targetRole == util.RoleRouter {
 			log.Info().Msgf("Service %s is meshed, recovering", serviceName)
 			return recover.HandleMeshedByAutoService(svc, targetDeployment, targetPod)
 		} else if targetRole == util.RoleExchangeShadow {
@@ -0,0 +1,130 @@
+package recover
+
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/command/general"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"strings"
+)
+
+const commandName = "recover"
+
+// updateService apply selector and annotation changes of service, or only print them in dry run mode
+func updateService(svc *coreV1.Service) error {
+	var changes []string
+	if origin, err := cluster.Ins().GetService(svc.Name, svc.Namespace); err == nil {
+		changes = append(diffMap("selector", origin.Spec.Selector, svc.Spec.Selector),
+			diffMap("annotations", origin.Annotations, svc.Annotations)...)
+	}
+	if opt.Get().Recover.DryRun {
+		printChange("Service", svc.Name, svc.Namespace, "update", changes)
+		return nil
+	}
+	if _, err := cluster.Ins().UpdateService(svc); err != nil {
+		return err
+	}
+	general.RecordChange(commandName, general.ObjectRef("Service", svc), "UpdateService", strings.Join(changes, "; "))
+	return nil
+}
+
+// removeResource delete specified resource, or only print it in dry run mode
+func removeResource(kind, name, namespace string) error {
+	if opt.Get().Recover.DryRun {
+		printChange(kind, name, namespace, "delete", nil)
+		return nil
+	}
+	log.Info().Msgf("Deleting %s %s", strings.ToLower(kind), name)
+	ref := general.GetObjectRef(kind, name, namespace)
+	var err error
+	switch kind {
+	case "Pod":
+		err = cluster.Ins().RemovePod(name, namespace)
+	case "Deployment":
+		err = cluster.Ins().RemoveDeployment(name, namespace)
+	case "Service":
+		err = cluster.Ins().RemoveService(name, namespace)
+	case "EndpointSlice":
+		err = cluster.Ins().RemoveEndpointSlice(name, namespace)
+	default:
+		err = fmt.Errorf("unsupported resource kind %s", kind)
+	}
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to remove %s %s", strings.ToLower(kind), name)
+		return err
+	}
+	general.RecordChange(commandName, ref, "Delete"+kind, "")
+	return nil
+}
+
+// scaleWorkload restore replicas of workload, or only print the change in dry run mode
+func scaleWorkload(kind, name, namespace string, replicas int32) error {
+	change := fmt.Sprintf("replicas: %d", replicas)
+	ref := general.ObjectRef(kind, &metav1.ObjectMeta{Name: name, Namespace: namespace})
+	if workload, err := cluster.Ins().GetWorkload(kind, name, namespace); err == nil {
+		change = fmt.Sprintf("replicas: %d -> %d", workload.Replicas, replicas)
+		ref.UID = workload.UID
+	}
+	if opt.Get().Recover.DryRun {
+		printChange(kind, name, namespace, "scale", []string{change})
+		return nil
+	}
+	if err := cluster.Ins().ScaleWorkload(kind, name, namespace, replicas); err != nil {
+		return err
+	}
+	general.RecordChange(commandName, ref, "ScaleWorkload", change)
+	return nil
+}
+
+// removePodLabel remove label from pod, or only print the change in dry run mode
+func removePodLabel(pod *coreV1.Pod, key string) error {
+	changes := diffMap("labels", map[string]string{key: pod.Labels[key]}, map[string]string{})
+	if opt.Get().Recover.DryRun {
+		printChange("Pod", pod.Name, pod.Namespace, "update", changes)
+		return nil
+	}
+	log.Info().Msgf("Removing %s label of pod %s", key, pod.Name)
+	if err := cluster.Ins().RemovePodLabel(pod.Name, pod.Namespace, key); err != nil {
+		return err
+	}
+	general.RecordChange(commandName, general.ObjectRef("Pod", pod), "RemovePodLabel", strings.Join(changes, "; "))
+	return nil
+}
+
+func printChange(kind, name, namespace, action string, changes []string) {
+	log.Info().Msgf("[dry-run] %s %s %s/%s", action, kind, namespace, name)
+	for _, c := range changes {
+		log.Info().Msgf("    %s", c)
+	}
+}
+
+// diffMap list the removed, changed and added keys of a map field, sorted by key
+func diffMap(field string, before, after map[string]string) []string {
+	keys := make([]string, 0)
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, exists := before[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	changes := make([]string, 0)
+	for _, k := range keys {
+		oldValue, hasOld := before[k]
+		newValue, hasNew := after[k]
+		if hasOld && !hasNew {
+			changes = append(changes, fmt.Sprintf("%s.%s: - %s", field, k, oldValue))
+		} else if !hasOld && hasNew {
+			changes = append(changes, fmt.Sprintf("%s.%s: + %s", field, k, newValue))
+		} else if oldValue != newValue {
+			changes = append(changes, fmt.Sprintf("%s.%s: %s -> %s", field, k, oldValue, newValue))
+		}
+	}
+	return changes
+}
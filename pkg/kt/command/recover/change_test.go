@@ -0,0 +1,17 @@
+package recover
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_diffMap(t *testing.T) {
+	before := map[string]string{"app": "order", "kt-role": "exchange", "kt-selector": "{\"app\":\"order\"}"}
+	after := map[string]string{"app": "order", "version": "v1", "kt-role": "router"}
+	require.Equal(t, []string{
+		"selector.kt-role: exchange -> router",
+		"selector.kt-selector: - {\"app\":\"order\"}",
+		"selector.version: + v1",
+	}, diffMap("selector", before, after))
+	require.Empty(t, diffMap("annotations", nil, map[string]string{}))
+}
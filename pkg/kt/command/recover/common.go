@@ -2,9 +2,9 @@ package recover
 
 import (
 	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
 	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
-	"github.com/rs/zerolog/log"
 	appV1 "k8s.io/api/apps/v1"
 	coreV1 "k8s.io/api/core/v1"
 	"strconv"
@@ -14,30 +14,31 @@ import (
 
 
 func UnlockServiceOnly(svc *coreV1.Service) error {
-	_, err := cluster.Ins().UpdateService(svc)
-	return err
+	return updateService(svc)
 }
 
 func HandleExchangedByScaleService(svc *coreV1.Service, deployment *appV1.Deployment, pod *coreV1.Pod) error {
-	if _, err := cluster.Ins().UpdateService(svc); err != nil {
+	if err := updateService(svc); err != nil {
 		return err
 	}
 	config := make(map[string]string)
 	if pod != nil && pod.Annotations != nil {
 		config = util.String2Map(pod.Annotations[util.KtConfig])
-		log.Info().Msgf("Deleting shadow pod %s", pod.Name)
-		_ = cluster.Ins().RemovePod(pod.Name, pod.Namespace)
+		_ = removeResource("Pod", pod.Name, pod.Namespace)
 	}
 	if len(config) == 0 && deployment != nil && deployment.Annotations != nil {
 		config = util.String2Map(deployment.Annotations[util.KtConfig])
-		log.Info().Msgf("Deleting shadow deployment %s", deployment.Name)
-		_ = cluster.Ins().RemoveDeployment(deployment.Name, deployment.Namespace)
+		_ = removeResource("Deployment", deployment.Name, deployment.Namespace)
 	}
 	replica, _ := strconv.ParseInt(config["replicas"], 10, 32)
 	app := config["app"]
+	kind := config["kind"]
+	if kind == "" {
+		// exchanged by elder version ktctl
+		kind = util.WorkloadDeployment
+	}
 	if replica > 0 && app != "" {
-		originReplica := int32(replica)
-		return cluster.Ins().ScaleTo(app, svc.Namespace, &originReplica)
+		return scaleWorkload(kind, app, svc.Namespace, int32(replica))
 	}
 	return nil
 }
@@ -50,6 +51,44 @@ func HandleExchangedBySelectorService(svc *coreV1.Service, deployment *appV1.Dep
 	return HandleServiceSelectorAndRemotePods(svc, deployment, pod)
 }
 
+func HandleExchangedByCanaryService(svc *coreV1.Service, canary string) error {
+	if err := updateService(svc); err != nil {
+		return err
+	}
+	pods, err := cluster.Ins().GetPodsByLabel(map[string]string{util.KtCanary: canary}, svc.Namespace)
+	if err != nil {
+		return err
+	}
+	var failures []string
+	for _, pod := range pods.Items {
+		if pod.Labels[util.KtRole] == util.RoleExchangeShadow {
+			err = removeResource("Pod", pod.Name, pod.Namespace)
+		} else if pod.DeletionTimestamp == nil {
+			err = removePodLabel(&pod, util.KtCanary)
+		} else {
+			continue
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (%s)", pod.Name, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to recover canary pods: %s", strings.Join(failures, ", "))
+	}
+	return nil
+}
+
+func HandleExchangedByEndpointService(svc *coreV1.Service, sliceName string) error {
+	_ = removeResource("EndpointSlice", sliceName, svc.Namespace)
+	// updating service makes kubernetes re-sync the drained endpoint slices
+	if err := updateService(svc); err != nil {
+		return err
+	}
+	// endpoint slice has the same name as shadow pod
+	_ = removeResource("Pod", sliceName, svc.Namespace)
+	return nil
+}
+
 func HandleMeshedByAutoService(svc *coreV1.Service, deployment *appV1.Deployment, pod *coreV1.Pod) error {
 	// shadow pods, shadow deployments, shadow services
 	if deployment != nil {
@@ -58,18 +97,14 @@ func HandleMeshedByAutoService(svc *coreV1.Service, deployment *appV1.Deployment
 		return fmt.Errorf("service '%s' is meshed without selecting a router pod, cannot auto recover", svc.Name)
 	}
 	// must delete router pod first, to avoid origin service recover by mesh watcher
-	log.Info().Msgf("Deleting route pod %s", pod.Name)
-	if err := cluster.Ins().RemovePod(pod.Name, pod.Namespace); err != nil {
-		log.Debug().Err(err).Msgf("Failed to remove pod %s", pod.Name)
+	_ = removeResource("Pod", pod.Name, pod.Namespace)
+	if !opt.Get().Recover.DryRun {
+		time.Sleep(1 * time.Second)
 	}
-	time.Sleep(1 * time.Second)
-	if _, err := cluster.Ins().UpdateService(svc); err != nil {
+	if err := updateService(svc); err != nil {
 		return err
 	}
-	log.Info().Msgf("Deleting stuntman service %s", svc.Name + util.StuntmanServiceSuffix)
-	if err := cluster.Ins().RemoveService(svc.Name + util.StuntmanServiceSuffix, svc.Namespace); err != nil {
-		log.Debug().Err(err).Msgf("Failed to remove service %s", svc.Name)
-	}
+	_ = removeResource("Service", svc.Name + util.StuntmanServiceSuffix, svc.Namespace)
 	shadowLabels := map[string]string{
 		util.ControlBy: util.KubernetesToolkit,
 		util.KtRole:    util.RoleMeshShadow,
@@ -78,10 +113,7 @@ func HandleMeshedByAutoService(svc *coreV1.Service, deployment *appV1.Deployment
 	if apps, err := cluster.Ins().GetDeploymentsByLabel(shadowLabels, svc.Namespace); err == nil {
 		for _, shadowApp := range apps.Items {
 			if strings.HasPrefix(shadowApp.Name, svc.Name + util.MeshPodInfix) {
-				log.Info().Msgf("Deleting shadow deployment %s", shadowApp.Name)
-				if err2 := cluster.Ins().RemoveDeployment(shadowApp.Name, shadowApp.Namespace); err2 != nil {
-					log.Debug().Err(err2).Msgf("Failed to remove deployment %s", shadowApp.Name)
-				}
+				_ = removeResource("Deployment", shadowApp.Name, shadowApp.Namespace)
 				shadowSvcNames = append(shadowSvcNames, shadowApp.Name)
 			}
 		}
@@ -89,34 +121,26 @@ func HandleMeshedByAutoService(svc *coreV1.Service, deployment *appV1.Deployment
 	if pods, err := cluster.Ins().GetPodsByLabel(shadowLabels, svc.Namespace); err == nil {
 		for _, shadowPod := range pods.Items {
 			if strings.HasPrefix(shadowPod.Name, svc.Name + util.MeshPodInfix) && shadowPod.DeletionTimestamp == nil {
-				log.Info().Msgf("Deleting shadow pod %s", shadowPod.Name)
-				if err2 := cluster.Ins().RemovePod(shadowPod.Name, shadowPod.Namespace); err2 != nil {
-					log.Debug().Err(err2).Msgf("Failed to remove pod %s", pod.Name)
-				}
+				_ = removeResource("Pod", shadowPod.Name, shadowPod.Namespace)
 				shadowSvcNames = append(shadowSvcNames, shadowPod.Name)
 			}
 		}
 	}
 	for _, shadowSvc := range shadowSvcNames {
-		log.Info().Msgf("Deleting shadow service %s", shadowSvc)
-		if err := cluster.Ins().RemoveService(shadowSvc, svc.Namespace); err != nil {
-			log.Debug().Err(err).Msgf("Failed to remove service %s", svc.Name)
-		}
+		_ = removeResource("Service", shadowSvc, svc.Namespace)
 	}
 	return nil
 }
 
 func HandleServiceSelectorAndRemotePods(svc *coreV1.Service, deployment *appV1.Deployment, pod *coreV1.Pod) error {
-	if _, err := cluster.Ins().UpdateService(svc); err != nil {
+	if err := updateService(svc); err != nil {
 		return err
 	}
 	if deployment != nil {
-		log.Info().Msgf("Deleting shadow deployment %s", deployment.Name)
-		_ = cluster.Ins().RemoveDeployment(deployment.Name, deployment.Namespace)
+		_ = removeResource("Deployment", deployment.Name, deployment.Namespace)
 	}
 	if pod != nil {
-		log.Info().Msgf("Deleting shadow pod %s", pod.Name)
-		_ = cluster.Ins().RemovePod(pod.Name, pod.Namespace)
+		_ = removeResource("Pod", pod.Name, pod.Namespace)
 	}
 	return nil
 }
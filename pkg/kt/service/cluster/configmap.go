@@ -47,7 +47,7 @@ func (k *Kubernetes) UpdateConfigMapHeartBeat(name, namespace string) {
 }
 
 func (k *Kubernetes) createConfigMapWithSshKey(labels map[string]string, sshcm string, namespace string,
-	generator *util.SSHGenerator) (configMap *coreV1.ConfigMap, err error) {
+	generator *util.SSHGenerator, configs map[string]string) (configMap *coreV1.ConfigMap, err error) {
 	SetupHeartBeat(sshcm, namespace, k.UpdateConfigMapHeartBeat)
 
 	labels = util.MergeMap(labels, map[string]string{util.ControlBy: util.KubernetesToolkit})
@@ -58,9 +58,36 @@ func (k *Kubernetes) createConfigMapWithSshKey(labels map[string]string, sshcm s
 			Labels:      labels,
 			Annotations: map[string]string{util.KtLastHeartBeat: util.GetTimestamp()},
 		},
-		Data: map[string]string{
+		Data: util.MergeMap(configs, map[string]string{
 			util.SshAuthKey:        string(generator.PublicKey),
 			util.SshAuthPrivateKey: string(generator.PrivateKey),
-		},
+		}),
 	}, metav1.CreateOptions{})
 }
+
+// WatchConfigMap watch config map changes
+func (k *Kubernetes) WatchConfigMap(name, namespace string, fAdd, fDel, fMod func(*coreV1.ConfigMap)) {
+	k.watchResource(k.Clientset.CoreV1().RESTClient(), name, namespace, "configmaps", &coreV1.ConfigMap{},
+		func(obj any) {
+			handleConfigMapEvent(obj, "added", fAdd)
+		},
+		func(obj any) {
+			handleConfigMapEvent(obj, "deleted", fDel)
+		},
+		func(obj any) {
+			handleConfigMapEvent(obj, "modified", fMod)
+		},
+	)
+}
+
+func handleConfigMapEvent(obj any, status string, f func(*coreV1.ConfigMap)) {
+	switch obj.(type) {
+	case *coreV1.ConfigMap:
+		if f != nil {
+			log.Debug().Msgf("Config map %s %s", obj.(*coreV1.ConfigMap).Name, status)
+			f(obj.(*coreV1.ConfigMap))
+		}
+	default:
+		// ignore
+	}
+}
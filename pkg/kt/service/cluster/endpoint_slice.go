@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"context"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	discoveryV1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labelApi "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net"
+)
+
+// GetEndpointSlice get endpoint slice by name
+func (k *Kubernetes) GetEndpointSlice(name, namespace string) (*discoveryV1.EndpointSlice, error) {
+	return k.Clientset.DiscoveryV1().EndpointSlices(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// GetEndpointSlicesByLabel get endpoint slices by label
+func (k *Kubernetes) GetEndpointSlicesByLabel(labels map[string]string, namespace string) (*discoveryV1.EndpointSliceList, error) {
+	return k.Clientset.DiscoveryV1().EndpointSlices(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector:  labelApi.SelectorFromSet(labels).String(),
+		TimeoutSeconds: &apiTimeout,
+	})
+}
+
+// CreateEndpointSlice create an endpoint slice of service pointing to specified pod
+func (k *Kubernetes) CreateEndpointSlice(name string, svc *coreV1.Service, pod *coreV1.Pod, targetPorts map[int]string) (*discoveryV1.EndpointSlice, error) {
+	return k.Clientset.DiscoveryV1().EndpointSlices(svc.Namespace).
+		Create(context.TODO(), createEndpointSlice(name, svc, pod, targetPorts), metav1.CreateOptions{})
+}
+
+// UpdateEndpointSlice ...
+func (k *Kubernetes) UpdateEndpointSlice(slice *discoveryV1.EndpointSlice) (*discoveryV1.EndpointSlice, error) {
+	return k.Clientset.DiscoveryV1().EndpointSlices(slice.Namespace).Update(context.TODO(), slice, metav1.UpdateOptions{})
+}
+
+// RemoveEndpointSlice remove endpoint slice
+func (k *Kubernetes) RemoveEndpointSlice(name, namespace string) error {
+	return k.Clientset.DiscoveryV1().EndpointSlices(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// WatchEndpointSlice watch endpoint slices in specified namespace
+func (k *Kubernetes) WatchEndpointSlice(namespace string, fAdd, fDel, fMod func(*discoveryV1.EndpointSlice)) {
+	k.watchResource(k.Clientset.DiscoveryV1().RESTClient(), "", namespace, "endpointslices", &discoveryV1.EndpointSlice{},
+		func(obj any) {
+			handleEndpointSliceEvent(obj, "added", fAdd)
+		},
+		func(obj any) {
+			handleEndpointSliceEvent(obj, "deleted", fDel)
+		},
+		func(obj any) {
+			handleEndpointSliceEvent(obj, "modified", fMod)
+		},
+	)
+}
+
+func handleEndpointSliceEvent(obj any, status string, f func(*discoveryV1.EndpointSlice)) {
+	switch obj.(type) {
+	case *discoveryV1.EndpointSlice:
+		if f != nil {
+			log.Debug().Msgf("Endpoint slice %s %s", obj.(*discoveryV1.EndpointSlice).Name, status)
+			f(obj.(*discoveryV1.EndpointSlice))
+		}
+	default:
+		// ignore
+	}
+}
+
+func createEndpointSlice(name string, svc *coreV1.Service, pod *coreV1.Pod, targetPorts map[int]string) *discoveryV1.EndpointSlice {
+	addressType := discoveryV1.AddressTypeIPv4
+	if ip := net.ParseIP(pod.Status.PodIP); ip != nil && ip.To4() == nil {
+		addressType = discoveryV1.AddressTypeIPv6
+	}
+	var ports []discoveryV1.EndpointPort
+	for _, p := range svc.Spec.Ports {
+		port := int32(p.TargetPort.IntValue())
+		if p.TargetPort.Type == intstr.String {
+			for number, portName := range targetPorts {
+				if portName == p.TargetPort.StrVal {
+					port = int32(number)
+				}
+			}
+		}
+		if port == 0 {
+			port = p.Port
+		}
+		portName := p.Name
+		protocol := p.Protocol
+		if protocol == "" {
+			protocol = coreV1.ProtocolTCP
+		}
+		ports = append(ports, discoveryV1.EndpointPort{
+			Name:     &portName,
+			Protocol: &protocol,
+			Port:     &port,
+		})
+	}
+	ready := true
+	nodeName := pod.Spec.NodeName
+	return &discoveryV1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: svc.Namespace,
+			Labels: map[string]string{
+				util.ControlBy:                util.KubernetesToolkit,
+				util.EndpointSliceServiceName: svc.Name,
+				util.EndpointSliceManagedBy:   util.EndpointSliceManager,
+			},
+		},
+		AddressType: addressType,
+		Endpoints: []discoveryV1.Endpoint{
+			{
+				Addresses:  []string{pod.Status.PodIP},
+				Conditions: discoveryV1.EndpointConditions{Ready: &ready},
+				NodeName:   &nodeName,
+				TargetRef: &coreV1.ObjectReference{
+					Kind:      "Pod",
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+					UID:       pod.UID,
+				},
+			},
+		},
+		Ports: ports,
+	}
+}
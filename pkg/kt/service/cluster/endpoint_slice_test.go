@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	discoveryV1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func TestKubernetes_CreateEndpointSlice(t *testing.T) {
+	svc := &coreV1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc-name", Namespace: "default"},
+		Spec: coreV1.ServiceSpec{
+			Ports: []coreV1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)},
+				{Name: "grpc", Port: 90, TargetPort: intstr.FromString("grpc"), Protocol: coreV1.ProtocolTCP},
+			},
+		},
+	}
+	pod := &coreV1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc-name-kt-exchange-abcde", Namespace: "default"},
+		Spec:       coreV1.PodSpec{NodeName: "node-1"},
+		Status:     coreV1.PodStatus{PodIP: "10.0.0.5"},
+	}
+	k := &Kubernetes{
+		Clientset: testclient.NewSimpleClientset(),
+	}
+	slice, err := k.CreateEndpointSlice(pod.Name, svc, pod, map[int]string{8080: "kt-8080", 9090: "grpc"})
+	require.Nil(t, err)
+	require.Equal(t, "svc-name", slice.Labels[util.EndpointSliceServiceName])
+	require.Equal(t, util.EndpointSliceManager, slice.Labels[util.EndpointSliceManagedBy])
+	require.Equal(t, discoveryV1.AddressTypeIPv4, slice.AddressType)
+	require.Equal(t, []string{"10.0.0.5"}, slice.Endpoints[0].Addresses)
+	require.True(t, *slice.Endpoints[0].Conditions.Ready)
+	require.Equal(t, 2, len(slice.Ports))
+	require.Equal(t, "http", *slice.Ports[0].Name)
+	require.Equal(t, int32(8080), *slice.Ports[0].Port)
+	require.Equal(t, coreV1.ProtocolTCP, *slice.Ports[0].Protocol)
+	require.Equal(t, "grpc", *slice.Ports[1].Name)
+	require.Equal(t, int32(9090), *slice.Ports[1].Port)
+}
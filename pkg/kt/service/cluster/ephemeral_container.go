@@ -23,7 +23,7 @@ func (k *Kubernetes) AddEphemeralContainer(containerName string, name string,
 	if err != nil {
 		return "", err
 	}
-	configMap, err2 := k.createConfigMapWithSshKey(map[string]string{}, name, opt.Get().Global.Namespace, generator)
+	configMap, err2 := k.createConfigMapWithSshKey(map[string]string{}, name, opt.Get().Global.Namespace, generator, nil)
 
 	if err2 != nil {
 		return "", fmt.Errorf("found shadow pod but no configMap. Please delete the pod %s", pod.Name)
@@ -32,6 +32,7 @@ func (k *Kubernetes) AddEphemeralContainer(containerName string, name string,
 	err = util.WritePrivateKey(generator.PrivateKeyPath, []byte(configMap.Data[util.SshAuthPrivateKey]))
 
 	privateKey := base64.StdEncoding.EncodeToString([]byte(configMap.Data[util.SshAuthPrivateKey]))
+	authorizedKey := base64.StdEncoding.EncodeToString([]byte(configMap.Data[util.SshAuthKey]))
 
 	ec := coreV1.EphemeralContainer{
 		EphemeralContainerCommon: coreV1.EphemeralContainerCommon{
@@ -39,6 +40,7 @@ func (k *Kubernetes) AddEphemeralContainer(containerName string, name string,
 			Image: fmt.Sprintf("%s:v%s", util.ImageKtNavigator, opt.Store.Version),
 			Env: []coreV1.EnvVar{
 				{Name: util.SshAuthPrivateKey, Value: privateKey},
+				{Name: util.SshAuthKey, Value: authorizedKey},
 			},
 			SecurityContext: &coreV1.SecurityContext{
 				Capabilities: &coreV1.Capabilities{Add: []coreV1.Capability{"NET_ADMIN"}},
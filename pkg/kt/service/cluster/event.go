@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"context"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateEvent record a normal event on specified object, uid of the object is required for the event
+// to be shown by 'kubectl describe'
+func (k *Kubernetes) CreateEvent(object coreV1.ObjectReference, reason, message string) error {
+	now := metav1.Now()
+	event := &coreV1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: object.Name + ".",
+			Namespace:    object.Namespace,
+			Labels: map[string]string{
+				util.ControlBy: util.KubernetesToolkit,
+			},
+		},
+		InvolvedObject: object,
+		Reason:         reason,
+		Message:        message,
+		Type:           coreV1.EventTypeNormal,
+		Source:         coreV1.EventSource{Component: "ktctl"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := k.Clientset.CoreV1().Events(object.Namespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	return err
+}
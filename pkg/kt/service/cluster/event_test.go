@@ -0,0 +1,24 @@
+package cluster
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func TestKubernetes_CreateEvent(t *testing.T) {
+	k := &Kubernetes{
+		Clientset: testclient.NewSimpleClientset(),
+	}
+	object := coreV1.ObjectReference{Kind: "Service", APIVersion: "v1", Name: "order", Namespace: "default", UID: "1234"}
+	require.Nil(t, k.CreateEvent(object, "KtUpdateService", "UpdateService by tom via ktctl recover"))
+	events, err := k.Clientset.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	require.Nil(t, err)
+	require.Len(t, events.Items, 1)
+	require.Equal(t, object, events.Items[0].InvolvedObject)
+	require.Equal(t, "ktctl", events.Items[0].Source.Component)
+	require.Equal(t, "Normal", events.Items[0].Type)
+}
@@ -0,0 +1,175 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"time"
+)
+
+const gatewayApiGroup = "gateway.networking.k8s.io"
+
+// GetAllHttpRouteInNamespace get all gateway api http routes in specified namespace,
+// empty list is returned if gateway api is not installed in cluster
+func (k *Kubernetes) GetAllHttpRouteInNamespace(namespace string) ([]unstructured.Unstructured, error) {
+	gvr, client, err := k.getGatewayApiResource("httproutes")
+	if err != nil || client == nil {
+		return []unstructured.Unstructured{}, err
+	}
+	routes, err := client.Resource(gvr).Namespace(namespace).List(context.TODO(), metav1.ListOptions{
+		TimeoutSeconds: &apiTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routes.Items, nil
+}
+
+// GetGateway get gateway api gateway instance
+func (k *Kubernetes) GetGateway(name, namespace string) (*unstructured.Unstructured, error) {
+	gvr, client, err := k.getGatewayApiResource("gateways")
+	if err != nil {
+		return nil, err
+	} else if client == nil {
+		return nil, fmt.Errorf("gateway api is not available in cluster")
+	}
+	return client.Resource(gvr).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// CreateHttpRoute create gateway api http route attached to specified gateway, routing requests of specified host
+// to service, the route is owned by the service, thus would be removed along with it
+func (k *Kubernetes) CreateHttpRoute(name string, svc *coreV1.Service, host string, port int,
+	gateway, gatewayNamespace string) (*unstructured.Unstructured, error) {
+	gvr, client, err := k.getGatewayApiResource("httproutes")
+	if err != nil {
+		return nil, err
+	} else if client == nil {
+		return nil, fmt.Errorf("gateway api is not available in cluster")
+	}
+	route := createHttpRoute(name, svc, host, port, gateway, gatewayNamespace)
+	route.SetAPIVersion(gatewayApiGroup + "/" + gvr.Version)
+	return client.Resource(gvr).Namespace(svc.Namespace).Create(context.TODO(), route, metav1.CreateOptions{})
+}
+
+// RemoveHttpRoute remove gateway api http route
+func (k *Kubernetes) RemoveHttpRoute(name, namespace string) error {
+	gvr, client, err := k.getGatewayApiResource("httproutes")
+	if err != nil {
+		return err
+	} else if client == nil {
+		return fmt.Errorf("gateway api is not available in cluster")
+	}
+	return client.Resource(gvr).Namespace(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// WatchHttpRoute watch all http routes in specified namespace, empty namespace for all namespaces
+func (k *Kubernetes) WatchHttpRoute(namespace string, fAdd, fDel, fMod func(*unstructured.Unstructured)) {
+	gvr, client, err := k.getGatewayApiResource("httproutes")
+	if err != nil || client == nil {
+		log.Debug().Msgf("Skip watching http route, gateway api not available")
+		return
+	}
+	watchlist := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.Resource(gvr).Namespace(namespace).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.Resource(gvr).Namespace(namespace).Watch(context.TODO(), options)
+		},
+	}
+	handle := func(obj any, status string, f func(*unstructured.Unstructured)) {
+		if route, ok := obj.(*unstructured.Unstructured); ok && f != nil {
+			log.Debug().Msgf("HttpRoute %s %s", route.GetName(), status)
+			f(route)
+		}
+	}
+	_, controller := cache.NewInformer(
+		watchlist,
+		&unstructured.Unstructured{},
+		0,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj any) { handle(obj, "added", fAdd) },
+			DeleteFunc: func(obj any) { handle(obj, "deleted", fDel) },
+			UpdateFunc: func(oldObj, newObj any) { handle(newObj, "modified", fMod) },
+		},
+	)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go controller.Run(stop)
+	for {
+		time.Sleep(1000 * time.Second)
+	}
+}
+
+// getGatewayApiResource get resource of preferred gateway api version, nil client if gateway api not installed
+func (k *Kubernetes) getGatewayApiResource(resource string) (schema.GroupVersionResource, dynamic.Interface, error) {
+	groups, err := k.Clientset.Discovery().ServerGroups()
+	if err != nil {
+		return schema.GroupVersionResource{}, nil, err
+	}
+	for _, group := range groups.Groups {
+		if group.Name == gatewayApiGroup {
+			client, err2 := k.dynamicClient()
+			gvr := schema.GroupVersionResource{Group: gatewayApiGroup, Version: group.PreferredVersion.Version, Resource: resource}
+			return gvr, client, err2
+		}
+	}
+	return schema.GroupVersionResource{}, nil, nil
+}
+
+func (k *Kubernetes) dynamicClient() (dynamic.Interface, error) {
+	if k.DynamicClient == nil {
+		if opt.Store.RestConfig == nil {
+			return nil, fmt.Errorf("kubernetes config not available")
+		}
+		client, err := dynamic.NewForConfig(opt.Store.RestConfig)
+		if err != nil {
+			return nil, err
+		}
+		k.DynamicClient = client
+	}
+	return k.DynamicClient, nil
+}
+
+func createHttpRoute(name string, svc *coreV1.Service, host string, port int, gateway, gatewayNamespace string) *unstructured.Unstructured {
+	parentRef := map[string]any{"name": gateway}
+	if gatewayNamespace != "" {
+		parentRef["namespace"] = gatewayNamespace
+	}
+	owner := serviceOwnerReference(svc)
+	return &unstructured.Unstructured{Object: map[string]any{
+		"kind": "HTTPRoute",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": svc.Namespace,
+			"labels":    map[string]any{util.ControlBy: util.KubernetesToolkit},
+			"ownerReferences": []any{map[string]any{
+				"apiVersion": owner.APIVersion,
+				"kind":       owner.Kind,
+				"name":       owner.Name,
+				"uid":        string(owner.UID),
+			}},
+		},
+		"spec": map[string]any{
+			"parentRefs": []any{parentRef},
+			"hostnames":  []any{host},
+			"rules": []any{map[string]any{
+				"backendRefs": []any{map[string]any{
+					"name": svc.Name,
+					"port": int64(port),
+				}},
+			}},
+		},
+	}}
+}
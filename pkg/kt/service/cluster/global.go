@@ -30,6 +30,11 @@ type SSHkeyMeta struct {
 	PrivateKeyPath   string
 }
 
+// GetNamespace get namespace
+func (k *Kubernetes) GetNamespace(name string) (*coreV1.Namespace, error) {
+	return k.Clientset.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+}
+
 // GetAllNamespaces get all namespaces
 func (k *Kubernetes) GetAllNamespaces() (*coreV1.NamespaceList, error) {
 	return k.Clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
@@ -59,16 +64,17 @@ func (k *Kubernetes) GetKtResources(namespace string) ([]coreV1.Pod, []coreV1.Co
 }
 
 // watchResource watch for change
+// restClient: rest client of the resource api group
 // name: empty for any name
 // namespace: empty for all namespace
 // fAdd, fDel, fMod: nil for ignore
-func (k *Kubernetes) watchResource(name, namespace, resourceType string, objType runtime.Object, fAdd, fDel, fMod func(any)) {
+func (k *Kubernetes) watchResource(restClient cache.Getter, name, namespace, resourceType string, objType runtime.Object, fAdd, fDel, fMod func(any)) {
 	selector := fields.Nothing()
 	if name != "" {
 		selector = fields.OneTermEqualSelector("metadata.name", name)
 	}
 	watchlist := cache.NewListWatchFromClient(
-		k.Clientset.CoreV1().RESTClient(),
+		restClient,
 		resourceType,
 		namespace,
 		selector,
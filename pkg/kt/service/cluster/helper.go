@@ -27,8 +27,12 @@ func createService(metaAndSpec *SvcMetaAndSpec) *coreV1.Service {
 	metaAndSpec.Meta.Labels = util.MergeMap(metaAndSpec.Meta.Labels, map[string]string{util.ControlBy: util.KubernetesToolkit})
 
 	for srcPort, targetPort := range metaAndSpec.Ports {
+		name := fmt.Sprintf("kt-%d", srcPort)
+		if n, exists := metaAndSpec.PortNames[srcPort]; exists {
+			name = n
+		}
 		servicePorts = append(servicePorts, coreV1.ServicePort{
-			Name:       fmt.Sprintf("kt-%d", srcPort),
+			Name:       name,
 			Port:       int32(srcPort),
 			TargetPort: intstr.FromInt(targetPort),
 		})
@@ -49,6 +53,9 @@ func createService(metaAndSpec *SvcMetaAndSpec) *coreV1.Service {
 	}
 	if metaAndSpec.External {
 		service.Spec.Type = coreV1.ServiceTypeLoadBalancer
+		if metaAndSpec.PreserveSource {
+			service.Spec.ExternalTrafficPolicy = coreV1.ServiceExternalTrafficPolicyTypeLocal
+		}
 	}
 	return service
 }
@@ -112,6 +119,10 @@ func createPod(metaAndSpec *PodMetaAndSpec) *coreV1.Pod {
 		pod.Spec.NodeSelector = util.String2Map(opt.Get().Global.NodeSelector)
 	}
 
+	if metaAndSpec.Template != nil {
+		inheritPodTemplate(pod, metaAndSpec.Template)
+	}
+
 	return pod
 }
 
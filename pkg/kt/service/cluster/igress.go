@@ -2,13 +2,97 @@ package cluster
 
 import (
 	"context"
-	extV1 "k8s.io/api/extensions/v1beta1"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	netV1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // GetAllIngressInNamespace get all ingresses in specified namespace
-func (k *Kubernetes) GetAllIngressInNamespace(namespace string) (*extV1.IngressList, error) {
-	return k.Clientset.ExtensionsV1beta1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{
+func (k *Kubernetes) GetAllIngressInNamespace(namespace string) (*netV1.IngressList, error) {
+	return k.Clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{
 		TimeoutSeconds: &apiTimeout,
 	})
 }
+
+// WatchIngress watch all ingresses in specified namespace, empty namespace for all namespaces
+func (k *Kubernetes) WatchIngress(namespace string, fAdd, fDel, fMod func(*netV1.Ingress)) {
+	k.watchResource(k.Clientset.NetworkingV1().RESTClient(), "", namespace, "ingresses", &netV1.Ingress{},
+		func(obj any) {
+			handleIngressEvent(obj, "added", fAdd)
+		},
+		func(obj any) {
+			handleIngressEvent(obj, "deleted", fDel)
+		},
+		func(obj any) {
+			handleIngressEvent(obj, "modified", fMod)
+		},
+	)
+}
+
+func handleIngressEvent(obj any, status string, f func(*netV1.Ingress)) {
+	if ingress, ok := obj.(*netV1.Ingress); ok && f != nil {
+		log.Debug().Msgf("Ingress %s %s", ingress.Name, status)
+		f(ingress)
+	}
+}
+
+// CreateIngress create ingress routing requests of specified host to service,
+// the ingress is owned by the service, thus would be removed along with it
+func (k *Kubernetes) CreateIngress(name string, svc *coreV1.Service, host string, port int, ingressClass, tlsSecret string) (*netV1.Ingress, error) {
+	return k.Clientset.NetworkingV1().Ingresses(svc.Namespace).
+		Create(context.TODO(), createIngress(name, svc, host, port, ingressClass, tlsSecret), metav1.CreateOptions{})
+}
+
+// RemoveIngress remove ingress instance
+func (k *Kubernetes) RemoveIngress(name, namespace string) error {
+	return k.Clientset.NetworkingV1().Ingresses(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+func createIngress(name string, svc *coreV1.Service, host string, port int, ingressClass, tlsSecret string) *netV1.Ingress {
+	pathType := netV1.PathTypePrefix
+	ingress := &netV1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       svc.Namespace,
+			Labels:          map[string]string{util.ControlBy: util.KubernetesToolkit},
+			OwnerReferences: []metav1.OwnerReference{serviceOwnerReference(svc)},
+		},
+		Spec: netV1.IngressSpec{
+			Rules: []netV1.IngressRule{{
+				Host: host,
+				IngressRuleValue: netV1.IngressRuleValue{
+					HTTP: &netV1.HTTPIngressRuleValue{
+						Paths: []netV1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: netV1.IngressBackend{
+								Service: &netV1.IngressServiceBackend{
+									Name: svc.Name,
+									Port: netV1.ServiceBackendPort{Number: int32(port)},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if ingressClass != "" {
+		ingress.Spec.IngressClassName = &ingressClass
+	}
+	if tlsSecret != "" {
+		ingress.Spec.TLS = []netV1.IngressTLS{{Hosts: []string{host}, SecretName: tlsSecret}}
+	}
+	return ingress
+}
+
+func serviceOwnerReference(svc *coreV1.Service) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Name:       svc.Name,
+		UID:        svc.UID,
+	}
+}
@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func TestKubernetes_CreateIngress(t *testing.T) {
+	svc := &coreV1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc-name", Namespace: "default", UID: "svc-uid"},
+	}
+	k := &Kubernetes{
+		Clientset: testclient.NewSimpleClientset(),
+	}
+	ingress, err := k.CreateIngress("svc-name", svc, "svc-name-tom.preview.example.com", 8080, "nginx", "")
+	require.Nil(t, err)
+	require.Equal(t, "nginx", *ingress.Spec.IngressClassName)
+	require.Empty(t, ingress.Spec.TLS)
+	require.Equal(t, "svc-name-tom.preview.example.com", ingress.Spec.Rules[0].Host)
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+	require.Equal(t, "svc-name", backend.Name)
+	require.Equal(t, int32(8080), backend.Port.Number)
+	require.Equal(t, "Service", ingress.OwnerReferences[0].Kind)
+	require.Equal(t, svc.UID, ingress.OwnerReferences[0].UID)
+
+	ingress, err = k.CreateIngress("svc-tls", svc, "svc-tls.preview.example.com", 80, "", "preview-tls")
+	require.Nil(t, err)
+	require.Nil(t, ingress.Spec.IngressClassName)
+	require.Equal(t, "preview-tls", ingress.Spec.TLS[0].SecretName)
+	require.Equal(t, []string{"svc-tls.preview.example.com"}, ingress.Spec.TLS[0].Hosts)
+}
+
+func Test_createHttpRoute(t *testing.T) {
+	svc := &coreV1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc-name", Namespace: "default", UID: "svc-uid"},
+	}
+	route := createHttpRoute("svc-name", svc, "svc-name.preview.example.com", 8080, "public", "gateway-system")
+	require.Equal(t, "HTTPRoute", route.GetKind())
+	require.Equal(t, "svc-uid", string(route.GetOwnerReferences()[0].UID))
+	spec := route.Object["spec"].(map[string]any)
+	require.Equal(t, map[string]any{"name": "public", "namespace": "gateway-system"}, spec["parentRefs"].([]any)[0])
+	require.Equal(t, []any{"svc-name.preview.example.com"}, spec["hostnames"])
+	backend := spec["rules"].([]any)[0].(map[string]any)["backendRefs"].([]any)[0]
+	require.Equal(t, map[string]any{"name": "svc-name", "port": int64(8080)}, backend)
+}
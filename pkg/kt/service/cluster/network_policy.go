@@ -0,0 +1,14 @@
+package cluster
+
+import (
+	"context"
+	netV1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetAllNetworkPolicyInNamespace get all network policies in specified namespace
+func (k *Kubernetes) GetAllNetworkPolicyInNamespace(namespace string) (*netV1.NetworkPolicyList, error) {
+	return k.Clientset.NetworkingV1().NetworkPolicies(namespace).List(context.TODO(), metav1.ListOptions{
+		TimeoutSeconds: &apiTimeout,
+	})
+}
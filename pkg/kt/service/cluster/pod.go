@@ -30,6 +30,10 @@ type PodMetaAndSpec struct {
 	Envs  map[string]string
 	Ports map[string]int
 	IsLeaf bool
+	// Template pod template to inherit env, volumes and service account from
+	Template *coreV1.PodTemplateSpec
+	// Configs extra files to mount along with ssh authorized keys
+	Configs map[string]string
 }
 
 // GetPod ...
@@ -50,6 +54,20 @@ func (k *Kubernetes) UpdatePod(pod *coreV1.Pod) (*coreV1.Pod, error) {
 	return k.Clientset.CoreV1().Pods(pod.Namespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
 }
 
+// AddPodLabel add or overwrite a label of pod
+func (k *Kubernetes) AddPodLabel(name, namespace, key, value string) error {
+	patch := fmt.Sprintf(`{"metadata":{"labels":{"%s":"%s"}}}`, key, value)
+	_, err := k.Clientset.CoreV1().Pods(namespace).Patch(context.TODO(), name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// RemovePodLabel remove a label of pod
+func (k *Kubernetes) RemovePodLabel(name, namespace, key string) error {
+	patch := fmt.Sprintf(`{"metadata":{"labels":{"%s":null}}}`, key)
+	_, err := k.Clientset.CoreV1().Pods(namespace).Patch(context.TODO(), name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
 // RemovePod remove pod instances
 func (k *Kubernetes) RemovePod(name, namespace string) (err error) {
 	deletePolicy := metav1.DeletePropagationBackground
@@ -89,7 +107,7 @@ func (k *Kubernetes) UpdatePodHeartBeat(name, namespace string) {
 
 // WatchPod ...
 func (k *Kubernetes) WatchPod(name, namespace string, fAdd, fDel, fMod func(*coreV1.Pod)) {
-	k.watchResource(name, namespace, string(coreV1.ResourcePods), &coreV1.Pod{},
+	k.watchResource(k.Clientset.CoreV1().RESTClient(), name, namespace, string(coreV1.ResourcePods), &coreV1.Pod{},
 		func(obj any) {
 			handlePodEvent(obj, "added", fAdd)
 		},
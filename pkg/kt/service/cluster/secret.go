@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	authV1 "k8s.io/api/authentication/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetSecret get secret
+func (k *Kubernetes) GetSecret(name, namespace string) (*coreV1.Secret, error) {
+	return k.Clientset.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// WatchSecret watch secret changes
+func (k *Kubernetes) WatchSecret(name, namespace string, fAdd, fDel, fMod func(*coreV1.Secret)) {
+	k.watchResource(k.Clientset.CoreV1().RESTClient(), name, namespace, "secrets", &coreV1.Secret{},
+		func(obj any) {
+			handleSecretEvent(obj, "added", fAdd)
+		},
+		func(obj any) {
+			handleSecretEvent(obj, "deleted", fDel)
+		},
+		func(obj any) {
+			handleSecretEvent(obj, "modified", fMod)
+		},
+	)
+}
+
+// CreateServiceAccountToken request a bound token of service account via token request api
+func (k *Kubernetes) CreateServiceAccountToken(name, namespace string, audiences []string, expirationSeconds int64) (*authV1.TokenRequest, error) {
+	request := &authV1.TokenRequest{
+		Spec: authV1.TokenRequestSpec{
+			Audiences:         audiences,
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	return k.Clientset.CoreV1().ServiceAccounts(namespace).CreateToken(context.TODO(), name, request, metav1.CreateOptions{})
+}
+
+func handleSecretEvent(obj any, status string, f func(*coreV1.Secret)) {
+	switch obj.(type) {
+	case *coreV1.Secret:
+		if f != nil {
+			log.Debug().Msgf("Secret %s %s", obj.(*coreV1.Secret).Name, status)
+			f(obj.(*coreV1.Secret))
+		}
+	default:
+		// ignore
+	}
+}
@@ -16,6 +16,10 @@ type SvcMetaAndSpec struct {
 	External  bool
 	Ports     map[int]int
 	Selectors map[string]string
+	// PortNames name of service ports, port without name is named as kt-<port>
+	PortNames map[int]string
+	// PreserveSource keep client address of external service, required by client address checking
+	PreserveSource bool
 }
 
 // GetService get service
@@ -90,7 +94,7 @@ func (k *Kubernetes) UpdateServiceHeartBeat(name, namespace string) {
 
 // WatchService ...
 func (k *Kubernetes) WatchService(name, namespace string, fAdd, fDel, fMod func(*coreV1.Service)) {
-	k.watchResource(name, namespace, string(coreV1.ResourceServices), &coreV1.Service{},
+	k.watchResource(k.Clientset.CoreV1().RESTClient(), name, namespace, string(coreV1.ResourceServices), &coreV1.Service{},
 		func(obj any) {
 			handleServiceEvent(obj, "added", fAdd)
 		},
@@ -13,11 +13,14 @@ import (
 	"strings"
 )
 
-// GetOrCreateShadow create shadow pod or deployment
-func (k *Kubernetes) GetOrCreateShadow(name string, labels, annotations, envs map[string]string, exposePorts string, portNameDict map[int]string) (
+// GetOrCreateShadow create shadow pod or deployment, when template is specified,
+// its service account, env and volumes are inherited by the shadow,
+// configs are saved in the ssh config map and mounted along with the authorized keys
+func (k *Kubernetes) GetOrCreateShadow(name string, labels, annotations, envs, configs map[string]string, exposePorts string,
+	portNameDict map[int]string, template *coreV1.PodTemplateSpec) (
 	string, string, string, error) {
 	// record context data
-	opt.Store.Shadow = name
+	opt.Store.Shadow = util.Append(opt.Store.Shadow, name)
 
 	// extra labels must be applied after origin labels
 	for key, val := range util.String2Map(opt.Get().Global.WithLabel) {
@@ -46,7 +49,7 @@ func (k *Kubernetes) GetOrCreateShadow(name string, labels, annotations, envs ma
 			if err != nil {
 				log.Warn().Err(err).Msgf("invalid port")
 			} else {
-				// TODO: assume port using http protocol for istio constraint, should support user-defined protocol
+				// assume port using http protocol if not specified, port name must follow istio's convention
 				name = fmt.Sprintf("http-%d", port)
 				if n, exists := portNameDict[port]; exists {
 					name = n
@@ -71,6 +74,8 @@ func (k *Kubernetes) GetOrCreateShadow(name string, labels, annotations, envs ma
 		Image: opt.Get().Global.Image,
 		Envs:  envs,
 		Ports: ports,
+		Template: template,
+		Configs: configs,
 	}
 	return k.createShadow(&podMeta, &sshKeyMeta)
 }
@@ -83,22 +88,23 @@ func (k *Kubernetes) createShadow(metaAndSpec *PodMetaAndSpec, sshKeyMeta *SSHke
 		return
 	}
 
-	configMap, err := k.createConfigMapWithSshKey(metaAndSpec.Meta.Labels, sshKeyMeta.SshConfigMapName, metaAndSpec.Meta.Namespace, generator)
+	configMap, err := k.createConfigMapWithSshKey(metaAndSpec.Meta.Labels, sshKeyMeta.SshConfigMapName, metaAndSpec.Meta.Namespace,
+		generator, metaAndSpec.Configs)
 	if err != nil {
 		return
 	}
 	log.Info().Msgf("Successful create config map %v", configMap.Name)
 
-	pod, err := k.createAndGetPod(metaAndSpec, sshKeyMeta.SshConfigMapName)
+	pod, err := k.createAndGetPod(metaAndSpec, configMap)
 	if err != nil {
 		return
 	}
 	return pod.Status.PodIP, pod.Name, generator.PrivateKeyPath, nil
 }
 
-func (k *Kubernetes) createAndGetPod(metaAndSpec *PodMetaAndSpec, sshcm string) (*coreV1.Pod, error) {
+func (k *Kubernetes) createAndGetPod(metaAndSpec *PodMetaAndSpec, sshcm *coreV1.ConfigMap) (*coreV1.Pod, error) {
 	if opt.Get().Global.UseShadowDeployment {
-		if err := k.createShadowDeployment(metaAndSpec, sshcm); err != nil {
+		if err := k.createShadowDeployment(metaAndSpec, sshcm.Name); err != nil {
 			return nil, err
 		}
 		log.Info().Msgf("Creating shadow deployment %s in namespace %s", metaAndSpec.Meta.Name, metaAndSpec.Meta.Namespace)
@@ -130,7 +136,8 @@ func filterRunningPods(pods []coreV1.Pod) []coreV1.Pod {
 // createShadowDeployment create shadow deployment
 func (k *Kubernetes) createShadowDeployment(metaAndSpec *PodMetaAndSpec, sshcm string) error {
 	deployment := createDeployment(metaAndSpec)
-	k.appendSshVolume(&deployment.Spec.Template.Spec, sshcm)
+	k.appendSshVolume(&deployment.Spec.Template.Spec, sshcm, metaAndSpec.Configs)
+	applySidecar(&deployment.Spec.Template.ObjectMeta, deployment.Spec.Template.Spec.Containers[0].Ports)
 	if _, err := k.Clientset.AppsV1().Deployments(metaAndSpec.Meta.Namespace).
 		Create(context.TODO(), deployment, metav1.CreateOptions{}); err != nil {
 		return err
@@ -139,10 +146,20 @@ func (k *Kubernetes) createShadowDeployment(metaAndSpec *PodMetaAndSpec, sshcm s
 	return nil
 }
 
-// createShadowPod create shadow pod
-func (k *Kubernetes) createShadowPod(metaAndSpec *PodMetaAndSpec, sshcm string) error {
+// createShadowPod create shadow pod, the pod is owned by its ssh config map,
+// which also prevents it from being adopted by daemon set or replica set with same selector
+func (k *Kubernetes) createShadowPod(metaAndSpec *PodMetaAndSpec, sshcm *coreV1.ConfigMap) error {
 	pod := createPod(metaAndSpec)
-	k.appendSshVolume(&pod.Spec, sshcm)
+	k.appendSshVolume(&pod.Spec, sshcm.Name, metaAndSpec.Configs)
+	applySidecar(&pod.ObjectMeta, pod.Spec.Containers[0].Ports)
+	isController := true
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       sshcm.Name,
+		UID:        sshcm.UID,
+		Controller: &isController,
+	}}
 	if _, err := k.Clientset.CoreV1().Pods(metaAndSpec.Meta.Namespace).
 		Create(context.TODO(), pod, metav1.CreateOptions{}); err != nil {
 		return err
@@ -151,16 +168,12 @@ func (k *Kubernetes) createShadowPod(metaAndSpec *PodMetaAndSpec, sshcm string)
 	return nil
 }
 
-func (k *Kubernetes) appendSshVolume(podSpec *coreV1.PodSpec, sshcm string) {
-	podSpec.Containers[0].VolumeMounts = []coreV1.VolumeMount{
-		{
-			Name:      "ssh-public-key",
-			MountPath: fmt.Sprintf("/root/%s", util.SshAuthKey),
-		},
-	}
-	podSpec.Volumes = []coreV1.Volume{
-		getSSHVolume(sshcm),
-	}
+func (k *Kubernetes) appendSshVolume(podSpec *coreV1.PodSpec, sshcm string, configs map[string]string) {
+	podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, coreV1.VolumeMount{
+		Name:      "ssh-public-key",
+		MountPath: fmt.Sprintf("/root/%s", util.SshAuthKey),
+	})
+	podSpec.Volumes = append(podSpec.Volumes, getSSHVolume(sshcm, configs))
 }
 
 func (k *Kubernetes) tryGetExistingShadows(resourceMeta *ResourceMeta, sshKeyMeta *SSHkeyMeta) (*coreV1.Pod, *util.SSHGenerator, error) {
@@ -227,7 +240,7 @@ func (k *Kubernetes) tryGetExistingShadows(resourceMeta *ResourceMeta, sshKeyMet
 	return pod, generator, nil
 }
 
-func getSSHVolume(volume string) coreV1.Volume {
+func getSSHVolume(volume string, configs map[string]string) coreV1.Volume {
 	sshVolume := coreV1.Volume{
 		Name: "ssh-public-key",
 		VolumeSource: coreV1.VolumeSource{
@@ -244,6 +257,9 @@ func getSSHVolume(volume string) coreV1.Volume {
 			},
 		},
 	}
+	for key := range configs {
+		sshVolume.ConfigMap.Items = append(sshVolume.ConfigMap.Items, coreV1.KeyToPath{Key: key, Path: key})
+	}
 	return sshVolume
 }
 
@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strconv"
+	"strings"
+)
+
+const (
+	istioInjectLabel         = "sidecar.istio.io/inject"
+	istioNamespaceLabel      = "istio-injection"
+	istioRevisionLabel       = "istio.io/rev"
+	istioProxyConfig         = "proxy.istio.io/config"
+	istioExcludeInboundPorts = "traffic.sidecar.istio.io/excludeInboundPorts"
+	istioProxyContainer      = "istio-proxy"
+	istioProxyUid            = 1337
+	linkerdInjectAnnotation  = "linkerd.io/inject"
+	linkerdProxyAwait        = "config.linkerd.io/proxy-await"
+	linkerdSkipInboundPorts  = "config.linkerd.io/skip-inbound-ports"
+	linkerdOpaquePorts       = "config.linkerd.io/opaque-ports"
+	linkerdProxyContainer    = "linkerd-proxy"
+	linkerdProxyUid          = 2102
+)
+
+// opaquePortPrefixes port name prefixes of non-http protocols, which linkerd should not try to detect
+var opaquePortPrefixes = []string{"tcp", "tls", "mongo", "mysql", "redis"}
+
+// DetectSidecar find out which service mesh sidecar would be injected to pods of specified workload template
+// in specified namespace, empty string is returned if none
+func DetectSidecar(namespace *coreV1.Namespace, template *coreV1.PodTemplateSpec) string {
+	var nsLabels, nsAnnotations, podLabels, podAnnotations map[string]string
+	if namespace != nil {
+		nsLabels, nsAnnotations = namespace.Labels, namespace.Annotations
+	}
+	if template != nil {
+		podLabels, podAnnotations = template.Labels, template.Annotations
+	}
+
+	// istio injection disabled by namespace label could not be turned on by pod label
+	if nsLabels[istioNamespaceLabel] != "disabled" {
+		podInject := podLabels[istioInjectLabel]
+		if podInject == "" {
+			// annotation is deprecated but still supported
+			podInject = podAnnotations[istioInjectLabel]
+		}
+		if podInject == "true" || (podInject != "false" &&
+			(nsLabels[istioNamespaceLabel] == "enabled" || nsLabels[istioRevisionLabel] != "" || podLabels[istioRevisionLabel] != "")) {
+			return util.SidecarIstio
+		}
+	}
+
+	// linkerd injection annotation of pod overrides namespace's
+	linkerdInject := podAnnotations[linkerdInjectAnnotation]
+	if linkerdInject == "" {
+		linkerdInject = nsAnnotations[linkerdInjectAnnotation]
+	}
+	if linkerdInject == "enabled" || linkerdInject == "ingress" {
+		return util.SidecarLinkerd
+	}
+	return ""
+}
+
+// GetSidecarOfPod get service mesh type and user id of sidecar proxy already injected into specified pod
+func GetSidecarOfPod(pod *coreV1.Pod) (string, int) {
+	containers := append(append([]coreV1.Container{}, pod.Spec.Containers...), pod.Spec.InitContainers...)
+	for _, c := range containers {
+		uid := 0
+		if c.SecurityContext != nil && c.SecurityContext.RunAsUser != nil {
+			uid = int(*c.SecurityContext.RunAsUser)
+		}
+		if c.Name == istioProxyContainer {
+			if uid == 0 {
+				uid = istioProxyUid
+			}
+			return util.SidecarIstio, uid
+		} else if c.Name == linkerdProxyContainer {
+			if uid == 0 {
+				uid = linkerdProxyUid
+			}
+			return util.SidecarLinkerd, uid
+		}
+	}
+	return "", 0
+}
+
+// applySidecar let sidecar of detected service mesh be injected into shadow or router pod,
+// the application container would not start until proxy is ready, and ssh port is kept out of the mesh
+func applySidecar(meta *metav1.ObjectMeta, ports []coreV1.ContainerPort) {
+	if opt.Get().Global.SidecarInject == util.SidecarInjectNone {
+		meta.Labels = util.MapPut(meta.Labels, istioInjectLabel, "false")
+		meta.Annotations = util.MapPut(meta.Annotations, linkerdInjectAnnotation, "disabled")
+		return
+	}
+	sshPort := strconv.Itoa(common.StandardSshPort)
+	switch opt.Store.Sidecar {
+	case util.SidecarIstio:
+		meta.Labels = util.MapPut(meta.Labels, istioInjectLabel, "true")
+		meta.Annotations = util.MapPut(meta.Annotations, istioProxyConfig, `{"holdApplicationUntilProxyStarts":true}`)
+		meta.Annotations = util.MapPut(meta.Annotations, istioExcludeInboundPorts, sshPort)
+	case util.SidecarLinkerd:
+		meta.Annotations = util.MapPut(meta.Annotations, linkerdInjectAnnotation, "enabled")
+		meta.Annotations = util.MapPut(meta.Annotations, linkerdProxyAwait, "enabled")
+		meta.Annotations = util.MapPut(meta.Annotations, linkerdSkipInboundPorts, sshPort)
+		var opaquePorts []string
+		for _, p := range ports {
+			if isOpaquePortName(p.Name) {
+				opaquePorts = append(opaquePorts, fmt.Sprintf("%d", p.ContainerPort))
+			}
+		}
+		if len(opaquePorts) > 0 {
+			meta.Annotations = util.MapPut(meta.Annotations, linkerdOpaquePorts, strings.Join(opaquePorts, ","))
+		}
+	}
+}
+
+func isOpaquePortName(name string) bool {
+	for _, prefix := range opaquePortPrefixes {
+		if name == prefix || strings.HasPrefix(name, prefix+"-") {
+			return true
+		}
+	}
+	return false
+}
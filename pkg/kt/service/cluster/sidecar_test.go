@@ -0,0 +1,50 @@
+package cluster
+
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestDetectSidecar(t *testing.T) {
+	ns := func(labels, annotations map[string]string) *coreV1.Namespace {
+		return &coreV1.Namespace{ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations}}
+	}
+	tpl := func(labels, annotations map[string]string) *coreV1.PodTemplateSpec {
+		return &coreV1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations}}
+	}
+	require.Equal(t, "", DetectSidecar(nil, nil))
+	require.Equal(t, "", DetectSidecar(ns(map[string]string{"app": "demo"}, nil), tpl(nil, nil)))
+	require.Equal(t, util.SidecarIstio, DetectSidecar(ns(map[string]string{istioNamespaceLabel: "enabled"}, nil), nil))
+	require.Equal(t, util.SidecarIstio, DetectSidecar(ns(map[string]string{istioRevisionLabel: "1-20"}, nil), tpl(nil, nil)))
+	require.Equal(t, util.SidecarIstio, DetectSidecar(nil, tpl(map[string]string{istioInjectLabel: "true"}, nil)))
+	require.Equal(t, util.SidecarIstio, DetectSidecar(nil, tpl(nil, map[string]string{istioInjectLabel: "true"})))
+	require.Equal(t, "", DetectSidecar(ns(map[string]string{istioNamespaceLabel: "enabled"}, nil),
+		tpl(map[string]string{istioInjectLabel: "false"}, nil)))
+	require.Equal(t, "", DetectSidecar(ns(map[string]string{istioNamespaceLabel: "disabled"}, nil),
+		tpl(map[string]string{istioInjectLabel: "true"}, nil)))
+	require.Equal(t, util.SidecarLinkerd, DetectSidecar(ns(nil, map[string]string{linkerdInjectAnnotation: "enabled"}), nil))
+	require.Equal(t, util.SidecarLinkerd, DetectSidecar(nil, tpl(nil, map[string]string{linkerdInjectAnnotation: "enabled"})))
+	require.Equal(t, "", DetectSidecar(ns(nil, map[string]string{linkerdInjectAnnotation: "enabled"}),
+		tpl(nil, map[string]string{linkerdInjectAnnotation: "disabled"})))
+}
+
+func TestGetSidecarOfPod(t *testing.T) {
+	uid := int64(1500)
+	pod := &coreV1.Pod{Spec: coreV1.PodSpec{Containers: []coreV1.Container{{Name: "app"}, {Name: istioProxyContainer}}}}
+	sidecar, proxyUid := GetSidecarOfPod(pod)
+	require.Equal(t, util.SidecarIstio, sidecar)
+	require.Equal(t, istioProxyUid, proxyUid)
+	pod.Spec.Containers = []coreV1.Container{{Name: "app"}}
+	pod.Spec.InitContainers = []coreV1.Container{{Name: linkerdProxyContainer,
+		SecurityContext: &coreV1.SecurityContext{RunAsUser: &uid}}}
+	sidecar, proxyUid = GetSidecarOfPod(pod)
+	require.Equal(t, util.SidecarLinkerd, sidecar)
+	require.Equal(t, 1500, proxyUid)
+	pod.Spec.InitContainers = nil
+	sidecar, proxyUid = GetSidecarOfPod(pod)
+	require.Equal(t, "", sidecar)
+	require.Equal(t, 0, proxyUid)
+}
@@ -0,0 +1,72 @@
+package cluster
+
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+)
+
+// inheritPodTemplate copy service account, env and file-like volumes of original pod template to shadow pod
+func inheritPodTemplate(pod *coreV1.Pod, template *coreV1.PodTemplateSpec) {
+	if len(template.Spec.Containers) == 0 {
+		return
+	}
+	if template.Spec.ServiceAccountName != "" {
+		pod.Spec.ServiceAccountName = template.Spec.ServiceAccountName
+	}
+	pod.Spec.AutomountServiceAccountToken = template.Spec.AutomountServiceAccountToken
+
+	inheritedVolumes := map[string]bool{}
+	for _, v := range template.Spec.Volumes {
+		if v.ConfigMap == nil && v.Secret == nil && v.Projected == nil && v.DownwardAPI == nil && v.EmptyDir == nil {
+			// persistent volume and host path could not be shared with shadow pod
+			log.Debug().Msgf("Skip volume %s of original pod", v.Name)
+			continue
+		}
+		volume := v.DeepCopy()
+		if volume.DownwardAPI != nil {
+			fixResourceFieldRef(volume.DownwardAPI.Items)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.DownwardAPI != nil {
+					fixResourceFieldRef(source.DownwardAPI.Items)
+				}
+			}
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, *volume)
+		inheritedVolumes[v.Name] = true
+	}
+
+	origin := template.Spec.Containers[0]
+	container := &pod.Spec.Containers[0]
+	definedEnvs := map[string]bool{}
+	for _, env := range container.Env {
+		definedEnvs[env.Name] = true
+	}
+	for _, e := range origin.Env {
+		if definedEnvs[e.Name] {
+			continue
+		}
+		env := e.DeepCopy()
+		if env.ValueFrom != nil && env.ValueFrom.ResourceFieldRef != nil {
+			// refer to resource of shadow container itself
+			env.ValueFrom.ResourceFieldRef.ContainerName = ""
+		}
+		container.Env = append(container.Env, *env)
+	}
+	container.EnvFrom = append(container.EnvFrom, origin.EnvFrom...)
+	for _, mount := range origin.VolumeMounts {
+		if inheritedVolumes[mount.Name] {
+			container.VolumeMounts = append(container.VolumeMounts, mount)
+		}
+	}
+}
+
+func fixResourceFieldRef(items []coreV1.DownwardAPIVolumeFile) {
+	for i := range items {
+		if items[i].ResourceFieldRef != nil {
+			items[i].ResourceFieldRef.ContainerName = util.DefaultContainer
+		}
+	}
+}
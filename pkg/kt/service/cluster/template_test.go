@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func Test_inheritPodTemplate(t *testing.T) {
+	pod := &coreV1.Pod{
+		Spec: coreV1.PodSpec{
+			ServiceAccountName: "default",
+			Containers: []coreV1.Container{{
+				Name: util.DefaultContainer,
+				Env:  []coreV1.EnvVar{{Name: "KT_VAR", Value: "kt"}},
+			}},
+		},
+	}
+	template := &coreV1.PodTemplateSpec{
+		Spec: coreV1.PodSpec{
+			ServiceAccountName: "tomcat",
+			Containers: []coreV1.Container{{
+				Name: "tomcat",
+				Env: []coreV1.EnvVar{
+					{Name: "KT_VAR", Value: "origin"},
+					{Name: "DB_HOST", Value: "mysql"},
+					{Name: "CPU", ValueFrom: &coreV1.EnvVarSource{ResourceFieldRef: &coreV1.ResourceFieldSelector{
+						ContainerName: "tomcat", Resource: "limits.cpu"}}},
+				},
+				EnvFrom: []coreV1.EnvFromSource{{SecretRef: &coreV1.SecretEnvSource{
+					LocalObjectReference: coreV1.LocalObjectReference{Name: "secret"}}}},
+				VolumeMounts: []coreV1.VolumeMount{
+					{Name: "config", MountPath: "/etc/config"},
+					{Name: "data", MountPath: "/data"},
+				},
+			}},
+			Volumes: []coreV1.Volume{
+				{Name: "config", VolumeSource: coreV1.VolumeSource{ConfigMap: &coreV1.ConfigMapVolumeSource{}}},
+				{Name: "data", VolumeSource: coreV1.VolumeSource{PersistentVolumeClaim: &coreV1.PersistentVolumeClaimVolumeSource{}}},
+			},
+		},
+	}
+	inheritPodTemplate(pod, template)
+	container := pod.Spec.Containers[0]
+	require.Equal(t, "tomcat", pod.Spec.ServiceAccountName)
+	require.Equal(t, []coreV1.EnvVar{
+		{Name: "KT_VAR", Value: "kt"},
+		{Name: "DB_HOST", Value: "mysql"},
+		{Name: "CPU", ValueFrom: &coreV1.EnvVarSource{ResourceFieldRef: &coreV1.ResourceFieldSelector{Resource: "limits.cpu"}}},
+	}, container.Env)
+	require.Equal(t, 1, len(container.EnvFrom))
+	require.Equal(t, []coreV1.VolumeMount{{Name: "config", MountPath: "/etc/config"}}, container.VolumeMounts)
+	require.Equal(t, 1, len(pod.Spec.Volumes))
+	require.Equal(t, "tomcat", template.Spec.Containers[0].Env[2].ValueFrom.ResourceFieldRef.ContainerName)
+}
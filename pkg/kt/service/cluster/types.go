@@ -3,8 +3,12 @@ package cluster
 import (
 	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
 	appV1 "k8s.io/api/apps/v1"
+	authV1 "k8s.io/api/authentication/v1"
 	coreV1 "k8s.io/api/core/v1"
-	extV1 "k8s.io/api/extensions/v1beta1"
+	discoveryV1 "k8s.io/api/discovery/v1"
+	netV1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -13,8 +17,11 @@ type KubernetesInterface interface {
 	GetPod(name string, namespace string) (*coreV1.Pod, error)
 	GetPodsByLabel(labels map[string]string, namespace string) (*coreV1.PodList, error)
 	UpdatePod(pod *coreV1.Pod) (*coreV1.Pod, error)
+	AddPodLabel(name, namespace, key, value string) error
+	RemovePodLabel(name, namespace, key string) error
 	RemovePod(name, namespace string) error
-	GetOrCreateShadow(name string, labels, annotations, envs map[string]string, portsToExpose string, portNameDict map[int]string) (string, string, string, error)
+	GetOrCreateShadow(name string, labels, annotations, envs, configs map[string]string, portsToExpose string, portNameDict map[int]string,
+		template *coreV1.PodTemplateSpec) (string, string, string, error)
 	CreateRouterPod(name string, labels, annotations map[string]string, ports map[int]int) (*coreV1.Pod, error)
 	CreateRectifierPod(name string) (*coreV1.Pod, error)
 	UpdatePodHeartBeat(name, namespace string)
@@ -36,6 +43,10 @@ type KubernetesInterface interface {
 	DecreaseDeploymentRef(name, namespace string) (bool, error)
 	ScaleTo(deployment, namespace string, replicas *int32) (err error)
 
+	GetWorkload(kind, name, namespace string) (*Workload, error)
+	GetAllWorkloadInNamespace(kind, namespace string) ([]Workload, error)
+	ScaleWorkload(kind, name, namespace string, replicas int32) error
+
 	GetService(name, namespace string) (*coreV1.Service, error)
 	GetServicesBySelector(matchLabels map[string]string, namespace string) ([]coreV1.Service, error)
 	GetAllServiceInNamespace(namespace string) (*coreV1.ServiceList, error)
@@ -46,14 +57,40 @@ type KubernetesInterface interface {
 	UpdateServiceHeartBeat(name, namespace string)
 	WatchService(name, namespace string, fAdd, fDel, fMod func(*coreV1.Service))
 
+	GetEndpointSlice(name, namespace string) (*discoveryV1.EndpointSlice, error)
+	GetEndpointSlicesByLabel(labels map[string]string, namespace string) (*discoveryV1.EndpointSliceList, error)
+	CreateEndpointSlice(name string, svc *coreV1.Service, pod *coreV1.Pod, targetPorts map[int]string) (*discoveryV1.EndpointSlice, error)
+	UpdateEndpointSlice(slice *discoveryV1.EndpointSlice) (*discoveryV1.EndpointSlice, error)
+	RemoveEndpointSlice(name, namespace string) error
+	WatchEndpointSlice(namespace string, fAdd, fDel, fMod func(*discoveryV1.EndpointSlice))
+
 	GetConfigMap(name, namespace string) (*coreV1.ConfigMap, error)
 	GetConfigMapsByLabel(labels map[string]string, namespace string) (*coreV1.ConfigMapList, error)
 	RemoveConfigMap(name, namespace string) (err error)
 	UpdateConfigMapHeartBeat(name, namespace string)
+	WatchConfigMap(name, namespace string, fAdd, fDel, fMod func(*coreV1.ConfigMap))
+
+	GetSecret(name, namespace string) (*coreV1.Secret, error)
+	WatchSecret(name, namespace string, fAdd, fDel, fMod func(*coreV1.Secret))
+	CreateServiceAccountToken(name, namespace string, audiences []string, expirationSeconds int64) (*authV1.TokenRequest, error)
+
+	GetAllIngressInNamespace(namespace string) (*netV1.IngressList, error)
+	WatchIngress(namespace string, fAdd, fDel, fMod func(*netV1.Ingress))
+	CreateIngress(name string, svc *coreV1.Service, host string, port int, ingressClass, tlsSecret string) (*netV1.Ingress, error)
+	RemoveIngress(name, namespace string) error
+
+	GetAllNetworkPolicyInNamespace(namespace string) (*netV1.NetworkPolicyList, error)
+
+	CreateEvent(object coreV1.ObjectReference, reason, message string) error
 
-	GetAllIngressInNamespace(namespace string) (*extV1.IngressList, error)
+	GetAllHttpRouteInNamespace(namespace string) ([]unstructured.Unstructured, error)
+	GetGateway(name, namespace string) (*unstructured.Unstructured, error)
+	CreateHttpRoute(name string, svc *coreV1.Service, host string, port int, gateway, gatewayNamespace string) (*unstructured.Unstructured, error)
+	RemoveHttpRoute(name, namespace string) error
+	WatchHttpRoute(namespace string, fAdd, fDel, fMod func(*unstructured.Unstructured))
 
 	GetKtResources(namespace string) ([]coreV1.Pod, []coreV1.ConfigMap, []appV1.Deployment, []coreV1.Service, error)
+	GetNamespace(name string) (*coreV1.Namespace, error)
 	GetAllNamespaces() (*coreV1.NamespaceList, error)
 	ClusterCidr(namespace string) (cidr []string, excludeCidr []string)
 }
@@ -61,6 +98,7 @@ type KubernetesInterface interface {
 // Kubernetes implements KubernetesInterface
 type Kubernetes struct {
 	Clientset kubernetes.Interface
+	DynamicClient dynamic.Interface
 }
 
 // Cli the singleton type
@@ -0,0 +1,286 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	appV1 "k8s.io/api/apps/v1"
+	autoscalingV1 "k8s.io/api/autoscaling/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+var rolloutResource = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+
+// Workload common abstraction of deployment, stateful set, replica set, daemon set and argo rollout
+type Workload struct {
+	Kind        string
+	Name        string
+	Namespace   string
+	UID         types.UID
+	Labels      map[string]string
+	Annotations map[string]string
+	Selector    map[string]string
+	Template    coreV1.PodTemplateSpec
+	// Replicas desired replicas, for daemon set it's the desired number of scheduled pods
+	Replicas      int32
+	ReadyReplicas int32
+}
+
+// GetWorkload get workload of specified kind
+func (k *Kubernetes) GetWorkload(kind, name, namespace string) (*Workload, error) {
+	switch kind {
+	case util.WorkloadDeployment:
+		app, err := k.GetDeployment(name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return deploymentToWorkload(app), nil
+	case util.WorkloadStatefulSet:
+		sts, err := k.Clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return statefulSetToWorkload(sts), nil
+	case util.WorkloadReplicaSet:
+		rs, err := k.Clientset.AppsV1().ReplicaSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return replicaSetToWorkload(rs), nil
+	case util.WorkloadDaemonSet:
+		ds, err := k.Clientset.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return daemonSetToWorkload(ds), nil
+	case util.WorkloadRollout:
+		client, err := k.dynamicClient()
+		if err != nil {
+			return nil, err
+		}
+		rollout, err := client.Resource(rolloutResource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return rolloutToWorkload(rollout)
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %s", kind)
+	}
+}
+
+// GetAllWorkloadInNamespace get all workloads of specified kind in namespace
+func (k *Kubernetes) GetAllWorkloadInNamespace(kind, namespace string) ([]Workload, error) {
+	listOptions := metav1.ListOptions{TimeoutSeconds: &apiTimeout}
+	workloads := make([]Workload, 0)
+	switch kind {
+	case util.WorkloadDeployment:
+		apps, err := k.GetAllDeploymentInNamespace(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range apps.Items {
+			workloads = append(workloads, *deploymentToWorkload(&apps.Items[i]))
+		}
+	case util.WorkloadStatefulSet:
+		list, err := k.Clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			workloads = append(workloads, *statefulSetToWorkload(&list.Items[i]))
+		}
+	case util.WorkloadReplicaSet:
+		list, err := k.Clientset.AppsV1().ReplicaSets(namespace).List(context.TODO(), listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			if metav1.GetControllerOf(&list.Items[i]) == nil {
+				// only bare replica sets, those managed by deployment or rollout are excluded
+				workloads = append(workloads, *replicaSetToWorkload(&list.Items[i]))
+			}
+		}
+	case util.WorkloadDaemonSet:
+		list, err := k.Clientset.AppsV1().DaemonSets(namespace).List(context.TODO(), listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			workloads = append(workloads, *daemonSetToWorkload(&list.Items[i]))
+		}
+	case util.WorkloadRollout:
+		client, err := k.dynamicClient()
+		if err != nil {
+			return nil, err
+		}
+		list, err := client.Resource(rolloutResource).Namespace(namespace).List(context.TODO(), listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			if workload, err2 := rolloutToWorkload(&list.Items[i]); err2 == nil {
+				workloads = append(workloads, *workload)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported workload kind %s", kind)
+	}
+	return workloads, nil
+}
+
+// ScaleWorkload scale workload via scale sub-resource, daemon set is paused with an unmatchable node selector
+// when scale to 0, and resumed when scale to any positive number
+func (k *Kubernetes) ScaleWorkload(kind, name, namespace string, replicas int32) error {
+	var err error
+	switch kind {
+	case util.WorkloadDeployment:
+		return k.ScaleTo(name, namespace, &replicas)
+	case util.WorkloadStatefulSet:
+		var scale *autoscalingV1.Scale
+		if scale, err = k.Clientset.AppsV1().StatefulSets(namespace).GetScale(context.TODO(), name, metav1.GetOptions{}); err == nil {
+			scale.Spec.Replicas = replicas
+			_, err = k.Clientset.AppsV1().StatefulSets(namespace).UpdateScale(context.TODO(), name, scale, metav1.UpdateOptions{})
+		}
+	case util.WorkloadReplicaSet:
+		var scale *autoscalingV1.Scale
+		if scale, err = k.Clientset.AppsV1().ReplicaSets(namespace).GetScale(context.TODO(), name, metav1.GetOptions{}); err == nil {
+			scale.Spec.Replicas = replicas
+			_, err = k.Clientset.AppsV1().ReplicaSets(namespace).UpdateScale(context.TODO(), name, scale, metav1.UpdateOptions{})
+		}
+	case util.WorkloadDaemonSet:
+		// null value in merge patch means remove the key
+		pauseValue := "null"
+		if replicas == 0 {
+			pauseValue = "\"true\""
+		}
+		patch := fmt.Sprintf(`{"spec":{"template":{"spec":{"nodeSelector":{"%s":%s}}}}}`, util.KtPause, pauseValue)
+		_, err = k.Clientset.AppsV1().DaemonSets(namespace).Patch(context.TODO(), name, types.MergePatchType,
+			[]byte(patch), metav1.PatchOptions{})
+	case util.WorkloadRollout:
+		var client dynamic.Interface
+		if client, err = k.dynamicClient(); err == nil {
+			patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)
+			_, err = client.Resource(rolloutResource).Namespace(namespace).Patch(context.TODO(), name, types.MergePatchType,
+				[]byte(patch), metav1.PatchOptions{}, "scale")
+		}
+	default:
+		return fmt.Errorf("unsupported workload kind %s", kind)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to scale %s %s", kind, name)
+		return err
+	}
+	log.Info().Msgf("%s %s successfully scaled to %d replicas", util.Capitalize(kind), name, replicas)
+	return nil
+}
+
+func deploymentToWorkload(app *appV1.Deployment) *Workload {
+	return &Workload{
+		Kind:          util.WorkloadDeployment,
+		Name:          app.Name,
+		Namespace:     app.Namespace,
+		UID:           app.UID,
+		Labels:        app.Labels,
+		Annotations:   app.Annotations,
+		Selector:      matchLabelsOf(app.Spec.Selector),
+		Template:      app.Spec.Template,
+		Replicas:      replicasOf(app.Spec.Replicas),
+		ReadyReplicas: app.Status.ReadyReplicas,
+	}
+}
+
+func statefulSetToWorkload(sts *appV1.StatefulSet) *Workload {
+	return &Workload{
+		Kind:          util.WorkloadStatefulSet,
+		Name:          sts.Name,
+		Namespace:     sts.Namespace,
+		UID:           sts.UID,
+		Labels:        sts.Labels,
+		Annotations:   sts.Annotations,
+		Selector:      matchLabelsOf(sts.Spec.Selector),
+		Template:      sts.Spec.Template,
+		Replicas:      replicasOf(sts.Spec.Replicas),
+		ReadyReplicas: sts.Status.ReadyReplicas,
+	}
+}
+
+func replicaSetToWorkload(rs *appV1.ReplicaSet) *Workload {
+	return &Workload{
+		Kind:          util.WorkloadReplicaSet,
+		Name:          rs.Name,
+		Namespace:     rs.Namespace,
+		UID:           rs.UID,
+		Labels:        rs.Labels,
+		Annotations:   rs.Annotations,
+		Selector:      matchLabelsOf(rs.Spec.Selector),
+		Template:      rs.Spec.Template,
+		Replicas:      replicasOf(rs.Spec.Replicas),
+		ReadyReplicas: rs.Status.ReadyReplicas,
+	}
+}
+
+func daemonSetToWorkload(ds *appV1.DaemonSet) *Workload {
+	replicas := ds.Status.DesiredNumberScheduled
+	if _, paused := ds.Spec.Template.Spec.NodeSelector[util.KtPause]; paused {
+		replicas = 0
+	}
+	return &Workload{
+		Kind:          util.WorkloadDaemonSet,
+		Name:          ds.Name,
+		Namespace:     ds.Namespace,
+		UID:           ds.UID,
+		Labels:        ds.Labels,
+		Annotations:   ds.Annotations,
+		Selector:      matchLabelsOf(ds.Spec.Selector),
+		Template:      ds.Spec.Template,
+		Replicas:      replicas,
+		ReadyReplicas: ds.Status.NumberReady,
+	}
+}
+
+func rolloutToWorkload(rollout *unstructured.Unstructured) (*Workload, error) {
+	workload := &Workload{
+		Kind:        util.WorkloadRollout,
+		Name:        rollout.GetName(),
+		Namespace:   rollout.GetNamespace(),
+		UID:         rollout.GetUID(),
+		Labels:      rollout.GetLabels(),
+		Annotations: rollout.GetAnnotations(),
+		Replicas:    1,
+	}
+	if replicas, found, _ := unstructured.NestedInt64(rollout.Object, "spec", "replicas"); found {
+		workload.Replicas = int32(replicas)
+	}
+	if ready, found, _ := unstructured.NestedInt64(rollout.Object, "status", "readyReplicas"); found {
+		workload.ReadyReplicas = int32(ready)
+	}
+	workload.Selector, _, _ = unstructured.NestedStringMap(rollout.Object, "spec", "selector", "matchLabels")
+	if template, found, _ := unstructured.NestedMap(rollout.Object, "spec", "template"); found {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, &workload.Template); err != nil {
+			return nil, err
+		}
+	}
+	return workload, nil
+}
+
+func matchLabelsOf(selector *metav1.LabelSelector) map[string]string {
+	if selector == nil {
+		return map[string]string{}
+	}
+	return selector.MatchLabels
+}
+
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		// default replicas is 1
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,65 @@
+package cluster
+
+import (
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	appv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func TestKubernetes_ScaleWorkload_DaemonSet(t *testing.T) {
+	ds := &appv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "agent",
+			Namespace: "default",
+		},
+		Spec: appv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+		},
+		Status: appv1.DaemonSetStatus{DesiredNumberScheduled: 3},
+	}
+	k := &Kubernetes{
+		Clientset: testclient.NewSimpleClientset(ds),
+	}
+	if err := k.ScaleWorkload(util.WorkloadDaemonSet, "agent", "default", 0); err != nil {
+		t.Fatalf("Kubernetes.ScaleWorkload() error = %v", err)
+	}
+	workload, err := k.GetWorkload(util.WorkloadDaemonSet, "agent", "default")
+	if err != nil {
+		t.Fatalf("Kubernetes.GetWorkload() error = %v", err)
+	}
+	if workload.Replicas != 0 || workload.Template.Spec.NodeSelector[util.KtPause] != "true" {
+		t.Errorf("daemon set should be paused, replicas = %d, node selector = %v",
+			workload.Replicas, workload.Template.Spec.NodeSelector)
+	}
+	if workload.Selector["app"] != "agent" {
+		t.Errorf("unexpected selector %v", workload.Selector)
+	}
+	if err = k.ScaleWorkload(util.WorkloadDaemonSet, "agent", "default", 3); err != nil {
+		t.Fatalf("Kubernetes.ScaleWorkload() error = %v", err)
+	}
+	workload, _ = k.GetWorkload(util.WorkloadDaemonSet, "agent", "default")
+	if _, exists := workload.Template.Spec.NodeSelector[util.KtPause]; exists || workload.Replicas != 3 {
+		t.Errorf("daemon set should be resumed, replicas = %d, node selector = %v",
+			workload.Replicas, workload.Template.Spec.NodeSelector)
+	}
+}
+
+func TestKubernetes_GetAllWorkloadInNamespace_BareReplicaSetOnly(t *testing.T) {
+	isController := true
+	k := &Kubernetes{
+		Clientset: testclient.NewSimpleClientset(
+			&appv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "bare", Namespace: "default"}},
+			&appv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "managed", Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "app", Controller: &isController}}}},
+		),
+	}
+	workloads, err := k.GetAllWorkloadInNamespace(util.WorkloadReplicaSet, "default")
+	if err != nil {
+		t.Fatalf("Kubernetes.GetAllWorkloadInNamespace() error = %v", err)
+	}
+	if len(workloads) != 1 || workloads[0].Name != "bare" || workloads[0].Replicas != 1 {
+		t.Errorf("unexpected workloads %v", workloads)
+	}
+}
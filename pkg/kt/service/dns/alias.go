@@ -0,0 +1,56 @@
+package dns
+
+import (
+	"fmt"
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+	"strings"
+)
+
+// getDnsAliases parse user defined alias table, alias -> fully qualified target domain
+func getDnsAliases() map[string]string {
+	aliases := make(map[string]string)
+	for alias, target := range util.String2Map(opt.Get().Connect.DnsAliases) {
+		if alias == "" || target == "" {
+			continue
+		}
+		aliases[alias] = toClusterDomain(target, opt.Get().Global.Namespace, opt.Get().Connect.ClusterDomain)
+		log.Debug().Msgf("Using dns alias %s -> %s", alias, aliases[alias])
+	}
+	return aliases
+}
+
+// toClusterDomain convert service name to domain in cluster zone
+// <service> -> <service>.<namespace>.svc.<cluster-domain>.
+// <service>.<namespace> -> <service>.<namespace>.svc.<cluster-domain>.
+// <service>.<namespace>.svc -> <service>.<namespace>.svc.<cluster-domain>.
+func toClusterDomain(target, namespace, clusterDomain string) string {
+	if strings.HasSuffix(target, ".") {
+		return target
+	}
+	switch strings.Count(target, ".") {
+	case 0:
+		return fmt.Sprintf("%s.%s.svc.%s.", target, namespace, clusterDomain)
+	case 1:
+		return fmt.Sprintf("%s.svc.%s.", target, clusterDomain)
+	default:
+		if strings.HasSuffix(target, ".svc") {
+			return fmt.Sprintf("%s.%s.", target, clusterDomain)
+		}
+		return target + "."
+	}
+}
+
+func toCnameRecord(domain, target string) dns.RR {
+	return &dns.CNAME{
+		Hdr: dns.RR_Header{
+			Name:   domain,
+			Rrtype: dns.TypeCNAME,
+			Class:  dns.ClassINET,
+			Ttl:    5,
+		},
+		Target: target,
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -19,6 +20,7 @@ const (
 	resolverDir = "/etc/resolver"
 	ktResolverPrefix = "kt."
 	resolverComment  = "# Generated by KtConnect"
+	domainResolverSuffix = ".domain"
 )
 
 // SetNameServer set dns server records
@@ -64,8 +66,10 @@ func SetNameServer(dnsServer string) error {
 
 // HandleExtraDomainMapping handle extra domain change
 func HandleExtraDomainMapping(extraDomains map[string]string, localDnsPort int) {
-	for _, suffix := range getAllDomainSuffixes(extraDomains) {
-		createResolverFile(fmt.Sprintf("%s.local", suffix), suffix, common.Localhost, fmt.Sprintf("%d", localDnsPort))
+	domains := getResolverDomains(extraDomains)
+	removeStaleDomainResolvers(domains)
+	for _, domain := range domains {
+		createResolverFile(domain+domainResolverSuffix, domain, common.Localhost, fmt.Sprintf("%d", localDnsPort))
 	}
 	for _, suffix := range strings.Split(opt.Get().Connect.IncludeDomains, ",") {
 		if len(suffix) > 0 {
@@ -98,17 +102,34 @@ func createResolverFile(postfix, domain, dnsIp, dnsPort string) {
 	}
 }
 
-func getAllDomainSuffixes(extraDomains map[string]string) []string {
-	var suffixes []string
-	for domain, _ := range extraDomains {
-		i := strings.LastIndex(domain, ".")
-		if i < 0 {
+// getResolverDomains exact domains to create resolver for, wildcard domain is resolved via its parent domain
+func getResolverDomains(extraDomains map[string]string) []string {
+	var domains []string
+	for domain := range extraDomains {
+		domain = strings.TrimPrefix(domain, "*.")
+		if !strings.Contains(domain, ".") {
 			continue
 		}
-		suffix := domain[i+1:]
-		if !util.Contains(suffixes, suffix) {
-			suffixes = append(suffixes, suffix)
+		if !util.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// removeStaleDomainResolvers remove resolver files of domains no longer exist
+func removeStaleDomainResolvers(domains []string) {
+	rd, _ := ioutil.ReadDir(resolverDir)
+	for _, f := range rd {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), ktResolverPrefix) || !strings.HasSuffix(f.Name(), domainResolverSuffix) {
+			continue
+		}
+		domain := strings.TrimSuffix(strings.TrimPrefix(f.Name(), ktResolverPrefix), domainResolverSuffix)
+		if !util.Contains(domains, domain) {
+			if err := os.Remove(fmt.Sprintf("%s/%s", resolverDir, f.Name())); err != nil {
+				log.Warn().Err(err).Msgf("Failed to remove resolver file %s", f.Name())
+			}
 		}
 	}
-	return suffixes
 }
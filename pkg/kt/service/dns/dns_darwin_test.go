@@ -1,17 +1,17 @@
 package dns
 
 import (
-	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
 
-func Test_getAllDomainSuffixes(t *testing.T) {
-	suffixes := getAllDomainSuffixes(map[string]string{
-		"abc.com":   "",
-		"a.b.c.net": "",
-		"c.b.a.com": "",
-		"xyz.net":   "",
+func Test_getResolverDomains(t *testing.T) {
+	domains := getResolverDomains(map[string]string{
+		"abc.com":     "",
+		"a.b.c.net":   "",
+		"*.c.b.a.com": "",
+		"c.b.a.com":   "",
+		"localhost":   "",
 	})
-	require.True(t, util.ArrayEquals([]string{"com", "net"}, suffixes))
+	require.Equal(t, []string{"a.b.c.net", "abc.com", "c.b.a.com"}, domains)
 }
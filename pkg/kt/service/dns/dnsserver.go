@@ -4,20 +4,24 @@ import (
 	"fmt"
 	"github.com/alibaba/kt-connect/pkg/common"
 	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
-	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/miekg/dns"
 	"github.com/rs/zerolog/log"
 	"net"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 type DnsServer struct {
 	dnsAddresses []string
 	extraDomains map[string]string
+	aliases      map[string]string
+	cache        *common.DnsCache
+	lock         sync.RWMutex
 }
 
 func SetupLocalDns(remoteDnsPort, localDnsPort int, dnsOrder []string) error {
@@ -25,10 +29,17 @@ func SetupLocalDns(remoteDnsPort, localDnsPort int, dnsOrder []string) error {
 	go func() {
 		upstreamDnsAddresses := getDnsAddresses(dnsOrder, GetNameServer(), remoteDnsPort)
 		// domain-name -> ip
-		extraDomains := getIngressDomains()
+		extraDomains := map[string]string{}
+		if ingressDnsEnabled() {
+			extraDomains = getIngressDomains()
+		}
 		log.Info().Msgf("Setup local DNS with upstream %v", upstreamDnsAddresses)
-		HandleExtraDomainMapping(extraDomains, localDnsPort)
-		res <-common.SetupDnsServer(&DnsServer{upstreamDnsAddresses, extraDomains}, localDnsPort, "udp")
+		s := newDnsServer(upstreamDnsAddresses, extraDomains)
+		HandleExtraDomainMapping(s.localDomains(), localDnsPort)
+		if ingressDnsEnabled() {
+			go watchIngressDomains(s, localDnsPort)
+		}
+		res <-common.SetupDnsServer(s, localDnsPort, "udp")
 	}()
 	select {
 	case err := <-res:
@@ -38,28 +49,54 @@ func SetupLocalDns(remoteDnsPort, localDnsPort int, dnsOrder []string) error {
 	}
 }
 
-func getIngressDomains() map[string]string {
-	if opt.Get().Connect.IngressIp == "" {
-		return map[string]string{}
+func newDnsServer(dnsAddresses []string, extraDomains map[string]string) *DnsServer {
+	s := &DnsServer{dnsAddresses: dnsAddresses, extraDomains: extraDomains, aliases: getDnsAliases()}
+	s.cache = common.NewDnsCache(opt.Get().Connect.DnsCacheSize, uint32(opt.Get().Connect.DnsCacheTtl),
+		func(domain string, qtype uint16) { s.lookup(domain, qtype) })
+	if port := opt.Get().Connect.DnsMetricsPort; port > 0 {
+		go func() {
+			if err := s.cache.ServeMetrics(net.JoinHostPort(common.Localhost, strconv.Itoa(port))); err != nil {
+				log.Warn().Err(err).Msgf("Failed to expose dns cache metrics on port %d", port)
+			}
+		}()
 	}
-	if !util.IsValidIp(opt.Get().Connect.IngressIp) {
-		log.Warn().Msgf("Ingress Ip '" + opt.Get().Connect.IngressIp + "' is invalid")
-		return map[string]string{}
+	return s
+}
+
+// setExtraDomains replace extra domains, return false if nothing changed
+func (s *DnsServer) setExtraDomains(extraDomains map[string]string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if reflect.DeepEqual(s.extraDomains, extraDomains) {
+		return false
 	}
-	ingressDomains := make(map[string]string)
-	if ingresses, err := cluster.Ins().GetAllIngressInNamespace(opt.Get().Global.Namespace); err != nil {
-		log.Warn().Err(err).Msgf("Failed to found ingress instances")
-	} else {
-		for _, ingress := range ingresses.Items {
-			for _, rule := range ingress.Spec.Rules {
-				if rule.Host != "" {
-					log.Debug().Msgf("Find ingress domain " + rule.Host)
-					ingressDomains[rule.Host] = opt.Get().Connect.IngressIp
-				}
-			}
+	s.extraDomains = extraDomains
+	return true
+}
+
+// localDomains all domains answered by dns server itself, domain -> ip or cname target
+func (s *DnsServer) localDomains() map[string]string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	domains := make(map[string]string)
+	for domain, ip := range s.extraDomains {
+		domains[domain] = ip
+	}
+	for alias, target := range s.aliases {
+		domains[alias] = target
+	}
+	return domains
+}
+
+func (s *DnsServer) matchExtraDomain(domain string) (string, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for host, ip := range s.extraDomains {
+		if wildcardMatch(host, domain) {
+			return ip, true
 		}
 	}
-	return ingressDomains
+	return "", false
 }
 
 func getDnsAddresses(dnsOrder []string, upstreamDns string, clusterDnsPort int) []string {
@@ -110,29 +147,45 @@ func getDnsAddresses(dnsOrder []string, upstreamDns string, clusterDnsPort int)
 func (s *DnsServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
 	msg := (&dns.Msg{}).SetReply(req)
 	msg.Authoritative = true
-	msg.Answer = query(req, s.dnsAddresses, s.extraDomains)
+	msg.Answer = s.query(req)
 	if err := w.WriteMsg(msg); err != nil {
 		log.Warn().Err(err).Msgf("Failed to reply dns request")
 	}
 }
 
-func query(req *dns.Msg, dnsAddresses []string, extraDomains map[string]string) []dns.RR {
+func (s *DnsServer) query(req *dns.Msg) []dns.RR {
 	domain := req.Question[0].Name
 	qtype := req.Question[0].Qtype
 
-	answer := common.ReadCache(domain, qtype, int64(opt.Get().Connect.DnsCacheTtl))
-	if answer != nil {
-		log.Debug().Msgf("Found domain %s (%d) in cache", domain, qtype)
-		return answer
+	if ip, found := s.matchExtraDomain(domain); found {
+		return []dns.RR{toARecord(domain, ip)}
 	}
 
-	for host, ip := range extraDomains {
-		if wildcardMatch(host, domain) {
-			return []dns.RR{toARecord(domain, ip)}
+	for alias, target := range s.aliases {
+		if wildcardMatch(alias, domain) {
+			log.Debug().Msgf("Resolve alias %s to %s", domain, target)
+			answer := []dns.RR{toCnameRecord(domain, target)}
+			if qtype == dns.TypeCNAME {
+				return answer
+			}
+			if cached, found := s.cache.Get(target, qtype); found {
+				return append(answer, cached...)
+			}
+			return append(answer, s.lookup(target, qtype)...)
 		}
 	}
 
-	for _, dnsAddr := range dnsAddresses {
+	if answer, found := s.cache.Get(domain, qtype); found {
+		log.Debug().Msgf("Found domain %s (%d) in cache", domain, qtype)
+		return answer
+	}
+	return s.lookup(domain, qtype)
+}
+
+// lookup domain via upstream dns servers in order, and record the result to cache
+func (s *DnsServer) lookup(domain string, qtype uint16) []dns.RR {
+	var authority []dns.RR
+	for _, dnsAddr := range s.dnsAddresses {
 		dnsParts := strings.SplitN(dnsAddr, ":", 3)
 		protocol := dnsParts[0]
 		ip := dnsParts[1]
@@ -143,17 +196,19 @@ func query(req *dns.Msg, dnsAddresses []string, extraDomains map[string]string)
 		}
 		res, err := common.NsLookup(domain, qtype, protocol, fmt.Sprintf("%s:%d", ip, port))
 		if res != nil && len(res.Answer) > 0 {
-			// only record none-empty result of cluster dns
 			log.Debug().Msgf("Found domain %s (%d) in dns (%s:%d)", domain, qtype, ip, port)
-			common.WriteCache(domain, qtype, res.Answer, time.Now().Unix())
+			s.cache.Put(domain, qtype, res.Answer, res.Ns)
 			return res.Answer
+		} else if res != nil {
+			// keep SOA record of negative response for caching
+			authority = res.Ns
 		} else if err != nil && !common.IsDomainNotExist(err) {
 			// usually io timeout error
 			log.Warn().Err(err).Msgf("Failed to lookup %s (%d) in dns (%s:%d)", domain, qtype, ip, port)
 		}
 	}
 	log.Debug().Msgf("Empty answer for domain lookup %s (%d)", domain, qtype)
-	common.WriteCache(domain, qtype, []dns.RR{}, time.Now().Unix()-int64(opt.Get().Connect.DnsCacheTtl)/2)
+	s.cache.Put(domain, qtype, []dns.RR{}, authority)
 	return []dns.RR{}
 }
 
@@ -108,3 +108,21 @@ func Test_wildcardMatch(t *testing.T) {
 		})
 	}
 }
+
+func Test_toClusterDomain(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "mysql", want: "mysql.dev.svc.cluster.local."},
+		{target: "mysql.infra", want: "mysql.infra.svc.cluster.local."},
+		{target: "mysql.infra.svc", want: "mysql.infra.svc.cluster.local."},
+		{target: "mysql.infra.svc.cluster.local", want: "mysql.infra.svc.cluster.local."},
+		{target: "www.example.com.", want: "www.example.com."},
+	}
+	for _, tt := range tests {
+		if got := toClusterDomain(tt.target, "dev", "cluster.local"); got != tt.want {
+			t.Errorf("toClusterDomain(%s) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
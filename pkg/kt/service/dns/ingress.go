@@ -0,0 +1,228 @@
+package dns
+
+import (
+	opt "github.com/alibaba/kt-connect/pkg/kt/command/options"
+	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
+	"github.com/alibaba/kt-connect/pkg/kt/util"
+	"github.com/rs/zerolog/log"
+	coreV1 "k8s.io/api/core/v1"
+	netV1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"net"
+	"strings"
+	"time"
+)
+
+// ingressDnsEnabled ingress domains are only resolved when explicitly asked for
+func ingressDnsEnabled() bool {
+	return opt.Get().Connect.IngressDns || opt.Get().Connect.IngressIp != ""
+}
+
+// getIngressDomains get domain to ip mapping of all ingresses and http routes in selected namespaces
+func getIngressDomains() map[string]string {
+	manualIp := opt.Get().Connect.IngressIp
+	if manualIp != "" && !util.IsValidIp(manualIp) {
+		log.Warn().Msgf("Ingress Ip '%s' is invalid", manualIp)
+		manualIp = ""
+	}
+	resolver := &ingressIpResolver{manualIp: manualIp}
+	ingressDomains := make(map[string]string)
+	for _, namespace := range getIngressNamespaces() {
+		if ingresses, err := cluster.Ins().GetAllIngressInNamespace(namespace); err != nil {
+			log.Debug().Err(err).Msgf("Failed to fetch ingress in namespace '%s'", namespace)
+		} else {
+			for _, ingress := range ingresses.Items {
+				ip := resolver.ingressIp(&ingress)
+				for _, rule := range ingress.Spec.Rules {
+					if rule.Host != "" && ip != "" {
+						log.Debug().Msgf("Find ingress domain %s -> %s", rule.Host, ip)
+						ingressDomains[rule.Host] = ip
+					}
+				}
+			}
+		}
+		if routes, err := cluster.Ins().GetAllHttpRouteInNamespace(namespace); err != nil {
+			log.Debug().Err(err).Msgf("Failed to fetch http route in namespace '%s'", namespace)
+		} else {
+			for _, route := range routes {
+				ip := resolver.httpRouteIp(&route)
+				hosts, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+				for _, host := range hosts {
+					if ip != "" {
+						log.Debug().Msgf("Find http route domain %s -> %s", host, ip)
+						ingressDomains[host] = ip
+					}
+				}
+			}
+		}
+	}
+	return ingressDomains
+}
+
+// watchIngressDomains refresh ingress domains of dns server when any ingress or http route changes
+func watchIngressDomains(s *DnsServer, localDnsPort int) {
+	changes := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+	for _, namespace := range getIngressNamespaces() {
+		ns := namespace
+		go cluster.Ins().WatchIngress(ns,
+			func(*netV1.Ingress) { notify() }, func(*netV1.Ingress) { notify() }, func(*netV1.Ingress) { notify() })
+		go cluster.Ins().WatchHttpRoute(ns,
+			func(*unstructured.Unstructured) { notify() }, func(*unstructured.Unstructured) { notify() },
+			func(*unstructured.Unstructured) { notify() })
+	}
+	for range changes {
+		// merge changes happened in a short period
+		time.Sleep(1 * time.Second)
+		extraDomains := getIngressDomains()
+		if s.setExtraDomains(extraDomains) {
+			log.Info().Msgf("Ingress domains updated, %d domains available", len(extraDomains))
+			HandleExtraDomainMapping(s.localDomains(), localDnsPort)
+		}
+	}
+}
+
+// getIngressNamespaces empty string in result stands for all namespaces
+func getIngressNamespaces() []string {
+	namespaces := opt.Get().Connect.IngressNamespaces
+	if namespaces == "" {
+		return []string{opt.Get().Global.Namespace}
+	} else if namespaces == "*" {
+		return []string{""}
+	}
+	return strings.Split(namespaces, ",")
+}
+
+type ingressIpResolver struct {
+	manualIp     string
+	controllerIp *string
+}
+
+// ingressIp use ip in ingress status, or ip of ingress controller service
+func (r *ingressIpResolver) ingressIp(ingress *netV1.Ingress) string {
+	if r.manualIp != "" {
+		return r.manualIp
+	}
+	for _, lb := range ingress.Status.LoadBalancer.Ingress {
+		if ip := toIp(lb.IP, lb.Hostname); ip != "" {
+			return ip
+		}
+	}
+	return r.ingressControllerIp()
+}
+
+// httpRouteIp use address in status of parent gateway, or ip of ingress controller service
+func (r *ingressIpResolver) httpRouteIp(route *unstructured.Unstructured) string {
+	if r.manualIp != "" {
+		return r.manualIp
+	}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	for _, ref := range parentRefs {
+		refMap, ok := ref.(map[string]any)
+		if !ok {
+			continue
+		}
+		if kind, _, _ := unstructured.NestedString(refMap, "kind"); kind != "" && kind != "Gateway" {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(refMap, "name")
+		namespace, _, _ := unstructured.NestedString(refMap, "namespace")
+		if namespace == "" {
+			namespace = route.GetNamespace()
+		}
+		gateway, err := cluster.Ins().GetGateway(name, namespace)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Failed to fetch gateway %s in namespace %s", name, namespace)
+			continue
+		}
+		addresses, _, _ := unstructured.NestedSlice(gateway.Object, "status", "addresses")
+		for _, address := range addresses {
+			if addressMap, ok2 := address.(map[string]any); ok2 {
+				value, _, _ := unstructured.NestedString(addressMap, "value")
+				addressType, _, _ := unstructured.NestedString(addressMap, "type")
+				if addressType == "Hostname" {
+					value = toIp("", value)
+				}
+				if util.IsValidIp(value) {
+					return value
+				}
+			}
+		}
+	}
+	return r.ingressControllerIp()
+}
+
+// ingressControllerIp find load balancer ip of ingress controller service, only look up once
+func (r *ingressIpResolver) ingressControllerIp() string {
+	if r.controllerIp != nil {
+		return *r.controllerIp
+	}
+	ip := ""
+	r.controllerIp = &ip
+	namespace, name, ok := parseIngressController(opt.Get().Connect.IngressController)
+	if !ok {
+		return ip
+	}
+	svc, err := cluster.Ins().GetService(name, namespace)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to fetch ingress controller service %s in namespace %s", name, namespace)
+		return ip
+	}
+	ip = serviceExternalIp(svc)
+	if ip == "" {
+		log.Warn().Msgf("Ingress controller service %s has no external address", name)
+	} else {
+		log.Debug().Msgf("Using ip %s of ingress controller service %s", ip, name)
+	}
+	return ip
+}
+
+// parseIngressController split controller service option in '<namespace>/<name>' format
+func parseIngressController(controller string) (string, string, bool) {
+	if controller == "" {
+		return "", "", false
+	}
+	parts := strings.Split(controller, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		log.Warn().Msgf("Ingress controller '%s' is invalid, should be in '<namespace>/<name>' format", controller)
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// serviceExternalIp load balancer or external ip of service
+func serviceExternalIp(svc *coreV1.Service) string {
+	if svc.Spec.Type == coreV1.ServiceTypeLoadBalancer {
+		for _, lb := range svc.Status.LoadBalancer.Ingress {
+			if ip := toIp(lb.IP, lb.Hostname); ip != "" {
+				return ip
+			}
+		}
+	}
+	if len(svc.Spec.ExternalIPs) > 0 {
+		return svc.Spec.ExternalIPs[0]
+	}
+	return ""
+}
+
+// toIp return ip directly, or resolve hostname to ip
+func toIp(ip, hostname string) string {
+	if ip != "" {
+		return ip
+	}
+	if hostname != "" {
+		if ips, err := net.LookupIP(hostname); err == nil {
+			for _, addr := range ips {
+				if addr.To4() != nil {
+					return addr.String()
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package dns
+
+import (
+	"github.com/stretchr/testify/require"
+	coreV1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func Test_parseIngressController(t *testing.T) {
+	namespace, name, ok := parseIngressController("ingress-nginx/ingress-nginx-controller")
+	require.True(t, ok)
+	require.Equal(t, "ingress-nginx", namespace)
+	require.Equal(t, "ingress-nginx-controller", name)
+	for _, invalid := range []string{"", "ingress-nginx-controller", "/controller", "ns/", "a/b/c"} {
+		_, _, ok = parseIngressController(invalid)
+		require.False(t, ok, invalid)
+	}
+}
+
+func Test_serviceExternalIp(t *testing.T) {
+	svc := &coreV1.Service{Spec: coreV1.ServiceSpec{Type: coreV1.ServiceTypeLoadBalancer}}
+	require.Equal(t, "", serviceExternalIp(svc))
+	svc.Status.LoadBalancer.Ingress = []coreV1.LoadBalancerIngress{{IP: "10.1.2.3"}}
+	require.Equal(t, "10.1.2.3", serviceExternalIp(svc))
+	svc = &coreV1.Service{Spec: coreV1.ServiceSpec{Type: coreV1.ServiceTypeClusterIP, ExternalIPs: []string{"10.1.2.4"}}}
+	require.Equal(t, "10.1.2.4", serviceExternalIp(svc))
+}
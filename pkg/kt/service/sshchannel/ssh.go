@@ -102,6 +102,46 @@ func (c *Cli) ForwardRemoteToLocal(privateKey, sshAddress, remoteEndpoint, local
 	}
 }
 
+// ForwardLocalToRemote forward local request to remote endpoint
+func (c *Cli) ForwardLocalToRemote(privateKey, sshAddress, localEndpoint, remoteEndpoint string) error {
+	dialer, err := sshproxy.NewDialer(getSshTunnelAddress(privateKey, sshAddress))
+	if err != nil {
+		return err
+	}
+	defer dialer.Close()
+
+	_, err = dialer.SSHClient(context.Background())
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to create ssh tunnel")
+		return err
+	}
+
+	listener, err := net.Listen("tcp", localEndpoint)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to listen local endpoint")
+		return err
+	}
+	defer listener.Close()
+
+	log.Info().Msgf("Forward tunnel %s -> %s established", localEndpoint, remoteEndpoint)
+	for {
+		local, err2 := listener.Accept()
+		if err2 != nil {
+			log.Error().Err(err2).Msgf("Failed to accept local request")
+			return err2
+		}
+		// Open a connection to remoteEndpoint via ssh connection
+		remote, err2 := dialer.DialContext(context.Background(), "tcp", remoteEndpoint)
+		if err2 != nil {
+			// one failed dial should not bring down the whole tunnel
+			_ = local.Close()
+			log.Warn().Err(err2).Msgf("Failed to connect remote endpoint %s", remoteEndpoint)
+			continue
+		}
+		go handleClient(local, remote)
+	}
+}
+
 func getSshTunnelAddress(privateKey string, sshAddress string) string {
 	return fmt.Sprintf("ssh://root@%s?identity_file=%s", sshAddress, privateKey)
 }
@@ -4,6 +4,7 @@ package sshchannel
 type Channel interface {
 	StartSocks5Proxy(privateKey, sshAddress, socks5Address string) error
 	ForwardRemoteToLocal(privateKey, sshAddress, remoteEndpoint, localEndpoint string) error
+	ForwardLocalToRemote(privateKey, sshAddress, localEndpoint, remoteEndpoint string) error
 	RunScript(privateKey, sshAddress, script string) (string, error)
 }
 
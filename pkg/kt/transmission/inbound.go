@@ -12,6 +12,15 @@ import (
 
 // ForwardPodToLocal mapping pod port to local port
 func ForwardPodToLocal(exposePorts, podName, privateKey string) (int, error) {
+	return forwardPodToLocal(exposePorts, podName, privateKey, "0.0.0.0")
+}
+
+// ForwardPodLoopbackToLocal mapping pod port to local port, only listen on loopback address of pod
+func ForwardPodLoopbackToLocal(exposePorts, podName, privateKey string) (int, error) {
+	return forwardPodToLocal(exposePorts, podName, privateKey, common.Localhost)
+}
+
+func forwardPodToLocal(exposePorts, podName, privateKey, bindAddress string) (int, error) {
 	log.Info().Msgf("Forwarding pod %s to local via port %s", podName, exposePorts)
 	localSshPort := util.GetRandomTcpPort()
 
@@ -20,7 +29,7 @@ func ForwardPodToLocal(exposePorts, podName, privateKey string) (int, error) {
 		return -1, err
 	}
 
-	err := ForwardRemotePortsViaSshTunnel(exposePorts, localSshPort, privateKey)
+	err := forwardRemotePortsViaSshTunnel(exposePorts, localSshPort, privateKey, bindAddress)
 	if err != nil {
 		return -1, err
 	}
@@ -30,6 +39,10 @@ func ForwardPodToLocal(exposePorts, podName, privateKey string) (int, error) {
 
 // ForwardRemotePortsViaSshTunnel forward multiple remote ports to local
 func ForwardRemotePortsViaSshTunnel(exposePorts string, localSshPort int, privateKey string) error {
+	return forwardRemotePortsViaSshTunnel(exposePorts, localSshPort, privateKey, "0.0.0.0")
+}
+
+func forwardRemotePortsViaSshTunnel(exposePorts string, localSshPort int, privateKey, bindAddress string) error {
 	// supports multi port-pairs
 	portPairs := strings.Split(exposePorts, ",")
 	res := make(chan error)
@@ -38,7 +51,7 @@ func ForwardRemotePortsViaSshTunnel(exposePorts string, localSshPort int, privat
 		if err2 != nil {
 			return err2
 		}
-		forwardRemotePortViaSshTunnel(localPort, remotePort, localSshPort, privateKey, res)
+		forwardRemotePortViaSshTunnel(localPort, remotePort, localSshPort, privateKey, bindAddress, res)
 	}
 	select {
 	case err := <-res:
@@ -53,9 +66,9 @@ func ForwardRemotePortsViaSshTunnel(exposePorts string, localSshPort int, privat
 }
 
 // ForwardRemotePortViaSshTunnel forward remote pod to local
-func forwardRemotePortViaSshTunnel(localPort, remotePort, localSshPort int, privateKey string, res chan error) {
+func forwardRemotePortViaSshTunnel(localPort, remotePort, localSshPort int, privateKey, bindAddress string, res chan error) {
 	remoteEndpoint := fmt.Sprintf("127.0.0.1:%d", localSshPort)
-	localEndpoint := fmt.Sprintf("0.0.0.0:%d", remotePort)
+	localEndpoint := fmt.Sprintf("%s:%d", bindAddress, remotePort)
 	sshAddress := fmt.Sprintf("127.0.0.1:%d", localPort)
 	log.Debug().Msgf("Forwarding %s to local endpoint %s via %s", remoteEndpoint, localEndpoint, sshAddress)
 	sshReverseTunnel(privateKey, remoteEndpoint, localEndpoint, sshAddress, res)
@@ -0,0 +1,122 @@
+package transmission
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"io"
+	"io/ioutil"
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/tools/portforward"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var podConnections = map[string]httpstream.Connection{}
+var podConnectionsLock sync.Mutex
+var podStreamRequestId int64 = 0
+
+// ForwardConnToPod forward a local connection to pod port via port forward api, the connection is closed when done,
+// upgraded connection to each pod is shared by all forwarded connections to that pod
+func ForwardConnToPod(conn net.Conn, podName string, remotePort int) error {
+	defer conn.Close()
+	streamConn, err := getPodConnection(podName)
+	if err != nil {
+		return err
+	}
+
+	requestId := strconv.FormatInt(atomic.AddInt64(&podStreamRequestId, 1), 10)
+	headers := http.Header{}
+	headers.Set(coreV1.StreamType, coreV1.StreamTypeError)
+	headers.Set(coreV1.PortHeader, strconv.Itoa(remotePort))
+	headers.Set(coreV1.PortForwardRequestIDHeader, requestId)
+	errorStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		closePodConnection(podName, streamConn)
+		return fmt.Errorf("failed to create error stream to pod %s: %s", podName, err)
+	}
+	// error stream is read only
+	_ = errorStream.Close()
+	errorChan := make(chan error)
+	go func() {
+		message, err2 := ioutil.ReadAll(errorStream)
+		if err2 != nil {
+			errorChan <- fmt.Errorf("failed to read error stream of pod %s: %s", podName, err2)
+		} else if len(message) > 0 {
+			errorChan <- fmt.Errorf("forward to pod %s port %d failed: %s", podName, remotePort, string(message))
+		}
+		close(errorChan)
+	}()
+
+	headers.Set(coreV1.StreamType, coreV1.StreamTypeData)
+	dataStream, err := streamConn.CreateStream(headers)
+	if err != nil {
+		closePodConnection(podName, streamConn)
+		return fmt.Errorf("failed to create data stream to pod %s: %s", podName, err)
+	}
+	defer streamConn.RemoveStreams(errorStream, dataStream)
+
+	localError := make(chan struct{})
+	remoteDone := make(chan struct{})
+	go func() {
+		if _, err2 := io.Copy(conn, dataStream); err2 != nil && !isClosedConnError(err2) {
+			log.Debug().Err(err2).Msgf("Failed to copy from pod %s to local", podName)
+		}
+		close(remoteDone)
+	}()
+	go func() {
+		// inform pod no more data after local connection closed
+		defer dataStream.Close()
+		if _, err2 := io.Copy(dataStream, conn); err2 != nil && !isClosedConnError(err2) {
+			log.Debug().Err(err2).Msgf("Failed to copy from local to pod %s", podName)
+			close(localError)
+		}
+	}()
+	select {
+	case <-remoteDone:
+	case <-localError:
+	}
+	return <-errorChan
+}
+
+// getPodConnection get existing upgraded connection to pod, or create a new one
+func getPodConnection(podName string) (httpstream.Connection, error) {
+	podConnectionsLock.Lock()
+	defer podConnectionsLock.Unlock()
+	if streamConn, exists := podConnections[podName]; exists {
+		select {
+		case <-streamConn.CloseChan():
+			delete(podConnections, podName)
+		default:
+			return streamConn, nil
+		}
+	}
+	dialer, err := createPortForwardDialer(podName)
+	if err != nil {
+		return nil, err
+	}
+	streamConn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect pod %s: %s", podName, err)
+	}
+	log.Debug().Msgf("Port forward connection to pod %s created", podName)
+	podConnections[podName] = streamConn
+	return streamConn, nil
+}
+
+func closePodConnection(podName string, streamConn httpstream.Connection) {
+	podConnectionsLock.Lock()
+	defer podConnectionsLock.Unlock()
+	if podConnections[podName] == streamConn {
+		delete(podConnections, podName)
+	}
+	_ = streamConn.Close()
+}
+
+func isClosedConnError(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/alibaba/kt-connect/pkg/kt/service/cluster"
 	"github.com/alibaba/kt-connect/pkg/kt/util"
 	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/transport/spdy"
 	"net/http"
@@ -60,8 +61,18 @@ func setupPortForwardToLocal(podName string, remotePort, localPort int, gone cha
 
 // createPortForwarder fetch a port forward handler
 func createPortForwarder(podName string, remotePort, localPort int, stop, ready chan struct{}) (*portforward.PortForwarder, error) {
-	apiPath := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", opt.Get().Global.Namespace, podName)
 	log.Debug().Msgf("Request port forward pod:%d -> local:%d via %s", remotePort, localPort, opt.Store.RestConfig.Host)
+	dialer, err := createPortForwardDialer(podName)
+	if err != nil {
+		return nil, err
+	}
+	ports := []string{fmt.Sprintf("%d:%d", localPort, remotePort)}
+	return portforward.New(dialer, ports, stop, ready, util.BackgroundLogger, util.BackgroundLogger)
+}
+
+// createPortForwardDialer create dialer to upgrade connection of pod port forward api
+func createPortForwardDialer(podName string) (httpstream.Dialer, error) {
+	apiPath := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", opt.Get().Global.Namespace, podName)
 	apiUrl, err := parseReqHost(opt.Store.RestConfig.Host, apiPath)
 	if err != nil {
 		return nil, err
@@ -71,10 +82,7 @@ func createPortForwarder(podName string, remotePort, localPort int, stop, ready
 	if err != nil {
 		return nil, err
 	}
-
-	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, apiUrl)
-	ports := []string{fmt.Sprintf("%d:%d", localPort, remotePort)}
-	return portforward.New(dialer, ports, stop, ready, util.BackgroundLogger, util.BackgroundLogger)
+	return spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, apiUrl), nil
 }
 
 // parseReqHost get the final url to port forward api
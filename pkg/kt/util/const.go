@@ -36,6 +36,10 @@ const (
 	ExchangeModeEphemeral = "ephemeral"
 	// ExchangeModeSelector selector mode
 	ExchangeModeSelector = "selector"
+	// ExchangeModeCanary canary mode
+	ExchangeModeCanary = "canary"
+	// ExchangeModeEndpoint endpoint slice mode
+	ExchangeModeEndpoint = "endpoint"
 	// MeshModeAuto auto mode
 	MeshModeAuto = "auto"
 	// MeshModeManual manual mode
@@ -50,6 +54,30 @@ const (
 	DnsOrderCluster = "cluster"
 	// DnsOrderUpstream proxy to upstream dns
 	DnsOrderUpstream = "upstream"
+	// WorkloadDeployment deployment workload
+	WorkloadDeployment = "deployment"
+	// WorkloadStatefulSet stateful set workload
+	WorkloadStatefulSet = "statefulset"
+	// WorkloadReplicaSet replica set workload
+	WorkloadReplicaSet = "replicaset"
+	// WorkloadDaemonSet daemon set workload
+	WorkloadDaemonSet = "daemonset"
+	// WorkloadRollout argo rollout workload
+	WorkloadRollout = "rollout"
+
+	// SidecarInjectAuto inject sidecar to kt pods when target namespace or workload is injected
+	SidecarInjectAuto = "auto"
+	// SidecarInjectNone never inject sidecar to kt pods
+	SidecarInjectNone = "none"
+	// SidecarIstio istio sidecar proxy
+	SidecarIstio = "istio"
+	// SidecarLinkerd linkerd sidecar proxy
+	SidecarLinkerd = "linkerd"
+
+	// PreviewIngress expose preview service via ingress
+	PreviewIngress = "ingress"
+	// PreviewHttpRoute expose preview service via gateway api http route
+	PreviewHttpRoute = "httproute"
 
 	// ControlBy label used for mark shadow pod
 	ControlBy = "control-by"
@@ -69,11 +97,29 @@ const (
 	KtLastHeartBeat = "kt-last-heart-beat"
 	// KtLock annotation used for avoid auto mesh conflict
 	KtLock = "kt-lock"
+	// KtPause node selector used for pause daemon set
+	KtPause = "kt-pause"
+	// KtCanary label shared by original pods and shadow pods in canary exchange
+	KtCanary = "kt-canary"
+	// KtEndpointSlice annotation used for record endpoint slice name of exchanged service
+	KtEndpointSlice = "kt-endpoint-slice"
+	// EndpointSliceServiceName label of endpoint slice used for mark its service
+	EndpointSliceServiceName = "kubernetes.io/service-name"
+	// EndpointSliceManagedBy label of endpoint slice used for mark its manager
+	EndpointSliceManagedBy = "endpointslice.kubernetes.io/managed-by"
+	// EndpointSliceController manager name of endpoint slices created by kubernetes
+	EndpointSliceController = "endpointslice-controller.k8s.io"
+	// EndpointSliceManager manager name of endpoint slices created by kt
+	EndpointSliceManager = "kt-connect"
 
 	// PostfixRsaKey postfix of local private key name
 	PostfixRsaKey = ".key"
 	// RouterBin path to router executable
 	RouterBin = "/usr/sbin/router"
+	// NavigatorBin path to navigator executable
+	NavigatorBin = "/usr/sbin/navigator"
+	// NavigatorLeaseSeconds redirect rules in ephemeral container are removed if not renewed in this period
+	NavigatorLeaseSeconds = 60
 	// SshBitSize ssh bit size
 	SshBitSize = 2048
 	// SshAuthKey auth key name
@@ -104,12 +150,18 @@ const (
 	RoleMeshShadow = "shadow-mesh"
 	// RolePreviewShadow shadow role
 	RolePreviewShadow = "shadow-preview"
+	// RoleForwardShadow shadow role
+	RoleForwardShadow = "shadow-forward"
 	// RoleRouter router role
 	RoleRouter = "router"
 	// SortByName birdseye sort
 	SortByName = "name"
 	// SortByStatus birdseye sort
 	SortByStatus = "status"
+	// OutputJson machine-readable output in json format
+	OutputJson = "json"
+	// OutputYaml machine-readable output in yaml format
+	OutputYaml = "yaml"
 	// TunNameWin tun device name in windows
 	TunNameWin = "KtConnectTunnel"
 	// TunNameLinux tun device name in linux
@@ -133,4 +185,5 @@ var (
 	KtLockDir = fmt.Sprintf("%s/lock", KtHome)
 	KtProfileDir = fmt.Sprintf("%s/profile", KtHome)
 	KtConfigFile = fmt.Sprintf("%s/config", KtHome)
+	KtAuditLogFile = fmt.Sprintf("%s/audit.log", KtHome)
 )
\ No newline at end of file
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"github.com/rs/zerolog/log"
 	"net"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const IpAddrPattern = "[0-9]+\\.[0-9]+\\.[0-9]+\\.[0-9]+"
@@ -65,6 +67,31 @@ func FindBrokenLocalPort(exposePorts string) string {
 	return ""
 }
 
+// CheckLocalHealth Check whether local application is ready to serve
+// Request the health path via first local port if specified, otherwise check all ports has process listening to
+func CheckLocalHealth(exposePorts, healthPath string) error {
+	if healthPath == "" {
+		if port := FindBrokenLocalPort(exposePorts); port != "" {
+			return fmt.Errorf("no application is running on port %s", port)
+		}
+		return nil
+	}
+	localPort := strings.Split(strings.Split(exposePorts, ",")[0], ":")[0]
+	if !strings.HasPrefix(healthPath, "/") {
+		healthPath = "/" + healthPath
+	}
+	client := http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%s%s", localPort, healthPath))
+	if err != nil {
+		return fmt.Errorf("health check on port %s failed: %s", localPort, err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("health check on port %s returned status %d", localPort, resp.StatusCode)
+	}
+	return nil
+}
+
 // FindInvalidRemotePort Check if all ports exist in provide service
 func FindInvalidRemotePort(exposePorts string, svcPorts map[int]string) string {
 	validPorts := make([]string, 0)
@@ -2,6 +2,9 @@ package util
 
 import (
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -11,3 +14,19 @@ func TestExtractHostIp(t *testing.T) {
 	require.Equal(t, "1.2.3.4", ExtractHostIp("http://1.2.3.4:8080/a/b/c"))
 	require.Equal(t, "127.0.0.1", ExtractHostIp("http://localhost:8080/a/b/c"))
 }
+
+func TestCheckLocalHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	port := strings.Split(server.Listener.Addr().String(), ":")[1]
+	require.NoError(t, CheckLocalHealth(port, ""))
+	require.NoError(t, CheckLocalHealth(port+":80", "/healthz"))
+	require.NoError(t, CheckLocalHealth(port, "healthz"))
+	require.Error(t, CheckLocalHealth(port, "/ready"))
+}
@@ -0,0 +1,38 @@
+package navigator
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LeaseFile record expire time of redirect rules, rules are removed by guard process once lease expired
+var LeaseFile = "/var/kt-navigator.lease"
+
+// Renew extend lease of redirect rules
+func Renew(seconds int) error {
+	expireAt := time.Now().Add(time.Duration(seconds) * time.Second).Unix()
+	return os.WriteFile(LeaseFile, []byte(strconv.FormatInt(expireAt, 10)), 0644)
+}
+
+// Release remove lease, guard process exits without teardown
+func Release() error {
+	if err := os.Remove(LeaseFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// LeaseStatus check lease status, return whether lease exists and whether it's expired
+func LeaseStatus() (exists bool, expired bool) {
+	content, err := os.ReadFile(LeaseFile)
+	if err != nil {
+		return false, false
+	}
+	expireAt, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return true, true
+	}
+	return true, time.Now().Unix() > expireAt
+}
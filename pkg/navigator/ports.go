@@ -0,0 +1,67 @@
+package navigator
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ProtocolTcp tcp protocol
+	ProtocolTcp = "tcp"
+	// ProtocolUdp udp protocol
+	ProtocolUdp = "udp"
+	// state code of listening tcp socket, see include/net/tcp_states.h
+	tcpStateListen = "0A"
+)
+
+// ListenedPorts get tcp ports in listen state and bound udp ports of current network namespace,
+// both ipv4 and ipv6 sockets are included
+func ListenedPorts() map[string][]int {
+	ports := map[string][]int{}
+	for _, protocol := range []string{ProtocolTcp, ProtocolUdp} {
+		found := map[int]bool{}
+		for _, file := range []string{"/proc/net/" + protocol, "/proc/net/" + protocol + "6"} {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				// ipv6 may be disabled
+				continue
+			}
+			for _, port := range parseProcNet(string(content), protocol) {
+				found[port] = true
+			}
+		}
+		for port := range found {
+			ports[protocol] = append(ports[protocol], port)
+		}
+		sort.Ints(ports[protocol])
+	}
+	return ports
+}
+
+// parseProcNet parse content of /proc/net/{tcp,tcp6,udp,udp6}, line format is like
+// "sl local_address rem_address st ...", e.g. "0: 00000000:1F90 00000000:0000 0A ..."
+func parseProcNet(content, protocol string) []int {
+	ports := make([]int, 0)
+	for i, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 4 {
+			// skip header line
+			continue
+		}
+		if protocol == ProtocolTcp && fields[3] != tcpStateListen {
+			continue
+		}
+		index := strings.LastIndex(fields[1], ":")
+		if index < 0 {
+			continue
+		}
+		port, err := strconv.ParseInt(fields[1][index+1:], 16, 32)
+		if err != nil || port == 0 {
+			continue
+		}
+		ports = append(ports, int(port))
+	}
+	return ports
+}
@@ -0,0 +1,25 @@
+package navigator
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_parseProcNet(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21456 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21457 1 0000000000000000 100 0 0 10 0
+   2: 0A00020F:1F90 0A000201:C350 01 00000000:00000000 00:00000000 00000000     0        0 21458 1 0000000000000000 20 4 30 10 -1
+`
+	require.Equal(t, []int{8080, 22}, parseProcNet(tcp, ProtocolTcp))
+
+	tcp6 := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:1F91 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 21460 1 0000000000000000 100 0 0 10 0
+`
+	require.Equal(t, []int{8081}, parseProcNet(tcp6, ProtocolTcp))
+
+	udp := `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  100: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 21461 2 0000000000000000 0
+`
+	require.Equal(t, []int{53}, parseProcNet(udp, ProtocolUdp))
+}
@@ -0,0 +1,185 @@
+package navigator
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	// name of iptables chain and nftables table holding redirect rules
+	iptablesChain = "KT_EXCHANGE"
+	nftTable      = "kt_exchange"
+)
+
+// Rule redirect traffic to port of specified protocol to another port
+type Rule struct {
+	Protocol     string
+	Port         int
+	RedirectPort int
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s/%d:%d", r.Protocol, r.Port, r.RedirectPort)
+}
+
+// Backend tool to manipulate netfilter rules
+type Backend interface {
+	// Setup add redirect rules, when proxyUid is positive, the pod has a service mesh sidecar which captures
+	// all inbound traffic, thus only traffic forwarded by the sidecar proxy (running as proxyUid) to local is redirected
+	Setup(rules []Rule, proxyUid int) error
+	Teardown() error
+}
+
+// runCommand execute command and return error with its output, replaced in test
+var runCommand = func(name string, args ...string) error {
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s %s failed: %s, %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// lookPath check whether executable exists, replaced in test
+var lookPath = func(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// ParseRules parse rules in "[protocol/]port:redirectPort" format separated by ',', protocol is tcp by default
+func ParseRules(text string) ([]Rule, error) {
+	rules := make([]Rule, 0)
+	for _, item := range strings.Split(text, ",") {
+		if item == "" {
+			continue
+		}
+		rule := Rule{Protocol: ProtocolTcp}
+		if index := strings.Index(item, "/"); index > 0 {
+			rule.Protocol = item[0:index]
+			item = item[index+1:]
+		}
+		if rule.Protocol != ProtocolTcp && rule.Protocol != ProtocolUdp {
+			return nil, fmt.Errorf("invalid protocol %s", rule.Protocol)
+		}
+		ports := strings.Split(item, ":")
+		if len(ports) != 2 {
+			return nil, fmt.Errorf("invalid redirect rule %s", item)
+		}
+		var err error
+		if rule.Port, err = strconv.Atoi(ports[0]); err != nil {
+			return nil, fmt.Errorf("invalid port %s", ports[0])
+		}
+		if rule.RedirectPort, err = strconv.Atoi(ports[1]); err != nil {
+			return nil, fmt.Errorf("invalid port %s", ports[1])
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// DetectBackend use iptables if available (either legacy or nft variant), otherwise use nft directly
+func DetectBackend() (Backend, error) {
+	if lookPath("iptables") {
+		binaries := []string{"iptables"}
+		if lookPath("ip6tables") {
+			binaries = append(binaries, "ip6tables")
+		}
+		return &iptablesBackend{binaries: binaries}, nil
+	} else if lookPath("nft") {
+		return &nftBackend{}, nil
+	}
+	return nil, fmt.Errorf("neither iptables nor nft is available")
+}
+
+type iptablesBackend struct {
+	binaries []string
+}
+
+// Setup add a dedicated chain to nat table, and jump to it from PREROUTING chain,
+// or from OUTPUT chain when traffic is forwarded by sidecar proxy
+func (b *iptablesBackend) Setup(rules []Rule, proxyUid int) error {
+	fromChain := "PREROUTING"
+	if proxyUid > 0 {
+		fromChain = "OUTPUT"
+	}
+	for _, bin := range b.binaries {
+		// chain may already exist when setup more than once
+		_ = runCommand(bin, "-t", "nat", "-N", iptablesChain)
+		if err := runCommand(bin, "-t", "nat", "-F", iptablesChain); err != nil {
+			return err
+		}
+		for _, rule := range rules {
+			args := []string{"-t", "nat", "-A", iptablesChain, "-p", rule.Protocol, "--dport", strconv.Itoa(rule.Port)}
+			if proxyUid > 0 {
+				// only connections of sidecar proxy to local app, not those to remote hosts
+				args = append(args, "-m", "owner", "--uid-owner", strconv.Itoa(proxyUid), "-m", "addrtype", "--dst-type", "LOCAL")
+			}
+			args = append(args, "-j", "REDIRECT", "--to-ports", strconv.Itoa(rule.RedirectPort))
+			if err := runCommand(bin, args...); err != nil {
+				return err
+			}
+		}
+		// jump rule must be ahead of the sidecar's, which returns traffic from proxy uid without redirect
+		if runCommand(bin, "-t", "nat", "-C", fromChain, "-j", iptablesChain) != nil {
+			if err := runCommand(bin, "-t", "nat", "-I", fromChain, "-j", iptablesChain); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Teardown remove the jump rules and the dedicated chain
+func (b *iptablesBackend) Teardown() error {
+	var lastErr error
+	for _, bin := range b.binaries {
+		for _, fromChain := range []string{"PREROUTING", "OUTPUT"} {
+			for runCommand(bin, "-t", "nat", "-D", fromChain, "-j", iptablesChain) == nil {
+				// remove all duplicated jump rules
+			}
+		}
+		if err := runCommand(bin, "-t", "nat", "-F", iptablesChain); err != nil {
+			lastErr = err
+			continue
+		}
+		if err := runCommand(bin, "-t", "nat", "-X", iptablesChain); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+type nftBackend struct{}
+
+// Setup add a dedicated table of inet family, which covers both ipv4 and ipv6
+func (b *nftBackend) Setup(rules []Rule, proxyUid int) error {
+	chain, hook := "prerouting", "prerouting"
+	if proxyUid > 0 {
+		chain, hook = "output", "output"
+	}
+	commands := [][]string{
+		{"add", "table", "inet", nftTable},
+		{"add", "chain", "inet", nftTable, chain, "{ type nat hook " + hook + " priority -100 ; }"},
+		{"flush", "chain", "inet", nftTable, chain},
+	}
+	for _, rule := range rules {
+		args := []string{"add", "rule", "inet", nftTable, chain}
+		if proxyUid > 0 {
+			// only connections of sidecar proxy to local app, not those to remote hosts
+			args = append(args, "meta", "skuid", strconv.Itoa(proxyUid), "fib", "daddr", "type", "local")
+		}
+		args = append(args, rule.Protocol, "dport", strconv.Itoa(rule.Port), "redirect", "to", ":"+strconv.Itoa(rule.RedirectPort))
+		commands = append(commands, args)
+	}
+	for _, args := range commands {
+		if err := runCommand("nft", args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Teardown delete the dedicated table
+func (b *nftBackend) Teardown() error {
+	return runCommand("nft", "delete", "table", "inet", nftTable)
+}
@@ -0,0 +1,102 @@
+package navigator
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("80:12345,udp/53:12346")
+	require.NoError(t, err)
+	require.Equal(t, []Rule{{ProtocolTcp, 80, 12345}, {ProtocolUdp, 53, 12346}}, rules)
+	_, err = ParseRules("sctp/80:12345")
+	require.Error(t, err)
+	_, err = ParseRules("80")
+	require.Error(t, err)
+}
+
+func TestIptablesBackend(t *testing.T) {
+	commands := make([]string, 0)
+	jumpRules := 0
+	runCommand = func(name string, args ...string) error {
+		cmd := name + " " + strings.Join(args, " ")
+		commands = append(commands, cmd)
+		if strings.Contains(cmd, " -I ") {
+			jumpRules++
+		} else if strings.Contains(cmd, " -C ") && jumpRules == 0 {
+			return fmt.Errorf("rule not exist")
+		} else if strings.Contains(cmd, " -D ") {
+			if jumpRules == 0 {
+				return fmt.Errorf("rule not exist")
+			}
+			jumpRules--
+		}
+		return nil
+	}
+	lookPath = func(name string) bool {
+		return name == "iptables"
+	}
+	backend, err := DetectBackend()
+	require.NoError(t, err)
+	require.NoError(t, backend.Setup([]Rule{{ProtocolTcp, 80, 12345}}, 0))
+	require.Equal(t, []string{
+		"iptables -t nat -N KT_EXCHANGE",
+		"iptables -t nat -F KT_EXCHANGE",
+		"iptables -t nat -A KT_EXCHANGE -p tcp --dport 80 -j REDIRECT --to-ports 12345",
+		"iptables -t nat -C PREROUTING -j KT_EXCHANGE",
+		"iptables -t nat -I PREROUTING -j KT_EXCHANGE",
+	}, commands)
+
+	commands = commands[:0]
+	require.NoError(t, backend.Teardown())
+	require.Equal(t, []string{
+		"iptables -t nat -D PREROUTING -j KT_EXCHANGE",
+		"iptables -t nat -D PREROUTING -j KT_EXCHANGE",
+		"iptables -t nat -D OUTPUT -j KT_EXCHANGE",
+		"iptables -t nat -F KT_EXCHANGE",
+		"iptables -t nat -X KT_EXCHANGE",
+	}, commands)
+
+	commands = commands[:0]
+	require.NoError(t, backend.Setup([]Rule{{ProtocolTcp, 80, 12345}}, 1337))
+	require.Equal(t, []string{
+		"iptables -t nat -N KT_EXCHANGE",
+		"iptables -t nat -F KT_EXCHANGE",
+		"iptables -t nat -A KT_EXCHANGE -p tcp --dport 80 -m owner --uid-owner 1337 -m addrtype --dst-type LOCAL -j REDIRECT --to-ports 12345",
+		"iptables -t nat -C OUTPUT -j KT_EXCHANGE",
+		"iptables -t nat -I OUTPUT -j KT_EXCHANGE",
+	}, commands)
+}
+
+func TestNftBackend(t *testing.T) {
+	commands := make([]string, 0)
+	runCommand = func(name string, args ...string) error {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return nil
+	}
+	lookPath = func(name string) bool {
+		return name == "nft"
+	}
+	backend, err := DetectBackend()
+	require.NoError(t, err)
+	require.NoError(t, backend.Setup([]Rule{{ProtocolTcp, 80, 12345}}, 2102))
+	require.Equal(t, []string{
+		"nft add table inet kt_exchange",
+		"nft add chain inet kt_exchange output { type nat hook output priority -100 ; }",
+		"nft flush chain inet kt_exchange output",
+		"nft add rule inet kt_exchange output meta skuid 2102 fib daddr type local tcp dport 80 redirect to :12345",
+	}, commands)
+}
+
+func TestParseRelayRules(t *testing.T) {
+	rules, err := ParseRelayRules("20053:20054:53,")
+	require.NoError(t, err)
+	require.Equal(t, []RelayRule{{20053, 20054, 53}}, rules)
+	require.Equal(t, "20053:20054:53", rules[0].String())
+	_, err = ParseRelayRules("20053:20054")
+	require.Error(t, err)
+	_, err = ParseRelayRules("20053:x:53")
+	require.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package navigator
+
+import (
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
+	"github.com/alibaba/kt-connect/pkg/shadow/udprelay"
+	"github.com/rs/zerolog/log"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// RelayRule carry datagrams received on udp port through tcp tunnel port, ssh reverse tunnel only
+// supports tcp, thus the tunnel is connected to an udp relay in ktctl, which sends them to target port
+type RelayRule struct {
+	Port       int
+	TunnelPort int
+	TargetPort int
+}
+
+func (r RelayRule) String() string {
+	return fmt.Sprintf("%d:%d:%d", r.Port, r.TunnelPort, r.TargetPort)
+}
+
+// ParseRelayRules parse rules in "port:tunnelPort:targetPort" format separated by ','
+func ParseRelayRules(text string) ([]RelayRule, error) {
+	rules := make([]RelayRule, 0)
+	for _, item := range strings.Split(text, ",") {
+		if item == "" {
+			continue
+		}
+		ports := strings.Split(item, ":")
+		if len(ports) != 3 {
+			return nil, fmt.Errorf("invalid relay rule %s", item)
+		}
+		numbers := make([]int, 3)
+		for i, p := range ports {
+			var err error
+			if numbers[i], err = strconv.Atoi(p); err != nil {
+				return nil, fmt.Errorf("invalid port %s", p)
+			}
+		}
+		rules = append(rules, RelayRule{Port: numbers[0], TunnelPort: numbers[1], TargetPort: numbers[2]})
+	}
+	return rules, nil
+}
+
+// StartRelay listen udp port of each rule, and relay datagrams through the tunnel port
+func StartRelay(rules []RelayRule) error {
+	for _, rule := range rules {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: rule.Port})
+		if err != nil {
+			return fmt.Errorf("failed to listen udp port %d: %s", rule.Port, err)
+		}
+		tunnel := net.JoinHostPort(common.Localhost, strconv.Itoa(rule.TunnelPort))
+		go udprelay.Relay(conn, net.JoinHostPort(common.Localhost, strconv.Itoa(rule.TargetPort)), func() (net.Conn, error) {
+			return net.Dial("tcp", tunnel)
+		})
+		log.Info().Msgf("Udp relay %s started", rule)
+	}
+	return nil
+}
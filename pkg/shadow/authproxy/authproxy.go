@@ -0,0 +1,219 @@
+package authproxy
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// tokenKey name of query parameter and cookie to carry token, for browsers which cannot set header
+	tokenKey = "kt_token"
+	realm    = "kt-connect preview"
+)
+
+// AuthProxy proxy which only forwards requests passed access control to upstream
+type AuthProxy struct {
+	config  *common.AuthConfig
+	cidrs   []*net.IPNet
+	proxies []*net.IPNet
+}
+
+// Start run a proxy for each port pair in "<listen>:<upstream>" format, upstream is listened on localhost
+func Start(ports string, configFile string) error {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return err
+	}
+	var config common.AuthConfig
+	if err = json.Unmarshal(content, &config); err != nil {
+		return fmt.Errorf("invalid auth config: %s", err)
+	}
+	p, err := NewAuthProxy(&config)
+	if err != nil {
+		return err
+	}
+	for _, pair := range strings.Split(ports, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid port pair '%s'", pair)
+		}
+		listenPort, err2 := strconv.Atoi(parts[0])
+		if err2 != nil {
+			return fmt.Errorf("invalid listen port '%s'", parts[0])
+		}
+		upstreamPort, err2 := strconv.Atoi(parts[1])
+		if err2 != nil {
+			return fmt.Errorf("invalid upstream port '%s'", parts[1])
+		}
+		if config.Type == "" {
+			go p.serveTcp(listenPort, upstreamPort)
+		} else {
+			go p.serveHttp(listenPort, upstreamPort)
+		}
+	}
+	return nil
+}
+
+// NewAuthProxy create auth proxy with specified config
+func NewAuthProxy(config *common.AuthConfig) (*AuthProxy, error) {
+	cidrs, err := parseCidrs(config.AllowCidrs)
+	if err != nil {
+		return nil, err
+	}
+	proxies, err := parseCidrs(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthProxy{config: config, cidrs: cidrs, proxies: proxies}, nil
+}
+
+// Handler wrap upstream handler with access control
+func (p *AuthProxy) Handler(upstream http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := p.clientIp(r); !p.isAllowed(ip) {
+			log.Info().Msgf("Request from %s denied", ip)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if !p.authenticate(w, r) {
+			return
+		}
+		upstream.ServeHTTP(w, r)
+	})
+}
+
+func (p *AuthProxy) serveHttp(listenPort, upstreamPort int) {
+	upstream := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: net.JoinHostPort(common.Localhost, strconv.Itoa(upstreamPort))})
+	log.Info().Msgf("Auth proxy listening on port %d with %s auth", listenPort, p.config.Type)
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", listenPort), p.Handler(upstream)); err != nil {
+		log.Error().Err(err).Msgf("Auth proxy on port %d stopped", listenPort)
+	}
+}
+
+func (p *AuthProxy) serveTcp(listenPort, upstreamPort int) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", listenPort))
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to listen port %d", listenPort)
+		return
+	}
+	log.Info().Msgf("Auth proxy listening on port %d with client address checking", listenPort)
+	for {
+		conn, err2 := listener.Accept()
+		if err2 != nil {
+			log.Warn().Err(err2).Msgf("Failed to accept connection")
+			continue
+		}
+		go p.handleTcp(conn, upstreamPort)
+	}
+}
+
+func (p *AuthProxy) handleTcp(conn net.Conn, upstreamPort int) {
+	defer conn.Close()
+	host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	if !p.isAllowed(net.ParseIP(host)) {
+		log.Info().Msgf("Connection from %s denied", host)
+		return
+	}
+	upstream, err := net.Dial("tcp", net.JoinHostPort(common.Localhost, strconv.Itoa(upstreamPort)))
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to connect upstream port %d", upstreamPort)
+		return
+	}
+	defer upstream.Close()
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+	}()
+	_, _ = io.Copy(conn, upstream)
+}
+
+func (p *AuthProxy) isAllowed(ip net.IP) bool {
+	return len(p.cidrs) == 0 || contains(p.cidrs, ip)
+}
+
+// authenticate check credential of request, the credential is removed before forwarding to upstream
+func (p *AuthProxy) authenticate(w http.ResponseWriter, r *http.Request) bool {
+	switch p.config.Type {
+	case common.AuthTypeBasic:
+		username, password, ok := r.BasicAuth()
+		if !ok || !secureEqual(username, p.config.Username) || !secureEqual(password, p.config.Password) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Basic realm="%s"`, realm))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return false
+		}
+		r.Header.Del("Authorization")
+	case common.AuthTypeToken:
+		if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); secureEqual(token, p.config.Token) {
+			r.Header.Del("Authorization")
+		} else if cookie, err := r.Cookie(tokenKey); err == nil && secureEqual(cookie.Value, p.config.Token) {
+			// cookie is kept, since upstream may also read other cookies
+		} else if query := r.URL.Query(); secureEqual(query.Get(tokenKey), p.config.Token) {
+			// token in url is saved to cookie, so that following requests of browser are also authenticated
+			http.SetCookie(w, &http.Cookie{Name: tokenKey, Value: p.config.Token, Path: "/", HttpOnly: true})
+			query.Del(tokenKey)
+			r.URL.RawQuery = query.Encode()
+		} else {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s"`, realm))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return false
+		}
+	}
+	return true
+}
+
+// clientIp get address of client, requests forwarded by trusted proxy (e.g. ingress controller)
+// use the address appended to X-Forwarded-For header by that proxy
+func (p *AuthProxy) clientIp(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if contains(p.proxies, ip) {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			addresses := strings.Split(forwarded, ",")
+			if forwardedIp := net.ParseIP(strings.TrimSpace(addresses[len(addresses)-1])); forwardedIp != nil {
+				return forwardedIp
+			}
+		}
+	}
+	return ip
+}
+
+func parseCidrs(cidrs []string) ([]*net.IPNet, error) {
+	var ipNets []*net.IPNet
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr '%s'", cidr)
+		}
+		ipNets = append(ipNets, ipNet)
+	}
+	return ipNets, nil
+}
+
+func contains(ipNets []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range ipNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func secureEqual(actual, expected string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) == 1
+}
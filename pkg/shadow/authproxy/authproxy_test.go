@@ -0,0 +1,90 @@
+package authproxy
+
+import (
+	"github.com/alibaba/kt-connect/pkg/common"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serve(t *testing.T, config *common.AuthConfig, r *http.Request) *httptest.ResponseRecorder {
+	p, err := NewAuthProxy(config)
+	require.Nil(t, err)
+	w := httptest.NewRecorder()
+	p.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Empty(t, r.Header.Get("Authorization"))
+		require.Empty(t, r.URL.Query().Get(tokenKey))
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, r)
+	return w
+}
+
+func TestBasicAuth(t *testing.T) {
+	config := &common.AuthConfig{Type: common.AuthTypeBasic, Username: "kt", Password: "secret"}
+	r := httptest.NewRequest("GET", "/", nil)
+	w := serve(t, config, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.SetBasicAuth("kt", "wrong")
+	require.Equal(t, http.StatusUnauthorized, serve(t, config, r).Code)
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.SetBasicAuth("kt", "secret")
+	require.Equal(t, http.StatusOK, serve(t, config, r).Code)
+}
+
+func TestTokenAuth(t *testing.T) {
+	config := &common.AuthConfig{Type: common.AuthTypeToken, Token: "abc"}
+	require.Equal(t, http.StatusUnauthorized, serve(t, config, httptest.NewRequest("GET", "/", nil)).Code)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer abc")
+	require.Equal(t, http.StatusOK, serve(t, config, r).Code)
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: tokenKey, Value: "abc"})
+	require.Equal(t, http.StatusOK, serve(t, config, r).Code)
+
+	w := serve(t, config, httptest.NewRequest("GET", "/?kt_token=abc&a=1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Set-Cookie"), "kt_token=abc")
+
+	require.Equal(t, http.StatusUnauthorized, serve(t, config, httptest.NewRequest("GET", "/?kt_token=x", nil)).Code)
+}
+
+func TestAllowCidrs(t *testing.T) {
+	config := &common.AuthConfig{AllowCidrs: []string{"1.2.3.0/24"}, TrustedProxies: []string{"10.0.0.0/24"}}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "1.2.3.4:5678"
+	require.Equal(t, http.StatusOK, serve(t, config, r).Code)
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "5.6.7.8:5678"
+	require.Equal(t, http.StatusForbidden, serve(t, config, r).Code)
+
+	// forwarded by trusted proxy
+	r = httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5678"
+	r.Header.Set("X-Forwarded-For", "5.6.7.8, 1.2.3.4")
+	require.Equal(t, http.StatusOK, serve(t, config, r).Code)
+
+	// public client cannot fake its address
+	r = httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "5.6.7.8:5678"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4")
+	require.Equal(t, http.StatusForbidden, serve(t, config, r).Code)
+
+	// untrusted in-cluster client cannot fake its address either
+	r = httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.1.1:5678"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4")
+	require.Equal(t, http.StatusForbidden, serve(t, config, r).Code)
+
+	_, err := NewAuthProxy(&common.AuthConfig{AllowCidrs: []string{"1.2.3.4"}})
+	require.NotNil(t, err)
+	_, err = NewAuthProxy(&common.AuthConfig{TrustedProxies: []string{"10.0.0.1"}})
+	require.NotNil(t, err)
+}
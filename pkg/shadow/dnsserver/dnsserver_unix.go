@@ -12,10 +12,18 @@ import (
 	"time"
 )
 
+const (
+	// max seconds to cache a dns record
+	dnsCacheMaxTtl = 60
+	// udp buffer size advertised to upstream dns servers
+	ednsUdpSize = 4096
+)
+
 // DnsServer nds server
 type DnsServer struct {
 	localDomain string
 	config *dns.ClientConfig
+	cache *common.DnsCache
 }
 
 // Start setup dns server
@@ -27,7 +35,16 @@ func Start(dnsPort int, dnsProtocol string, localDomain string) {
 	for _, domain := range config.Search {
 		log.Info().Msgf("Load search %s", domain)
 	}
-	err := common.SetupDnsServer(&DnsServer{localDomain, config}, dnsPort, dnsProtocol)
+	log.Info().Msgf("Load options ndots:%d attempts:%d timeout:%d", config.Ndots, config.Attempts, config.Timeout)
+	s := &DnsServer{localDomain: localDomain, config: config}
+	s.cache = common.NewDnsCache(common.DefaultDnsCacheSize, dnsCacheMaxTtl,
+		func(name string, qtype uint16) { s.resolve(name, qtype, nil) })
+	go func() {
+		if err := s.cache.ServeMetrics(fmt.Sprintf(":%d", common.StandardDnsMetricsPort)); err != nil {
+			log.Warn().Err(err).Msgf("Failed to expose dns cache metrics")
+		}
+	}()
+	err := common.SetupDnsServer(s, dnsPort, dnsProtocol)
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to start dns server")
 	}
@@ -40,6 +57,17 @@ func (s *DnsServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
 	msg.Answer = s.query(req)
 	log.Info().Msgf("Answer: %v", msg.Answer)
 
+	size := dns.MinMsgSize
+	if opt := req.IsEdns0(); opt != nil {
+		msg.SetEdns0(opt.UDPSize(), opt.Do())
+		if opt.UDPSize() > dns.MinMsgSize {
+			size = int(opt.UDPSize())
+		}
+	}
+	if w.LocalAddr().Network() == "udp" {
+		// let client retry via tcp if answer is too large
+		msg.Truncate(size)
+	}
 	if err := w.WriteMsg(msg); err != nil {
 		log.Error().Err(err).Msgf("Failed to response")
 	}
@@ -54,12 +82,21 @@ func (s *DnsServer) query(req *dns.Msg) (rr []dns.RR) {
 
 	name := req.Question[0].Name
 	qtype := req.Question[0].Qtype
-	answer := common.ReadCache(name, qtype, 60)
-	if answer != nil {
+	edns := getEdnsToForward(req)
+	if edns != nil {
+		// answer may vary with client subnet or dnssec flag, do not use cache
+		return s.resolve(name, qtype, edns)
+	}
+	if answer, found := s.cache.Get(name, qtype); found {
 		log.Debug().Msgf("Found domain %s (%d) in cache", name, qtype)
 		return answer
 	}
+	return s.resolve(name, qtype, nil)
+}
 
+// Lookup all possible domains of specified name, and record the result to cache if edns is not specified
+func (s *DnsServer) resolve(name string, qtype uint16, edns *dns.OPT) []dns.RR {
+	originName := name
 	if s.localDomain != "" {
 		for _, d := range strings.Split(s.localDomain, ",") {
 			if strings.HasSuffix(name, d+".") {
@@ -70,17 +107,23 @@ func (s *DnsServer) query(req *dns.Msg) (rr []dns.RR) {
 	}
 	log.Info().Msgf("Looking up %s (%d)", name, qtype)
 
-	rr = make([]dns.RR, 0)
+	var authority []dns.RR
 	domainsToLookup := s.fetchAllPossibleDomains(name)
 	for _, domain := range domainsToLookup {
-		r, err := s.lookup(domain, qtype, name)
+		res, err := s.lookup(domain, qtype, name, edns)
 		if err == nil {
-			rr = r
-			break
+			if edns == nil {
+				s.cache.Put(originName, qtype, res.Answer, res.Ns)
+			}
+			return res.Answer
+		} else if res != nil {
+			authority = res.Ns
 		}
 	}
-	common.WriteCache(name, qtype, rr, time.Now().Unix())
-	return
+	if edns == nil {
+		s.cache.Put(originName, qtype, []dns.RR{}, authority)
+	}
+	return []dns.RR{}
 }
 
 // get all domains need to lookup
@@ -130,6 +173,16 @@ func (s *DnsServer) fetchAllPossibleDomains(name string) []string {
 		// raw domain
 		namesToLookup = append(namesToLookup, name)
 	}
+	if s.config.Ndots > 0 && count-1 >= s.config.Ndots && len(namesToLookup) > 1 && namesToLookup[0] != name {
+		// name with enough dots should be looked up as absolute name first, see resolv.conf(5)
+		absoluteFirst := []string{name}
+		for _, n := range namesToLookup {
+			if n != name {
+				absoluteFirst = append(absoluteFirst, n)
+			}
+		}
+		namesToLookup = absoluteFirst
+	}
 	return namesToLookup
 }
 
@@ -149,38 +202,116 @@ func (s *DnsServer) getSuffixes() (suffixes []string) {
 	return
 }
 
-// Get upstream dns server address
-func (s *DnsServer) getResolveServer() (address string, err error) {
+// Get all upstream dns server addresses
+func (s *DnsServer) getResolveServers() ([]string, error) {
 	if len(s.config.Servers) <= 0 {
-		return "", fmt.Errorf("error: no dns server available")
+		return nil, fmt.Errorf("error: no dns server available")
 	}
-
-	return net.JoinHostPort(s.config.Servers[0], s.config.Port), nil
+	var addresses []string
+	for _, server := range s.config.Servers {
+		addresses = append(addresses, net.JoinHostPort(server, s.config.Port))
+	}
+	return addresses, nil
 }
 
 // Look for domain record from upstream dns server
-func (s *DnsServer) lookup(domain string, qtype uint16, name string) ([]dns.RR, error) {
-	address, err := s.getResolveServer()
+func (s *DnsServer) lookup(domain string, qtype uint16, name string, edns *dns.OPT) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.RecursionDesired = true
+	msg.SetQuestion(domain, qtype)
+	setEdns(msg, edns)
+	log.Debug().Msgf("Resolving domain %s (%d) via upstream", domain, qtype)
+
+	res, err := s.exchange(msg)
 	if err != nil {
-		log.Error().Err(err).Msgf("Failed to fetch upstream dns")
-		return []dns.RR{}, err
+		log.Warn().Err(err).Msgf("Failed to answer name %s (%d) query for %s", name, qtype, domain)
+		return nil, err
+	}
+	if res.Rcode == dns.RcodeNameError {
+		log.Debug().Msgf("Domain %s (%d) not exist", domain, qtype)
+		return res, fmt.Errorf("domain %s (%d) not exist", domain, qtype)
+	} else if res.Rcode != dns.RcodeSuccess {
+		log.Warn().Msgf("Failed to answer name %s (%d) query for %s, response code %d", name, qtype, domain, res.Rcode)
+		return res, fmt.Errorf("response code %d", res.Rcode)
+	}
+
+	if len(res.Answer) == 0 {
+		log.Debug().Msgf("Empty answer")
 	}
-	log.Debug().Msgf("Resolving domain %s (%d) via upstream %s", domain, qtype, address)
+	res.Answer = s.convertAnswer(name, res.Answer)
+	return res, nil
+}
 
-	res, err := common.NsLookup(domain, qtype, "udp", address)
+// Send request to upstream dns servers in order, follow the 'attempts' option of resolv.conf
+func (s *DnsServer) exchange(msg *dns.Msg) (*dns.Msg, error) {
+	addresses, err := s.getResolveServers()
 	if err != nil {
-		if common.IsDomainNotExist(err) {
-			log.Debug().Msgf(err.Error())
-		} else {
-			log.Warn().Err(err).Msgf("Failed to answer name %s (%d) query for %s", name, qtype, domain)
+		return nil, err
+	}
+	attempts := s.config.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	timeout := time.Duration(s.config.Timeout) * time.Second
+	for i := 0; i < attempts; i++ {
+		for _, address := range addresses {
+			var res *dns.Msg
+			res, err = exchangeWithServer(msg, address, timeout)
+			if err != nil {
+				log.Debug().Err(err).Msgf("Failed to query upstream %s", address)
+				continue
+			}
+			if res.Rcode == dns.RcodeServerFailure || res.Rcode == dns.RcodeRefused {
+				// try next server
+				log.Debug().Msgf("Upstream %s responded with code %d", address, res.Rcode)
+				err = fmt.Errorf("response code %d", res.Rcode)
+				continue
+			}
+			return res, nil
 		}
-		return []dns.RR{}, err
 	}
+	return nil, err
+}
 
-	if len(res.Answer) == 0 {
-		log.Debug().Msgf("Empty answer")
+// Query single dns server, retry via tcp if udp answer is truncated
+func exchangeWithServer(msg *dns.Msg, address string, timeout time.Duration) (*dns.Msg, error) {
+	c := &dns.Client{Net: "udp", Timeout: timeout}
+	res, _, err := c.Exchange(msg, address)
+	if err == nil && res.Truncated {
+		log.Debug().Msgf("Truncated answer from %s, retry via tcp", address)
+		c.Net = "tcp"
+		res, _, err = c.Exchange(msg, address)
+	}
+	return res, err
+}
+
+// Get EDNS0 options need to pass through, return nil if neither client subnet nor DO bit is specified
+func getEdnsToForward(req *dns.Msg) *dns.OPT {
+	opt := req.IsEdns0()
+	if opt == nil {
+		return nil
+	}
+	edns := &dns.OPT{Hdr: dns.RR_Header{Name: ".", Rrtype: dns.TypeOPT}}
+	edns.SetDo(opt.Do())
+	for _, o := range opt.Option {
+		if o.Option() == dns.EDNS0SUBNET {
+			edns.Option = append(edns.Option, o)
+		}
+	}
+	if !edns.Do() && len(edns.Option) == 0 {
+		return nil
+	}
+	return edns
+}
+
+// Attach EDNS0 record to upstream request, with a larger udp buffer size to reduce truncation
+func setEdns(msg *dns.Msg, edns *dns.OPT) {
+	do := edns != nil && edns.Do()
+	msg.SetEdns0(ednsUdpSize, do)
+	if edns != nil {
+		opt := msg.IsEdns0()
+		opt.Option = append(opt.Option, edns.Option...)
 	}
-	return s.convertAnswer(name, res.Answer), nil
 }
 
 // Replace fully qualified domain name with short domain name in dns answer
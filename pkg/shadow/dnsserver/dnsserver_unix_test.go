@@ -1,7 +1,10 @@
 package dnsserver
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/require"
+	"net"
+	"strconv"
 	"testing"
 
 	"github.com/miekg/dns"
@@ -52,4 +55,72 @@ func TestFetchAllPossibleDomains(t *testing.T) {
 	domains = s.fetchAllPossibleDomains("pod-0.alibaba.ci.svc.cluster.local.")
 	require.Equal(t, 1, len(domains))
 	require.Equal(t, "pod-0.alibaba.ci.svc.cluster.local.", domains[0])
-}
\ No newline at end of file
+}
+
+func TestFetchAllPossibleDomainsWithNdots(t *testing.T) {
+	s := DnsServer{}
+	s.config = &dns.ClientConfig{Ndots: 1}
+	s.config.Search = []string{"default.svc.cluster.local", "svc.cluster.local", "cluster.local"}
+	domains := s.fetchAllPossibleDomains("alibaba.")
+	require.Equal(t, []string{"alibaba.default.svc.cluster.local.", "alibaba."}, domains)
+	domains = s.fetchAllPossibleDomains("alibaba.ci.")
+	require.Equal(t, []string{"alibaba.ci.", "alibaba.ci.svc.cluster.local.", "alibaba.ci.default.svc.cluster.local."}, domains)
+	s.config.Ndots = 5
+	domains = s.fetchAllPossibleDomains("alibaba.ci.")
+	require.Equal(t, "alibaba.ci.svc.cluster.local.", domains[0])
+}
+
+func TestShouldForwardEdnsOptions(t *testing.T) {
+	req := new(dns.Msg)
+	req.SetQuestion("tomcat.", dns.TypeA)
+	require.Nil(t, getEdnsToForward(req))
+	req.SetEdns0(1232, false)
+	require.Nil(t, getEdnsToForward(req))
+
+	subnet := &dns.EDNS0_SUBNET{Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: 24, Address: net.ParseIP("10.1.2.0").To4()}
+	req.IsEdns0().Option = append(req.IsEdns0().Option, subnet, &dns.EDNS0_NSID{Code: dns.EDNS0NSID})
+	req.IsEdns0().SetDo()
+	edns := getEdnsToForward(req)
+	require.NotNil(t, edns)
+	require.True(t, edns.Do())
+	require.Equal(t, 1, len(edns.Option))
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("tomcat.", dns.TypeA)
+	setEdns(msg, edns)
+	require.Equal(t, uint16(ednsUdpSize), msg.IsEdns0().UDPSize())
+	require.True(t, msg.IsEdns0().Do())
+	require.Equal(t, subnet, msg.IsEdns0().Option[0])
+}
+
+func TestShouldFailoverAndRetryTruncatedAnswerViaTcp(t *testing.T) {
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := (&dns.Msg{}).SetReply(req)
+		if w.LocalAddr().Network() == "udp" {
+			msg.Truncated = true
+		} else {
+			rr, _ := dns.NewRR("tomcat. 5 IN A 10.12.4.6")
+			msg.Answer = []dns.RR{rr}
+		}
+		_ = w.WriteMsg(msg)
+	})
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := pc.LocalAddr().(*net.UDPAddr).Port
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+	udpServer := &dns.Server{PacketConn: pc, Handler: handler}
+	tcpServer := &dns.Server{Listener: l, Handler: handler}
+	go func() { _ = udpServer.ActivateAndServe() }()
+	go func() { _ = tcpServer.ActivateAndServe() }()
+	defer func() { _ = udpServer.Shutdown(); _ = tcpServer.Shutdown() }()
+
+	// first server is not reachable
+	s := DnsServer{config: &dns.ClientConfig{Servers: []string{"127.0.0.2", "127.0.0.1"}, Port: strconv.Itoa(port), Timeout: 1}}
+	msg := new(dns.Msg)
+	msg.SetQuestion("tomcat.", dns.TypeA)
+	res, err := s.exchange(msg)
+	require.NoError(t, err)
+	require.False(t, res.Truncated)
+	require.Equal(t, 1, len(res.Answer))
+}
@@ -0,0 +1,149 @@
+package udprelay
+
+import (
+	"errors"
+	"github.com/alibaba/kt-connect/pkg/common"
+	"github.com/rs/zerolog/log"
+	"net"
+	"sync"
+	"time"
+)
+
+// Relay read datagrams from udp listener, datagrams from each client are carried to target
+// through its own stream created by dial, idle streams are closed and reopened on demand
+func Relay(conn *net.UDPConn, target string, dial func() (net.Conn, error)) {
+	sessions := &relaySessions{items: map[string]*relaySession{}}
+	go sessions.reapIdle(common.UdpSessionIdleTimeout)
+	buf := make([]byte, common.MaxUdpFrameSize)
+	for {
+		n, clientAddr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				log.Debug().Err(err).Msgf("Udp listener for %s closed", target)
+				return
+			}
+			log.Warn().Err(err).Msgf("Failed to read datagram for %s", target)
+			continue
+		}
+		// session may be closed by relay, reconnect once before dropping the datagram
+		for retry := 0; retry < 2; retry++ {
+			session := sessions.get(clientAddr.String())
+			if session == nil {
+				session, err = openRelaySession(conn, clientAddr, target, dial, sessions)
+				if err != nil {
+					log.Warn().Err(err).Msgf("Failed to open relay session to %s", target)
+					break
+				}
+			}
+			session.touch()
+			if err = common.WriteUdpFrame(session.stream, buf[:n]); err == nil {
+				break
+			}
+			log.Debug().Err(err).Msgf("Failed to relay datagram to %s", target)
+			sessions.remove(clientAddr.String(), session)
+		}
+	}
+}
+
+// openRelaySession connect relay, and send datagrams from relay back to udp client
+func openRelaySession(conn *net.UDPConn, clientAddr *net.UDPAddr, target string, dial func() (net.Conn, error),
+	sessions *relaySessions) (*relaySession, error) {
+	stream, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	if err = common.WriteUdpFrame(stream, []byte(target)); err != nil {
+		_ = stream.Close()
+		return nil, err
+	}
+	log.Debug().Msgf("Relay session %s -> %s opened", clientAddr, target)
+	session := &relaySession{stream: stream, lastActive: time.Now()}
+	sessions.put(clientAddr.String(), session)
+	go func() {
+		defer sessions.remove(clientAddr.String(), session)
+		buf := make([]byte, common.MaxUdpFrameSize)
+		for {
+			n, err2 := common.ReadUdpFrame(stream, buf)
+			if err2 != nil {
+				log.Debug().Msgf("Relay session %s -> %s closed", clientAddr, target)
+				return
+			}
+			session.touch()
+			if _, err2 = conn.WriteToUDP(buf[:n], clientAddr); err2 != nil {
+				log.Debug().Err(err2).Msgf("Failed to send datagram to %s", clientAddr)
+			}
+		}
+	}()
+	return session, nil
+}
+
+// relaySession stream to relay of an udp client
+type relaySession struct {
+	lock       sync.Mutex
+	stream     net.Conn
+	lastActive time.Time
+}
+
+func (s *relaySession) touch() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lastActive = time.Now()
+}
+
+func (s *relaySession) idleSince(now time.Time) time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return now.Sub(s.lastActive)
+}
+
+// relaySessions sessions of udp clients, indexed by client address
+type relaySessions struct {
+	lock  sync.Mutex
+	items map[string]*relaySession
+}
+
+func (r *relaySessions) get(client string) *relaySession {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.items[client]
+}
+
+func (r *relaySessions) put(client string, session *relaySession) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.items[client] = session
+}
+
+// remove close the session, and forget it if it's still the current session of client
+func (r *relaySessions) remove(client string, session *relaySession) {
+	_ = session.stream.Close()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.items[client] == session {
+		delete(r.items, client)
+	}
+}
+
+// closeIdle close sessions without datagram sent or received longer than timeout
+func (r *relaySessions) closeIdle(now time.Time, timeout time.Duration) {
+	r.lock.Lock()
+	idle := map[string]*relaySession{}
+	for client, session := range r.items {
+		if session.idleSince(now) >= timeout {
+			idle[client] = session
+		}
+	}
+	r.lock.Unlock()
+	for client, session := range idle {
+		log.Debug().Msgf("Relay session of %s idle for %s, closing", client, timeout)
+		r.remove(client, session)
+	}
+}
+
+func (r *relaySessions) reapIdle(timeout time.Duration) {
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		r.closeIdle(now, timeout)
+	}
+}
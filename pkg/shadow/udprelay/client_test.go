@@ -0,0 +1,30 @@
+package udprelay
+
+import (
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCloseIdleSessions(t *testing.T) {
+	now := time.Now()
+	idleStream, idlePeer := net.Pipe()
+	activeStream, activePeer := net.Pipe()
+	defer idlePeer.Close()
+	defer activePeer.Close()
+	idle := &relaySession{stream: idleStream, lastActive: now.Add(-3 * time.Minute)}
+	active := &relaySession{stream: activeStream, lastActive: now.Add(-time.Minute)}
+	sessions := &relaySessions{items: map[string]*relaySession{"127.0.0.1:1001": idle, "127.0.0.1:1002": active}}
+
+	sessions.closeIdle(now, 2*time.Minute)
+	require.Nil(t, sessions.get("127.0.0.1:1001"))
+	require.Equal(t, active, sessions.get("127.0.0.1:1002"))
+	_, err := idlePeer.Read(make([]byte, 1))
+	require.NotNil(t, err)
+
+	// removing a replaced session does not affect the new one
+	sessions.put("127.0.0.1:1001", active)
+	sessions.remove("127.0.0.1:1001", idle)
+	require.Equal(t, active, sessions.get("127.0.0.1:1001"))
+}
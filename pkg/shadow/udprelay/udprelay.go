@@ -0,0 +1,103 @@
+package udprelay
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alibaba/kt-connect/pkg/common"
+	"github.com/rs/zerolog/log"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Start listen tcp port to relay datagrams, each connection is a session to one udp target,
+// the first frame of the connection is target address, following frames are datagrams,
+// only the allowed targets (in <host>:<port> format) can be relayed to
+func Start(port int, allowedTargets []string) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Udp relay listening on port %d, allowed targets %v", port, allowedTargets)
+	go Serve(listener, allowedTargets)
+	return nil
+}
+
+// Serve relay datagrams of sessions accepted by listener until it's closed
+func Serve(listener net.Listener, allowedTargets []string) {
+	allowed := map[string]bool{}
+	for _, target := range allowedTargets {
+		allowed[target] = true
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Warn().Err(err).Msgf("Failed to accept connection")
+			continue
+		}
+		go handleSession(conn, allowed)
+	}
+}
+
+func handleSession(conn net.Conn, allowed map[string]bool) {
+	defer conn.Close()
+	buf := make([]byte, common.MaxUdpFrameSize)
+	n, err := common.ReadUdpFrame(conn, buf)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to read relay target")
+		return
+	}
+	target := string(buf[:n])
+	if !allowed[target] {
+		log.Warn().Msgf("Relay target %s is not allowed", target)
+		return
+	}
+	udpConn, err := net.Dial("udp", target)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to connect udp target %s", target)
+		return
+	}
+	defer udpConn.Close()
+	log.Debug().Msgf("Relay session to %s started", target)
+
+	// datagrams of both directions keep the session alive
+	lastActive := time.Now().UnixNano()
+	go func() {
+		// when tcp connection closed, close udp connection to stop the reading loop below
+		defer udpConn.Close()
+		inBuf := make([]byte, common.MaxUdpFrameSize)
+		for {
+			size, err2 := common.ReadUdpFrame(conn, inBuf)
+			if err2 != nil {
+				return
+			}
+			atomic.StoreInt64(&lastActive, time.Now().UnixNano())
+			if _, err2 = udpConn.Write(inBuf[:size]); err2 != nil {
+				log.Debug().Err(err2).Msgf("Failed to send datagram to %s", target)
+			}
+		}
+	}()
+	for {
+		_ = udpConn.SetReadDeadline(time.Unix(0, atomic.LoadInt64(&lastActive)).Add(common.UdpSessionIdleTimeout))
+		size, err2 := udpConn.Read(buf)
+		if err2 != nil {
+			if netErr, ok := err2.(net.Error); ok && netErr.Timeout() {
+				if time.Since(time.Unix(0, atomic.LoadInt64(&lastActive))) < common.UdpSessionIdleTimeout {
+					// datagram sent to target meanwhile
+					continue
+				}
+			} else {
+				log.Debug().Err(err2).Msgf("Relay session to %s interrupted", target)
+			}
+			break
+		}
+		atomic.StoreInt64(&lastActive, time.Now().UnixNano())
+		if err2 = common.WriteUdpFrame(conn, buf[:size]); err2 != nil {
+			break
+		}
+	}
+	log.Debug().Msgf("Relay session to %s closed", target)
+}
@@ -0,0 +1,49 @@
+package udprelay
+
+import (
+	"github.com/alibaba/kt-connect/pkg/common"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+)
+
+func TestHandleSession(t *testing.T) {
+	// udp echo server as relay target
+	echo, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(common.Localhost)})
+	require.Nil(t, err)
+	defer echo.Close()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err2 := echo.ReadFromUDP(buf)
+			if err2 != nil {
+				return
+			}
+			_, _ = echo.WriteToUDP(append([]byte("echo:"), buf[:n]...), addr)
+		}
+	}()
+
+	client, server := net.Pipe()
+	go handleSession(server, map[string]bool{echo.LocalAddr().String(): true})
+	defer client.Close()
+
+	require.Nil(t, common.WriteUdpFrame(client, []byte(echo.LocalAddr().String())))
+	buf := make([]byte, common.MaxUdpFrameSize)
+	for _, msg := range []string{"hello", "world"} {
+		require.Nil(t, common.WriteUdpFrame(client, []byte(msg)))
+		n, err2 := common.ReadUdpFrame(client, buf)
+		require.Nil(t, err2)
+		require.Equal(t, "echo:"+msg, string(buf[:n]))
+	}
+}
+
+func TestHandleSessionWithDisallowedTarget(t *testing.T) {
+	client, server := net.Pipe()
+	go handleSession(server, map[string]bool{"dns.default:53": true})
+	defer client.Close()
+
+	require.Nil(t, common.WriteUdpFrame(client, []byte("10.0.0.1:53")))
+	// session is closed without relaying
+	_, err := common.ReadUdpFrame(client, make([]byte, common.MaxUdpFrameSize))
+	require.NotNil(t, err)
+}